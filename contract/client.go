@@ -0,0 +1,23 @@
+package contract
+
+// clientKey is a private type used as a context key to avoid collisions.
+type clientKey struct{}
+
+// ClientKey is the context key used to store and retrieve the [Client]
+// of a request from a context.Context.
+var ClientKey = clientKey{}
+
+// Client describes the client that originated a request as resolved
+// from the headers set by trusted proxies, which may differ from the
+// connection the server received.
+type Client struct {
+	// IP is the address of the client, without a port.
+	IP string
+
+	// Scheme is the scheme the client used, either "http" or "https".
+	Scheme string
+
+	// Host is the host the client requested, including the
+	// port when it's not the default one.
+	Host string
+}
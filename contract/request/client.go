@@ -0,0 +1,68 @@
+package request
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// Client returns the [contract.Client] of the request as resolved by
+// the trusted proxy middleware. The boolean return value indicates
+// whether the middleware resolved it.
+func Client(r *http.Request) (contract.Client, bool) {
+	client, ok := r.Context().Value(contract.ClientKey).(contract.Client)
+
+	return client, ok
+}
+
+// ClientIP returns the IP address of the client that originated the
+// request. It uses the address resolved by the trusted proxy
+// middleware when present, or the host of [http.Request.RemoteAddr]
+// otherwise, which is the address of the last proxy when the
+// application is behind one.
+func ClientIP(r *http.Request) string {
+	if client, ok := Client(r); ok && client.IP != "" {
+		return client.IP
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Scheme returns the scheme the client used for the request, either
+// "http" or "https". It uses the scheme resolved by the trusted proxy
+// middleware when present, or the connection's TLS state otherwise.
+func Scheme(r *http.Request) string {
+	if client, ok := Client(r); ok && client.Scheme != "" {
+		return client.Scheme
+	}
+
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// Host returns the host the client requested. It uses the host
+// resolved by the trusted proxy middleware when present, or
+// [http.Request.Host] otherwise.
+func Host(r *http.Request) string {
+	if client, ok := Client(r); ok && client.Host != "" {
+		return client.Host
+	}
+
+	return r.Host
+}
+
+// Origin returns the origin the client requested, made of its
+// [Scheme] and [Host], such as "https://example.com".
+func Origin(r *http.Request) string {
+	return Scheme(r) + "://" + Host(r)
+}
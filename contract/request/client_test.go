@@ -0,0 +1,57 @@
+package request_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/request"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientReturnsValuesFromContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), contract.ClientKey, contract.Client{
+		IP:     "203.0.113.7",
+		Scheme: "https",
+		Host:   "example.com",
+	})
+
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	require.Equal(t, "203.0.113.7", request.ClientIP(r))
+	require.Equal(t, "https", request.Scheme(r))
+	require.Equal(t, "example.com", request.Host(r))
+	require.Equal(t, "https://example.com", request.Origin(r))
+}
+
+func TestClientFallsBackToTheConnection(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("GET", "http://internal:8080/", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+
+	_, ok := request.Client(r)
+
+	require.False(t, ok)
+	require.Equal(t, "10.0.0.1", request.ClientIP(r))
+	require.Equal(t, "http", request.Scheme(r))
+	require.Equal(t, "internal:8080", request.Host(r))
+
+	r.TLS = &tls.ConnectionState{}
+
+	require.Equal(t, "https", request.Scheme(r))
+}
+
+func TestClientIPReturnsRemoteAddrWithoutPort(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "unix"
+
+	require.Equal(t, "unix", request.ClientIP(r))
+}
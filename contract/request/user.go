@@ -0,0 +1,20 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// User returns the authenticated principal of the request as stored
+// by the authentication middleware. The boolean return value reports
+// whether the request is authenticated with a principal of type T.
+//
+// Example usage:
+//
+//	user, ok := request.User[*models.User](r)
+func User[T any](r *http.Request) (T, bool) {
+	user, ok := r.Context().Value(contract.UserKey).(T)
+
+	return user, ok
+}
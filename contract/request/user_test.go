@@ -0,0 +1,42 @@
+package request_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/request"
+
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	ID int
+}
+
+func TestUserReturnsPrincipalFromContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), contract.UserKey, &account{ID: 7})
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+	user, ok := request.User[*account](r)
+
+	require.True(t, ok)
+	require.Equal(t, 7, user.ID)
+}
+
+func TestUserReportsMissingOrMismatchedPrincipal(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("GET", "/", nil)
+
+	_, ok := request.User[*account](r)
+	require.False(t, ok)
+
+	r = r.WithContext(context.WithValue(r.Context(), contract.UserKey, "admin"))
+
+	_, ok = request.User[*account](r)
+	require.False(t, ok)
+}
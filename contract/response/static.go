@@ -11,6 +11,8 @@ import (
 	"net/url"
 	"strings"
 	"text/template"
+
+	"github.com/studiolambda/cosmos/contract/request"
 )
 
 // ErrUnsafeRedirect is returned by [SafeRedirect] when the target
@@ -233,6 +235,33 @@ func SafeRedirect(w http.ResponseWriter, status int, rawURL string) error {
 	return Redirect(w, status, rawURL)
 }
 
+// SameOriginRedirect sends an HTTP redirect response only if the
+// target URL is a safe relative path, as accepted by [SafeRedirect],
+// or an absolute URL with the same origin as the request. The origin
+// is resolved with [request.Origin], so it matches the scheme and host
+// the client used even behind trusted proxies.
+//
+// Returns [ErrUnsafeRedirect] if the URL fails validation.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request being redirected
+//   - status: The HTTP redirect status code to set
+//   - rawURL: The URL to redirect the user to
+func SameOriginRedirect(w http.ResponseWriter, r *http.Request, status int, rawURL string) error {
+	if isRelativePath(rawURL) {
+		return Redirect(w, status, rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+
+	if err != nil || parsed.User != nil || !strings.EqualFold(parsed.Scheme+"://"+parsed.Host, request.Origin(r)) {
+		return fmt.Errorf("%w: `%s` must be a relative path or share the request origin", ErrUnsafeRedirect, rawURL)
+	}
+
+	return Redirect(w, status, rawURL)
+}
+
 // isRelativePath validates that a URL is a safe relative path
 // and not an absolute URL, protocol-relative URL, javascript:
 // URI, or data: URI that could be used in an open redirect attack.
@@ -1,6 +1,7 @@
 package response_test
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	htmltemplate "html/template"
@@ -10,6 +11,7 @@ import (
 	"text/template"
 
 	"github.com/stretchr/testify/require"
+	"github.com/studiolambda/cosmos/contract"
 	"github.com/studiolambda/cosmos/contract/response"
 )
 
@@ -467,3 +469,75 @@ func TestHTMLTemplateBuffersBeforeWritingStatus(t *testing.T) {
 	require.Equal(t, http.StatusOK, w.Code)
 	require.Empty(t, w.Body.String())
 }
+
+func TestSameOriginRedirectAllowsRelativePath(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+
+	err := response.SameOriginRedirect(w, r, http.StatusFound, "/dashboard")
+
+	require.NoError(t, err)
+	require.Equal(t, "/dashboard", w.Header().Get("Location"))
+}
+
+func TestSameOriginRedirectAllowsResolvedOrigin(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://internal:8080/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), contract.ClientKey, contract.Client{
+		Scheme: "https",
+		Host:   "example.com",
+	}))
+
+	err := response.SameOriginRedirect(w, r, http.StatusFound, "https://example.com/dashboard")
+
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/dashboard", w.Header().Get("Location"))
+}
+
+func TestSameOriginRedirectRejectsOtherSchemes(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	w := httptest.NewRecorder()
+
+	err := response.SameOriginRedirect(w, r, http.StatusFound, "https://example.com/dashboard")
+
+	require.ErrorIs(t, err, response.ErrUnsafeRedirect)
+}
+
+func TestSameOriginRedirectRejectsOtherHosts(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	w := httptest.NewRecorder()
+
+	err := response.SameOriginRedirect(w, r, http.StatusFound, "http://evil.com/")
+
+	require.ErrorIs(t, err, response.ErrUnsafeRedirect)
+}
+
+func TestSameOriginRedirectRejectsUserInfo(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	w := httptest.NewRecorder()
+
+	err := response.SameOriginRedirect(w, r, http.StatusFound, "http://user@example.com/")
+
+	require.ErrorIs(t, err, response.ErrUnsafeRedirect)
+}
+
+func TestSameOriginRedirectRejectsSchemeRelativeURLs(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	w := httptest.NewRecorder()
+
+	err := response.SameOriginRedirect(w, r, http.StatusFound, "//evil.com")
+
+	require.ErrorIs(t, err, response.ErrUnsafeRedirect)
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"maps"
+	"net/url"
+	"slices"
 	"sync"
 	"time"
 )
@@ -15,6 +17,19 @@ type sessionKey struct{}
 // SessionKey is the context key used to store and retrieve the session from a context.Context.
 var SessionKey = sessionKey{}
 
+const (
+	// flashNewKey is the storage key of the keys flashed during
+	// the current request, kept until the end of the next one.
+	flashNewKey = "_flash.new"
+
+	// flashOldKey is the storage key of the keys flashed during
+	// the previous request, removed at the end of the current one.
+	flashOldKey = "_flash.old"
+
+	// oldInputKey is the storage key of the flashed input.
+	oldInputKey = "_old_input"
+)
+
 // sessionIDLength is the number of random bytes used to generate
 // a session ID. 32 bytes provides 256 bits of entropy.
 const sessionIDLength = 32
@@ -207,6 +222,175 @@ func (session *Session) Clear() {
 	session.changed = true
 }
 
+// Flash stores a value in the session that is only available until the
+// end of the next request, such as a status message shown after a
+// redirect. This operation marks the session as changed.
+func (session *Session) Flash(key string, value any) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	session.storage[key] = value
+	session.setFlashKeys(flashNewKey, append(session.flashKeys(flashNewKey), key))
+	session.setFlashKeys(flashOldKey, slices.DeleteFunc(session.flashKeys(flashOldKey), func(old string) bool {
+		return old == key
+	}))
+	session.changed = true
+}
+
+// FlashNow stores a value in the session that is only available until
+// the end of the current request, such as a message rendered by the
+// handler that sets it. This operation marks the session as changed.
+func (session *Session) FlashNow(key string, value any) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	session.storage[key] = value
+	session.setFlashKeys(flashOldKey, append(session.flashKeys(flashOldKey), key))
+	session.setFlashKeys(flashNewKey, slices.DeleteFunc(session.flashKeys(flashNewKey), func(current string) bool {
+		return current == key
+	}))
+	session.changed = true
+}
+
+// Reflash keeps all the values flashed by the previous request for
+// one more request. This operation marks the session as changed.
+func (session *Session) Reflash() {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	session.keep(session.flashKeys(flashOldKey))
+}
+
+// Keep keeps the given values flashed by the previous request for
+// one more request. This operation marks the session as changed.
+func (session *Session) Keep(keys ...string) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	session.keep(slices.DeleteFunc(session.flashKeys(flashOldKey), func(old string) bool {
+		return !slices.Contains(keys, old)
+	}))
+}
+
+// FlashInput flashes the given input, usually the form of a request
+// that failed validation, so that [Session.OldInput] returns it on the
+// next request to repopulate the form. Fields listed in except, such
+// as passwords, are left out. This operation marks the session as
+// changed.
+func (session *Session) FlashInput(input url.Values, except ...string) {
+	flashed := make(map[string][]string, len(input))
+
+	for key, values := range input {
+		if !slices.Contains(except, key) {
+			flashed[key] = slices.Clone(values)
+		}
+	}
+
+	session.Flash(oldInputKey, flashed)
+}
+
+// OldInput returns the first value of the given field of the input
+// flashed by [Session.FlashInput], or an empty string when missing.
+func (session *Session) OldInput(key string) string {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	var values []string
+
+	switch input := session.storage[oldInputKey].(type) {
+	case map[string][]string:
+		values = input[key]
+	case map[string]any:
+		values = stringSlice(input[key])
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// AgeFlashData removes the values flashed by the previous request and
+// keeps the ones flashed by the current request for the next one. It
+// is called by the session middleware before saving the session, and
+// marks it as changed when there were flashed values.
+func (session *Session) AgeFlashData() {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	previous := session.flashKeys(flashOldKey)
+	current := session.flashKeys(flashNewKey)
+
+	if len(previous) == 0 && len(current) == 0 {
+		return
+	}
+
+	for _, key := range previous {
+		if !slices.Contains(current, key) {
+			delete(session.storage, key)
+		}
+	}
+
+	session.setFlashKeys(flashOldKey, current)
+	session.setFlashKeys(flashNewKey, nil)
+	session.changed = true
+}
+
+// keep moves the given keys flashed by the previous request to the
+// ones flashed by the current request. The mutex must be held.
+func (session *Session) keep(keys []string) {
+	session.setFlashKeys(flashNewKey, append(session.flashKeys(flashNewKey), keys...))
+	session.setFlashKeys(flashOldKey, slices.DeleteFunc(session.flashKeys(flashOldKey), func(old string) bool {
+		return slices.Contains(keys, old)
+	}))
+	session.changed = true
+}
+
+// flashKeys returns the flashed keys stored under the given
+// storage key. The mutex must be held.
+func (session *Session) flashKeys(storageKey string) []string {
+	return stringSlice(session.storage[storageKey])
+}
+
+// setFlashKeys stores the given flashed keys under the given storage
+// key, without duplicates, or removes it when empty. The mutex must be
+// held.
+func (session *Session) setFlashKeys(storageKey string, keys []string) {
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	if len(keys) == 0 {
+		delete(session.storage, storageKey)
+
+		return
+	}
+
+	session.storage[storageKey] = keys
+}
+
+// stringSlice returns the given value as a slice of strings, which is
+// either a []string or, once the session went through a JSON round
+// trip, a []any of strings.
+func stringSlice(value any) []string {
+	switch value := value.(type) {
+	case []string:
+		return slices.Clone(value)
+	case []any:
+		result := make([]string, 0, len(value))
+
+		for _, item := range value {
+			if item, ok := item.(string); ok {
+				result = append(result, item)
+			}
+		}
+
+		return result
+	default:
+		return nil
+	}
+}
+
 // CreatedAt returns the absolute time the session was first created.
 func (session *Session) CreatedAt() time.Time {
 	session.mutex.Lock()
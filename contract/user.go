@@ -0,0 +1,10 @@
+package contract
+
+// userKey is a private type used as a context key to avoid collisions.
+type userKey struct{}
+
+// UserKey is the context key used to store and retrieve the
+// authenticated principal of a request from a context.Context.
+// The principal can be of any type, such as a user model or
+// the claims of a token.
+var UserKey = userKey{}
@@ -0,0 +1,422 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrShutdownTimeout is returned by [Application.Run] when a step of
+// the graceful shutdown does not finish within its timeout.
+var ErrShutdownTimeout = errors.New("shutdown step timed out")
+
+// ApplicationOptions configures an [Application] created with
+// [ApplicationWith]. All zero-valued fields default to the values
+// from [DefaultApplicationOptions].
+type ApplicationOptions struct {
+	// Signals are the signals that start the graceful shutdown.
+	// Defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+
+	// DrainDelay is the time to wait after the shutdown started and
+	// before the servers stop accepting connections, giving load
+	// balancers time to notice the failing readiness checks.
+	// Defaults to no delay.
+	DrainDelay time.Duration
+
+	// ShutdownTimeout limits the time to drain the in-flight
+	// requests, including their AfterResponse hooks. Connections
+	// still open once it elapses are closed. Defaults to 30s.
+	ShutdownTimeout time.Duration
+
+	// CloseTimeout limits the time each registered closer has to
+	// release its resources. Defaults to 10s.
+	CloseTimeout time.Duration
+}
+
+// Application owns the HTTP servers of a service and the resources
+// they depend on, such as databases, caches, event brokers and
+// encrypters, and manages their lifecycle:
+//
+//	app := framework.NewApplication()
+//	app.Serve(framework.NewServer(":8080", router))
+//	app.Closer("database", db)
+//	app.Closer("events", broker)
+//
+//	if err := app.Run(context.Background()); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// [Application.Run] starts the servers and blocks until a signal is
+// received, the context is cancelled or a server fails. It then shuts
+// down gracefully: the shutdown callbacks run, the servers stop
+// accepting connections and drain the in-flight requests, and the
+// closers release their resources in the reverse order they were
+// registered, each one within its own timeout.
+type Application struct {
+	// options stores the options of the application.
+	options ApplicationOptions
+
+	// mutex guards the registered servers, closers and
+	// shutdown callbacks.
+	mutex sync.Mutex
+
+	// servers stores the registered servers in
+	// registration order.
+	servers []*applicationServer
+
+	// closers stores the registered closers in
+	// registration order.
+	closers []applicationCloser
+
+	// shutdownFuncs stores the callbacks registered with
+	// [Application.OnShutdown].
+	shutdownFuncs []func()
+
+	// shuttingDown reports whether the graceful shutdown started.
+	shuttingDown atomic.Bool
+
+	// requests tracks the requests being served, including their
+	// AfterResponse hooks.
+	requests sync.WaitGroup
+}
+
+// applicationServer is a server registered in an [Application].
+type applicationServer struct {
+	// server stores the HTTP server.
+	server *http.Server
+
+	// listener stores the listener the server accepts connections
+	// from. When nil, the server listens on its own address.
+	listener net.Listener
+}
+
+// applicationCloser is a resource registered in an [Application]
+// that is released during the shutdown.
+type applicationCloser struct {
+	// name stores the name used to identify the closer in errors.
+	name string
+
+	// close stores the function that releases the resource.
+	close func(ctx context.Context) error
+}
+
+// DefaultApplicationOptions returns the default application options.
+// Each call returns a fresh copy, preventing accidental mutation of
+// shared defaults.
+func DefaultApplicationOptions() ApplicationOptions {
+	return ApplicationOptions{
+		Signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		ShutdownTimeout: 30 * time.Second,
+		CloseTimeout:    10 * time.Second,
+	}
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultApplicationOptions] fields.
+func (options ApplicationOptions) withDefaults() ApplicationOptions {
+	defaults := DefaultApplicationOptions()
+
+	if len(options.Signals) == 0 {
+		options.Signals = defaults.Signals
+	}
+
+	if options.ShutdownTimeout == 0 {
+		options.ShutdownTimeout = defaults.ShutdownTimeout
+	}
+
+	if options.CloseTimeout == 0 {
+		options.CloseTimeout = defaults.CloseTimeout
+	}
+
+	return options
+}
+
+// NewApplication creates an [Application] using the
+// [DefaultApplicationOptions].
+func NewApplication() *Application {
+	return ApplicationWith(DefaultApplicationOptions())
+}
+
+// ApplicationWith creates an [Application] using the given options.
+func ApplicationWith(options ApplicationOptions) *Application {
+	return &Application{
+		options:       options.withDefaults(),
+		servers:       make([]*applicationServer, 0),
+		closers:       make([]applicationCloser, 0),
+		shutdownFuncs: make([]func(), 0),
+	}
+}
+
+// Serve registers a server that is started by [Application.Run] on
+// its own address. Servers with a TLSConfig are started with TLS
+// using the certificates it provides.
+//
+// The server handler is wrapped to track the in-flight requests, so
+// it must be set before calling this method.
+func (application *Application) Serve(server *http.Server) *Application {
+	return application.ServeListener(server, nil)
+}
+
+// ServeListener registers a server that is started by [Application.Run]
+// accepting connections from the given listener, like [Application.Serve].
+func (application *Application) ServeListener(server *http.Server, listener net.Listener) *Application {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	server.Handler = application.track(server.Handler)
+
+	application.servers = append(application.servers, &applicationServer{
+		server:   server,
+		listener: listener,
+	})
+
+	return application
+}
+
+// Closer registers a resource that is released during the shutdown.
+// The closer can implement either Close() error, Close() or
+// Shutdown(context.Context) error, such as [database.SQL],
+// [crypto.AES] or the event brokers. The name identifies the
+// closer in the returned errors.
+//
+// It panics if the closer implements none of those methods, as
+// it's a programming error.
+func (application *Application) Closer(name string, closer any) *Application {
+	switch closer := closer.(type) {
+	case interface{ Shutdown(ctx context.Context) error }:
+		return application.CloserFunc(name, closer.Shutdown)
+	case interface{ Close() error }:
+		return application.CloserFunc(name, func(ctx context.Context) error {
+			return closer.Close()
+		})
+	case interface{ Close() }:
+		return application.CloserFunc(name, func(ctx context.Context) error {
+			closer.Close()
+
+			return nil
+		})
+	default:
+		panic(fmt.Sprintf("framework: closer %q of type %T has no Close method", name, closer))
+	}
+}
+
+// CloserFunc registers a function that releases a resource during
+// the shutdown. The given context is cancelled once the close
+// timeout elapses.
+func (application *Application) CloserFunc(name string, close func(ctx context.Context) error) *Application {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	application.closers = append(application.closers, applicationCloser{
+		name:  name,
+		close: close,
+	})
+
+	return application
+}
+
+// OnShutdown registers a callback that runs as soon as the graceful
+// shutdown starts, before the servers stop accepting connections.
+func (application *Application) OnShutdown(callback func()) *Application {
+	application.mutex.Lock()
+	defer application.mutex.Unlock()
+
+	application.shutdownFuncs = append(application.shutdownFuncs, callback)
+
+	return application
+}
+
+// ShuttingDown reports whether the graceful shutdown started.
+func (application *Application) ShuttingDown() bool {
+	return application.shuttingDown.Load()
+}
+
+// Run starts the registered servers and blocks until one of the
+// configured signals is received, the given context is cancelled or
+// a server fails to serve. It then shuts the application down
+// gracefully and returns the errors of every failed step joined
+// together, or nil when all of them succeeded.
+func (application *Application) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, application.options.Signals...)
+	defer stop()
+
+	application.mutex.Lock()
+	servers := application.servers
+	application.mutex.Unlock()
+
+	failures := make(chan error, len(servers))
+
+	for _, server := range servers {
+		go func() {
+			if err := server.serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failures <- fmt.Errorf("framework: serving %s: %w", server.address(), err)
+			}
+		}()
+	}
+
+	errs := make([]error, 0)
+
+	select {
+	case <-ctx.Done():
+	case err := <-failures:
+		errs = append(errs, err)
+	}
+
+	stop()
+
+	return errors.Join(append(errs, application.shutdown(servers)...)...)
+}
+
+// shutdown performs the graceful shutdown of the given servers and
+// releases the registered closers, returning the errors of the
+// failed steps.
+func (application *Application) shutdown(servers []*applicationServer) []error {
+	application.shuttingDown.Store(true)
+
+	application.mutex.Lock()
+	callbacks := application.shutdownFuncs
+	closers := application.closers
+	application.mutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+
+	if application.options.DrainDelay > 0 {
+		time.Sleep(application.options.DrainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), application.options.ShutdownTimeout)
+	defer cancel()
+
+	errs := application.shutdownServers(ctx, servers)
+
+	if err := application.wait(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := application.close(closers[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// shutdownServers stops the given servers concurrently, waiting for
+// their connections to become idle until the context is done, after
+// which the remaining connections are closed.
+func (application *Application) shutdownServers(ctx context.Context, servers []*applicationServer) []error {
+	var mutex sync.Mutex
+	var group sync.WaitGroup
+
+	errs := make([]error, 0)
+
+	for _, server := range servers {
+		group.Go(func() {
+			if err := server.server.Shutdown(ctx); err != nil {
+				_ = server.server.Close()
+
+				mutex.Lock()
+				errs = append(errs, fmt.Errorf("framework: shutting down %s: %w", server.address(), err))
+				mutex.Unlock()
+			}
+		})
+	}
+
+	group.Wait()
+
+	return errs
+}
+
+// wait blocks until the in-flight requests and their AfterResponse
+// hooks finished, or until the context is done.
+func (application *Application) wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		application.requests.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("framework: draining requests: %w", ErrShutdownTimeout)
+	}
+}
+
+// close releases the given closer within the close timeout.
+func (application *Application) close(closer applicationCloser) error {
+	ctx, cancel := context.WithTimeout(context.Background(), application.options.CloseTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- closer.close(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("framework: closing %s: %w", closer.name, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("framework: closing %s: %w", closer.name, ErrShutdownTimeout)
+	}
+}
+
+// track wraps the given handler to track the requests it serves.
+// Since [Handler] runs the AfterResponse hooks before returning,
+// they are tracked as well.
+func (application *Application) track(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		application.requests.Add(1)
+		defer application.requests.Done()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// serve starts serving the server, blocking until it stops.
+func (server *applicationServer) serve() error {
+	tls := server.server.TLSConfig != nil
+
+	switch {
+	case server.listener != nil && tls:
+		return server.server.ServeTLS(server.listener, "", "")
+	case server.listener != nil:
+		return server.server.Serve(server.listener)
+	case tls:
+		return server.server.ListenAndServeTLS("", "")
+	default:
+		return server.server.ListenAndServe()
+	}
+}
+
+// address returns the address the server listens on.
+func (server *applicationServer) address() string {
+	if server.listener != nil {
+		return server.listener.Addr().String()
+	}
+
+	return server.server.Addr
+}
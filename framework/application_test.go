@@ -0,0 +1,292 @@
+package framework_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+
+	"github.com/stretchr/testify/require"
+)
+
+type closerWithoutError struct {
+	closed atomic.Bool
+}
+
+func (closer *closerWithoutError) Close() {
+	closer.closed.Store(true)
+}
+
+type closerWithError struct {
+	err error
+}
+
+func (closer closerWithError) Close() error {
+	return closer.err
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	require.NoError(t, err)
+
+	return listener
+}
+
+func run(app *framework.Application, ctx context.Context) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		result <- app.Run(ctx)
+	}()
+
+	return result
+}
+
+func get(t *testing.T, listener net.Listener, path string) string {
+	t.Helper()
+
+	var res *http.Response
+	var err error
+
+	for range 50 {
+		if res, err = http.Get("http://" + listener.Addr().String() + path); err == nil {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestApplicationServesUntilContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	listener := listen(t)
+	app := framework.NewApplication()
+	app.ServeListener(framework.NewServer("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})), listener)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	result := run(app, ctx)
+
+	require.Equal(t, "ok", get(t, listener, "/"))
+	require.False(t, app.ShuttingDown())
+
+	cancel()
+
+	require.NoError(t, <-result)
+	require.True(t, app.ShuttingDown())
+}
+
+func TestApplicationShutsDownOnSignal(t *testing.T) {
+	listener := listen(t)
+	app := framework.ApplicationWith(framework.ApplicationOptions{
+		Signals: []os.Signal{syscall.SIGUSR1},
+	})
+	app.ServeListener(framework.NewServer("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})), listener)
+
+	result := run(app, t.Context())
+
+	require.Equal(t, "ok", get(t, listener, "/"))
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case err := <-result:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the application to shut down")
+	}
+}
+
+func TestApplicationClosesInReverseOrder(t *testing.T) {
+	t.Parallel()
+
+	closed := make([]string, 0)
+	app := framework.NewApplication()
+
+	for _, name := range []string{"database", "cache", "events"} {
+		app.CloserFunc(name, func(ctx context.Context) error {
+			closed = append(closed, name)
+
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.NoError(t, app.Run(ctx))
+	require.Equal(t, []string{"events", "cache", "database"}, closed)
+}
+
+func TestApplicationAcceptsCloseMethods(t *testing.T) {
+	t.Parallel()
+
+	encrypter := &closerWithoutError{}
+	app := framework.NewApplication().
+		Closer("encrypter", encrypter).
+		Closer("database", closerWithError{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.NoError(t, app.Run(ctx))
+	require.True(t, encrypter.closed.Load())
+}
+
+func TestApplicationCloserPanicsWithoutCloseMethod(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		framework.NewApplication().Closer("invalid", struct{}{})
+	})
+}
+
+func TestApplicationAggregatesCloseErrors(t *testing.T) {
+	t.Parallel()
+
+	first := errors.New("first")
+	second := errors.New("second")
+	app := framework.NewApplication().
+		Closer("first", closerWithError{err: first}).
+		Closer("second", closerWithError{err: second})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := app.Run(ctx)
+
+	require.ErrorIs(t, err, first)
+	require.ErrorIs(t, err, second)
+}
+
+func TestApplicationCloseTimeoutContinuesWithNextCloser(t *testing.T) {
+	t.Parallel()
+
+	var closed atomic.Bool
+
+	app := framework.ApplicationWith(framework.ApplicationOptions{
+		CloseTimeout: 10 * time.Millisecond,
+	})
+
+	app.CloserFunc("first", func(ctx context.Context) error {
+		closed.Store(true)
+
+		return nil
+	})
+
+	app.CloserFunc("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := app.Run(ctx)
+
+	require.ErrorIs(t, err, framework.ErrShutdownTimeout)
+	require.True(t, closed.Load())
+}
+
+func TestApplicationWaitsForAfterResponseHooks(t *testing.T) {
+	t.Parallel()
+
+	var hooked atomic.Bool
+	var closedAfterHooks atomic.Bool
+
+	listener := listen(t)
+	router := framework.New()
+	router.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		request.Hooks(r).AfterResponse(func(err error) {
+			time.Sleep(50 * time.Millisecond)
+			hooked.Store(true)
+		})
+
+		_, err := w.Write([]byte("ok"))
+
+		return err
+	})
+
+	app := framework.NewApplication()
+	app.ServeListener(framework.NewServer("", router), listener)
+	app.CloserFunc("database", func(ctx context.Context) error {
+		closedAfterHooks.Store(hooked.Load())
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	result := run(app, ctx)
+
+	require.Equal(t, "ok", get(t, listener, "/"))
+
+	cancel()
+
+	require.NoError(t, <-result)
+	require.True(t, closedAfterHooks.Load())
+}
+
+func TestApplicationRunsShutdownCallbacks(t *testing.T) {
+	t.Parallel()
+
+	var called atomic.Bool
+
+	app := framework.NewApplication()
+	app.OnShutdown(func() {
+		called.Store(app.ShuttingDown())
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	require.NoError(t, app.Run(ctx))
+	require.True(t, called.Load())
+}
+
+func TestApplicationReturnsServerFailures(t *testing.T) {
+	t.Parallel()
+
+	listener := listen(t)
+	defer listener.Close()
+
+	var closed atomic.Bool
+
+	app := framework.NewApplication()
+	app.Serve(framework.NewServer(listener.Addr().String(), http.NotFoundHandler()))
+	app.CloserFunc("database", func(ctx context.Context) error {
+		closed.Store(true)
+
+		return nil
+	})
+
+	err := app.Run(t.Context())
+
+	require.Error(t, err)
+	require.True(t, closed.Load())
+}
@@ -0,0 +1,217 @@
+// Package authorization decides what authenticated principals can do.
+//
+// A [Gate] holds named policies, either for any resource or for the
+// resources of a given type, and the permissions granted to roles.
+// Checks run against it directly with [Gate.Allows], which needs no
+// HTTP request, or from handlers and middleware with [Can],
+// [Authorize] and [Require] once [Middleware] stores the gate in the
+// request context.
+package authorization
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Wildcard is the permission that grants every permission.
+const Wildcard = "*"
+
+// Policy decides whether the principal can perform an ability on the
+// resource, which is nil for abilities that don't act on one. Errors
+// are reported as is, as they mean the decision could not be made.
+type Policy func(ctx context.Context, principal any, resource any) (bool, error)
+
+// RoleHolder is implemented by principals that have roles.
+type RoleHolder interface {
+	// Roles returns the roles of the principal.
+	Roles() []string
+}
+
+// PermissionHolder is implemented by principals that are
+// granted permissions directly, besides the ones of their roles.
+type PermissionHolder interface {
+	// Permissions returns the permissions of the principal.
+	Permissions() []string
+}
+
+// Gate holds the policies and role permissions abilities are checked
+// against. It's safe for concurrent use.
+type Gate struct {
+	// mutex guards the fields below.
+	mutex sync.RWMutex
+
+	// abilities stores the policies of abilities that
+	// don't depend on the type of the resource.
+	abilities map[string]Policy
+
+	// resources stores the policies of abilities
+	// by the type of their resource.
+	resources map[reflect.Type]map[string]Policy
+
+	// grants stores the permissions of roles.
+	grants map[string][]string
+}
+
+// New creates an empty [Gate].
+func New() *Gate {
+	return &Gate{
+		abilities: make(map[string]Policy),
+		resources: make(map[reflect.Type]map[string]Policy),
+		grants:    make(map[string][]string),
+	}
+}
+
+// Define registers the policy of the given ability for any resource,
+// replacing the existing one, if any.
+//
+// Example usage:
+//
+//	gate.Define("reports.export", authorization.PolicyFor(
+//	    func(ctx context.Context, user *models.User, _ any) (bool, error) {
+//	        return user.Plan == "enterprise", nil
+//	    },
+//	))
+func (gate *Gate) Define(ability string, policy Policy) *Gate {
+	gate.mutex.Lock()
+	defer gate.mutex.Unlock()
+
+	gate.abilities[ability] = policy
+
+	return gate
+}
+
+// Grant grants the given permissions to the role. Granting
+// [Wildcard] grants every permission.
+func (gate *Gate) Grant(role string, permissions ...string) *Gate {
+	gate.mutex.Lock()
+	defer gate.mutex.Unlock()
+
+	gate.grants[role] = append(gate.grants[role], permissions...)
+
+	return gate
+}
+
+// Register registers the policy of the given ability for resources of
+// type R, such as *models.Post, which takes precedence over the policy
+// defined for any resource. It's matched against the dynamic type of
+// resources, so R should be a concrete type. Principals that are not
+// of type P, such as guests, are denied.
+//
+// Example usage:
+//
+//	authorization.Register(gate, "update",
+//	    func(ctx context.Context, user *models.User, post *models.Post) (bool, error) {
+//	        return post.AuthorID == user.ID, nil
+//	    },
+//	)
+func Register[P any, R any](gate *Gate, ability string, policy func(ctx context.Context, principal P, resource R) (bool, error)) *Gate {
+	gate.mutex.Lock()
+	defer gate.mutex.Unlock()
+
+	resource := reflect.TypeFor[R]()
+
+	if gate.resources[resource] == nil {
+		gate.resources[resource] = make(map[string]Policy)
+	}
+
+	gate.resources[resource][ability] = PolicyFor(policy)
+
+	return gate
+}
+
+// PolicyFor adapts a policy of typed principals and resources into a
+// [Policy], which denies principals that are not of type P and
+// resources that are not of type R.
+func PolicyFor[P any, R any](policy func(ctx context.Context, principal P, resource R) (bool, error)) Policy {
+	return func(ctx context.Context, principal any, resource any) (bool, error) {
+		typedPrincipal, ok := principal.(P)
+
+		if !ok {
+			return false, nil
+		}
+
+		typedResource, ok := resource.(R)
+
+		if !ok && resource != nil {
+			return false, nil
+		}
+
+		return policy(ctx, typedPrincipal, typedResource)
+	}
+}
+
+// Allows reports whether the principal can perform the ability on the
+// resource, which can be nil. The policy registered for the type of the
+// resource is used first, then the policy defined for any resource.
+// Abilities without a policy are permissions, which the principal must
+// have, see [Gate.HasPermission].
+func (gate *Gate) Allows(ctx context.Context, principal any, ability string, resource any) (bool, error) {
+	policy := gate.policy(ability, resource)
+
+	if policy == nil {
+		return gate.HasPermission(principal, ability), nil
+	}
+
+	return policy(ctx, principal, resource)
+}
+
+// HasRole reports whether the principal has any of the given roles.
+// Principals that don't implement [RoleHolder] have no roles.
+func HasRole(principal any, roles ...string) bool {
+	holder, ok := principal.(RoleHolder)
+
+	if !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(holder.Roles(), func(role string) bool {
+		return slices.Contains(roles, role)
+	})
+}
+
+// HasPermission reports whether the principal has the permission,
+// either directly, when it implements [PermissionHolder], or through
+// the permissions granted to its roles.
+func (gate *Gate) HasPermission(principal any, permission string) bool {
+	granted := func(permissions []string) bool {
+		return slices.Contains(permissions, permission) || slices.Contains(permissions, Wildcard)
+	}
+
+	if holder, ok := principal.(PermissionHolder); ok && granted(holder.Permissions()) {
+		return true
+	}
+
+	holder, ok := principal.(RoleHolder)
+
+	if !ok {
+		return false
+	}
+
+	gate.mutex.RLock()
+	defer gate.mutex.RUnlock()
+
+	for _, role := range holder.Roles() {
+		if granted(gate.grants[role]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policy returns the policy of the ability for the
+// resource, or nil when there's none.
+func (gate *Gate) policy(ability string, resource any) Policy {
+	gate.mutex.RLock()
+	defer gate.mutex.RUnlock()
+
+	if resource != nil {
+		if policy, ok := gate.resources[reflect.TypeOf(resource)][ability]; ok {
+			return policy
+		}
+	}
+
+	return gate.abilities[ability]
+}
@@ -0,0 +1,134 @@
+package authorization_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/authorization"
+
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID          int
+	roles       []string
+	permissions []string
+}
+
+func (user *user) Roles() []string {
+	return user.roles
+}
+
+func (user *user) Permissions() []string {
+	return user.permissions
+}
+
+type post struct {
+	AuthorID int
+}
+
+type comment struct {
+	AuthorID int
+}
+
+func ownsPost(ctx context.Context, user *user, post *post) (bool, error) {
+	return post.AuthorID == user.ID, nil
+}
+
+func TestGateUsesPoliciesOfResourceTypes(t *testing.T) {
+	t.Parallel()
+
+	gate := authorization.New()
+	authorization.Register(gate, "update", ownsPost)
+
+	author := &user{ID: 1}
+	other := &user{ID: 2}
+
+	allowed, err := gate.Allows(t.Context(), author, "update", &post{AuthorID: 1})
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, err = gate.Allows(t.Context(), other, "update", &post{AuthorID: 1})
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	allowed, err = gate.Allows(t.Context(), nil, "update", &post{AuthorID: 1})
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	allowed, err = gate.Allows(t.Context(), author, "update", &comment{AuthorID: 1})
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
+
+func TestGateFallsBackToDefinedAbilities(t *testing.T) {
+	t.Parallel()
+
+	gate := authorization.New().Define("update", authorization.PolicyFor(
+		func(ctx context.Context, user *user, _ any) (bool, error) {
+			return user.ID == 1, nil
+		},
+	))
+
+	authorization.Register(gate, "update", ownsPost)
+
+	allowed, err := gate.Allows(t.Context(), &user{ID: 1}, "update", &comment{AuthorID: 2})
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, err = gate.Allows(t.Context(), &user{ID: 1}, "update", &post{AuthorID: 2})
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	allowed, err = gate.Allows(t.Context(), &user{ID: 1}, "update", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestGateReturnsPolicyErrors(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("database down")
+	gate := authorization.New().Define("export", func(ctx context.Context, principal any, resource any) (bool, error) {
+		return false, failure
+	})
+
+	_, err := gate.Allows(t.Context(), &user{}, "export", nil)
+
+	require.ErrorIs(t, err, failure)
+}
+
+func TestGateChecksPermissionsOfAbilitiesWithoutPolicies(t *testing.T) {
+	t.Parallel()
+
+	gate := authorization.New().
+		Grant("editor", "posts.publish").
+		Grant("admin", authorization.Wildcard)
+
+	editor := &user{roles: []string{"editor"}}
+	admin := &user{roles: []string{"admin"}}
+	direct := &user{permissions: []string{"posts.publish"}}
+	guest := &user{}
+
+	require.True(t, gate.HasPermission(editor, "posts.publish"))
+	require.False(t, gate.HasPermission(editor, "users.delete"))
+	require.True(t, gate.HasPermission(admin, "users.delete"))
+	require.True(t, gate.HasPermission(direct, "posts.publish"))
+	require.False(t, gate.HasPermission(guest, "posts.publish"))
+	require.False(t, gate.HasPermission("anonymous", "posts.publish"))
+
+	allowed, err := gate.Allows(t.Context(), editor, "posts.publish", &post{})
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestHasRoleMatchesAnyRole(t *testing.T) {
+	t.Parallel()
+
+	editor := &user{roles: []string{"editor"}}
+
+	require.True(t, authorization.HasRole(editor, "admin", "editor"))
+	require.False(t, authorization.HasRole(editor, "admin"))
+	require.False(t, authorization.HasRole(nil, "admin"))
+}
@@ -0,0 +1,139 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// ErrMissingGate is returned when checking abilities of a request
+// whose context has no [Gate], as [Middleware] is not registered.
+var ErrMissingGate = errors.New("authorization gate not found in request context")
+
+// ErrForbidden is returned when the principal of a request is not
+// allowed to perform an ability. It uses HTTP 403 Forbidden.
+var ErrForbidden = problem.Problem{
+	Title:  "Forbidden",
+	Detail: "You are not allowed to perform this action.",
+	Status: http.StatusForbidden,
+}
+
+// gateKey is the context key of the gate.
+type gateKey struct{}
+
+// Middleware returns middleware that stores the given gate in the
+// request context, where [Can], [Authorize] and the [Require]
+// middleware find it.
+func Middleware(gate *Gate) framework.Middleware {
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			ctx := context.WithValue(r.Context(), gateKey{}, gate)
+
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// Can reports whether the principal of the request, as stored by the
+// authentication middleware, can perform the ability on the resource,
+// which can be nil, see [Gate.Allows].
+func Can(r *http.Request, ability string, resource any) (bool, error) {
+	gate, ok := r.Context().Value(gateKey{}).(*Gate)
+
+	if !ok {
+		return false, ErrMissingGate
+	}
+
+	return gate.Allows(r.Context(), r.Context().Value(contract.UserKey), ability, resource)
+}
+
+// Authorize returns nil when the principal of the request can perform
+// the ability on the resource. Otherwise, it returns [ErrForbidden],
+// or [middleware.ErrUnauthorized] for requests that are not
+// authenticated, so that handlers can return it as is.
+//
+// Example usage:
+//
+//	if err := authorization.Authorize(r, "update", post); err != nil {
+//	    return err
+//	}
+func Authorize(r *http.Request, ability string, resource any) error {
+	allowed, err := Can(r, ability, resource)
+
+	if err != nil {
+		return err
+	}
+
+	return deny(r, allowed)
+}
+
+// Require returns middleware that only lets requests through when
+// their principal can perform the given ability, which does not act on
+// a resource, see [Authorize].
+//
+// Example usage:
+//
+//	app.With(authorization.Require("users.delete")).Delete("/users/{id}", deleteUser)
+func Require(ability string) framework.Middleware {
+	return RequireResource(ability, nil)
+}
+
+// RequireResource returns middleware that only lets requests through
+// when their principal can perform the given ability on the resource
+// resolved from the request by the given function, such as a model
+// found by a route parameter. Errors of the function are returned as
+// is. A nil function checks the ability without a resource.
+func RequireResource(ability string, resolve func(r *http.Request) (any, error)) framework.Middleware {
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			var resource any
+
+			if resolve != nil {
+				var err error
+
+				if resource, err = resolve(r); err != nil {
+					return err
+				}
+			}
+
+			if err := Authorize(r, ability, resource); err != nil {
+				return err
+			}
+
+			return next(w, r)
+		}
+	}
+}
+
+// RequireRole returns middleware that only lets requests through when
+// their principal has any of the given roles, see [HasRole]. It
+// doesn't need a [Gate] in the request context.
+func RequireRole(roles ...string) framework.Middleware {
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if err := deny(r, HasRole(r.Context().Value(contract.UserKey), roles...)); err != nil {
+				return err
+			}
+
+			return next(w, r)
+		}
+	}
+}
+
+// deny returns the error of a denied request, or nil when allowed.
+func deny(r *http.Request, allowed bool) error {
+	if allowed {
+		return nil
+	}
+
+	if r.Context().Value(contract.UserKey) == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	return ErrForbidden
+}
@@ -0,0 +1,126 @@
+package authorization_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/authorization"
+
+	"github.com/stretchr/testify/require"
+)
+
+func authenticate(principal *user) framework.Middleware {
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if principal == nil {
+				return next(w, r)
+			}
+
+			ctx := context.WithValue(r.Context(), contract.UserKey, principal)
+
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+func authorizationApp(principal *user) *framework.Router {
+	gate := authorization.New().Grant("admin", "users.delete")
+	authorization.Register(gate, "update", ownsPost)
+
+	app := framework.New()
+	app.Use(authenticate(principal), authorization.Middleware(gate))
+
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, "ok")
+	}
+
+	app.With(authorization.Require("users.delete")).Delete("/users/{id}", ok)
+	app.With(authorization.RequireRole("admin")).Get("/admin", ok)
+	app.With(authorization.RequireResource("update", func(r *http.Request) (any, error) {
+		author, err := strconv.Atoi(request.Param(r, "author"))
+
+		return &post{AuthorID: author}, err
+	})).Put("/posts/{author}", ok)
+
+	app.Patch("/posts/{author}", func(w http.ResponseWriter, r *http.Request) error {
+		author, _ := strconv.Atoi(request.Param(r, "author"))
+
+		if err := authorization.Authorize(r, "update", &post{AuthorID: author}); err != nil {
+			return err
+		}
+
+		return ok(w, r)
+	})
+
+	return app
+}
+
+func serve(app *framework.Router, method string, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRequireChecksAbilities(t *testing.T) {
+	t.Parallel()
+
+	admin := authorizationApp(&user{ID: 1, roles: []string{"admin"}})
+	member := authorizationApp(&user{ID: 2})
+	guest := authorizationApp(nil)
+
+	require.Equal(t, http.StatusOK, serve(admin, http.MethodDelete, "/users/3").Code)
+	require.Equal(t, http.StatusForbidden, serve(member, http.MethodDelete, "/users/3").Code)
+	require.Equal(t, http.StatusUnauthorized, serve(guest, http.MethodDelete, "/users/3").Code)
+}
+
+func TestRequireRoleChecksRoles(t *testing.T) {
+	t.Parallel()
+
+	admin := authorizationApp(&user{roles: []string{"admin"}})
+	member := authorizationApp(&user{roles: []string{"member"}})
+
+	require.Equal(t, http.StatusOK, serve(admin, http.MethodGet, "/admin").Code)
+
+	rec := serve(member, http.MethodGet, "/admin")
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "Forbidden")
+}
+
+func TestRequireResourceChecksResolvedResources(t *testing.T) {
+	t.Parallel()
+
+	app := authorizationApp(&user{ID: 1})
+
+	require.Equal(t, http.StatusOK, serve(app, http.MethodPut, "/posts/1").Code)
+	require.Equal(t, http.StatusForbidden, serve(app, http.MethodPut, "/posts/2").Code)
+	require.Equal(t, http.StatusInternalServerError, serve(app, http.MethodPut, "/posts/abc").Code)
+}
+
+func TestAuthorizeChecksAbilitiesInHandlers(t *testing.T) {
+	t.Parallel()
+
+	app := authorizationApp(&user{ID: 1})
+
+	require.Equal(t, http.StatusOK, serve(app, http.MethodPatch, "/posts/1").Code)
+	require.Equal(t, http.StatusForbidden, serve(app, http.MethodPatch, "/posts/2").Code)
+}
+
+func TestCanRequiresGate(t *testing.T) {
+	t.Parallel()
+
+	_, err := authorization.Can(httptest.NewRequest(http.MethodGet, "/", nil), "update", nil)
+
+	require.ErrorIs(t, err, authorization.ErrMissingGate)
+}
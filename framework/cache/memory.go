@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/studiolambda/cosmos/contract"
@@ -16,6 +18,9 @@ import (
 // where persistence across restarts is not required.
 type Memory struct {
 	store *cache.Cache
+
+	// mutex guards the conversion of counters stored as bytes.
+	mutex sync.Mutex
 }
 
 // NewMemory creates a Memory cache with the given default expiration
@@ -28,8 +33,9 @@ func NewMemory(expiration time.Duration, cleanup time.Duration) *Memory {
 }
 
 // Get retrieves the raw bytes for the given key from the in-memory
-// store. Returns [contract.ErrCacheKeyNotFound] when the key does
-// not exist or has expired.
+// store. Counters are returned as decimal bytes, like Redis does.
+// Returns [contract.ErrCacheKeyNotFound] when the key does not
+// exist or has expired.
 func (memory *Memory) Get(_ context.Context, key string) ([]byte, error) {
 	val, found := memory.store.Get(key)
 
@@ -37,6 +43,10 @@ func (memory *Memory) Get(_ context.Context, key string) ([]byte, error) {
 		return nil, contract.ErrCacheKeyNotFound
 	}
 
+	if counter, ok := val.(int64); ok {
+		return []byte(strconv.FormatInt(counter, 10)), nil
+	}
+
 	raw, ok := val.([]byte)
 
 	if !ok {
@@ -77,27 +87,56 @@ func (memory *Memory) Store() *cache.Cache {
 }
 
 // Increment atomically increases the integer value stored at key by
-// the given amount. Returns [contract.ErrCacheKeyNotFound] if the key
-// does not exist.
+// the given amount. Values stored with Put as decimal bytes are
+// treated as counters, keeping their expiration. Returns
+// [contract.ErrCacheKeyNotFound] if the key does not exist.
 func (memory *Memory) Increment(_ context.Context, key string, delta int64) (int64, error) {
-	result, err := memory.store.IncrementInt64(key, delta)
-
-	if err != nil {
-		return 0, contract.ErrCacheKeyNotFound
-	}
-
-	return result, nil
+	return memory.add(key, delta)
 }
 
 // Decrement atomically decreases the integer value stored at key by
-// the given amount. Returns [contract.ErrCacheKeyNotFound] if the key
-// does not exist.
+// the given amount. Values stored with Put as decimal bytes are
+// treated as counters, keeping their expiration. Returns
+// [contract.ErrCacheKeyNotFound] if the key does not exist.
 func (memory *Memory) Decrement(_ context.Context, key string, delta int64) (int64, error) {
-	result, err := memory.store.DecrementInt64(key, delta)
+	return memory.add(key, -delta)
+}
+
+// add atomically adds the given delta to the counter stored at key,
+// converting counters stored as decimal bytes into integers.
+func (memory *Memory) add(key string, delta int64) (int64, error) {
+	if result, err := memory.store.IncrementInt64(key, delta); err == nil {
+		return result, nil
+	}
+
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	value, expiration, found := memory.store.GetWithExpiration(key)
+	raw, ok := value.([]byte)
+
+	if !found || !ok {
+		// The counter may have been converted while waiting for the lock.
+		if result, err := memory.store.IncrementInt64(key, delta); err == nil {
+			return result, nil
+		}
+
+		return 0, contract.ErrCacheKeyNotFound
+	}
+
+	current, err := strconv.ParseInt(string(raw), 10, 64)
 
 	if err != nil {
 		return 0, contract.ErrCacheKeyNotFound
 	}
 
-	return result, nil
+	ttl := cache.NoExpiration
+
+	if !expiration.IsZero() {
+		ttl = time.Until(expiration)
+	}
+
+	memory.store.Set(key, current+delta, ttl)
+
+	return current + delta, nil
 }
@@ -284,3 +284,22 @@ func TestCacheWrapperIncrementDelegatesToDriver(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, int64(15), result)
 }
+
+func TestMemoryIncrementConvertsStoredBytes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mem := cache.NewMemory(5*time.Minute, 10*time.Minute)
+
+	require.NoError(t, mem.Put(ctx, "counter", []byte("10"), time.Minute))
+
+	result, err := mem.Increment(ctx, "counter", 5)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(15), result)
+
+	raw, err := mem.Get(ctx, "counter")
+
+	require.NoError(t, err)
+	require.Equal(t, "15", string(raw))
+}
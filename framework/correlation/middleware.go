@@ -10,6 +10,7 @@ import (
 	"github.com/studiolambda/cosmos/contract"
 	"github.com/studiolambda/cosmos/contract/request"
 	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/tracing"
 )
 
 // DefaultHeader is the default HTTP header used to
@@ -38,10 +39,11 @@ type Options struct {
 // a correlation ID for distributed tracing. It checks for an
 // existing ID in the following order:
 //
-//  1. The W3C traceparent header (extracts the trace ID component)
-//  2. The X-Correlation-ID header
+//  1. The trace ID of the span started by [tracing.Middleware]
+//  2. The W3C traceparent header (extracts the trace ID component)
+//  3. The X-Correlation-ID header
 //
-// If neither is present, a new 16-byte random hex ID is generated.
+// If none is present, a new 16-byte random hex ID is generated.
 // The correlation ID is stored in the request context and set on
 // the response header.
 //
@@ -73,7 +75,15 @@ func MiddlewareWith(options Options) framework.Middleware {
 
 	return func(next framework.Handler) framework.Handler {
 		return func(w http.ResponseWriter, r *http.Request) error {
-			id := extractTraceID(r)
+			id := ""
+
+			if spanContext := tracing.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+				id = spanContext.TraceID.String()
+			}
+
+			if id == "" {
+				id = extractTraceID(r)
+			}
 
 			if id == "" {
 				id = r.Header.Get(options.Header)
@@ -9,6 +9,7 @@ import (
 	"github.com/studiolambda/cosmos/contract/request"
 	"github.com/studiolambda/cosmos/framework"
 	"github.com/studiolambda/cosmos/framework/correlation"
+	"github.com/studiolambda/cosmos/framework/tracing"
 
 	"github.com/stretchr/testify/require"
 )
@@ -82,6 +83,37 @@ func TestMiddlewareExtractsFromTraceparent(t *testing.T) {
 	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", res.Header.Get("X-Correlation-ID"))
 }
 
+func TestMiddlewareUsesTraceIDOfCurrentSpan(t *testing.T) {
+	t.Parallel()
+
+	var captured, traceID string
+
+	handler := correlation.Middleware()(framework.Handler(func(
+		w http.ResponseWriter,
+		r *http.Request,
+	) error {
+		captured = request.CorrelationID(r)
+		traceID = tracing.SpanContextFromContext(r.Context()).TraceID.String()
+		w.WriteHeader(http.StatusOK)
+
+		return nil
+	}))
+
+	tracer := tracing.New(tracing.NewMemoryExporter())
+	handler = tracing.MiddlewareWith(tracing.MiddlewareOptions{
+		Tracer:         tracer,
+		IgnoreIncoming: true,
+	})(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	res := handler.Record(req)
+
+	require.Equal(t, traceID, captured)
+	require.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", captured)
+	require.Equal(t, captured, res.Header.Get("X-Correlation-ID"))
+}
+
 func TestMiddlewareTraceparentTakesPrecedenceOverHeader(t *testing.T) {
 	t.Parallel()
 
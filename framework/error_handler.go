@@ -0,0 +1,268 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// ErrorMapper converts an error returned by a [Handler] into the
+// error that is rendered instead, reporting whether it applies.
+type ErrorMapper func(err error) (error, bool)
+
+// ErrorReporter receives every error rendered by an [ErrorHandler]
+// along with the status code of its response, e.g. to send it to
+// an error tracking service. It runs synchronously, so it should
+// hand the error off quickly.
+type ErrorReporter func(r *http.Request, err error, status int)
+
+// ErrorHandlerOptions configures an [ErrorHandler] created
+// with [ErrorHandlerWith].
+type ErrorHandlerOptions struct {
+	// Development renders problems with their error stack trace,
+	// see [problem.Problem.ServeHTTPDev]. It MUST NOT be enabled
+	// in production as it exposes internal details to clients.
+	Development bool
+
+	// Redact renders the server errors that no mapping matched as a
+	// generic problem that only carries the status code and its
+	// text, regardless of how the error would render itself. It is
+	// ignored when Development is enabled.
+	Redact bool
+
+	// Reporter is called with every error the handler renders,
+	// including the ones returned after the response was already
+	// partially written. Defaults to no reporting.
+	Reporter ErrorReporter
+}
+
+// ErrorHandler renders the errors returned by [Handler] values. Errors
+// are first matched against the registered mappings, in registration
+// order, so that domain errors become specific responses:
+//
+//	renderer := framework.NewErrorHandler().
+//	    MapIs(sql.ErrNoRows, framework.ErrNotFound)
+//
+//	framework.MapAs(renderer, func(err *ValidationError) error {
+//	    return ErrUnprocessable.With("fields", err.Fields)
+//	})
+//
+//	app.Use(renderer.Middleware())
+//
+// The resulting error is then rendered the same way [Handler] does when
+// no ErrorHandler is installed: context cancellations use
+// [StatusClientClosedRequest], errors implementing [HTTPStatus] use
+// their status code, errors implementing [http.Handler] render
+// themselves and any other error becomes a [problem.Problem].
+//
+// Mappings must be registered before the handler starts serving
+// requests, as an ErrorHandler is not safe for concurrent registration.
+type ErrorHandler struct {
+	// mappers stores the registered mappings in
+	// registration order.
+	mappers []ErrorMapper
+
+	// options stores the options of the handler.
+	options ErrorHandlerOptions
+}
+
+// errorHandlerKey is the context key under which the [Handler] stores
+// the slot of the [ErrorHandler] that renders the request errors.
+type errorHandlerKey struct{}
+
+// errorHandlerSlot stores the [ErrorHandler] installed for a request
+// by [ErrorHandler.Middleware]. It's a pointer stored in the request
+// context, so that middleware can install the handler on the request
+// served by the outermost [Handler].
+type errorHandlerSlot struct {
+	// handler stores the installed handler.
+	handler *ErrorHandler
+}
+
+// defaultErrorHandler renders the errors of the requests that
+// have no [ErrorHandler] installed.
+var defaultErrorHandler = NewErrorHandler()
+
+// NewErrorHandler creates an [ErrorHandler] with no mappings that
+// renders errors exactly like [Handler] does by default.
+func NewErrorHandler() *ErrorHandler {
+	return ErrorHandlerWith(ErrorHandlerOptions{})
+}
+
+// ErrorHandlerWith creates an [ErrorHandler] with no mappings
+// using the given options.
+func ErrorHandlerWith(options ErrorHandlerOptions) *ErrorHandler {
+	return &ErrorHandler{
+		mappers: make([]ErrorMapper, 0),
+		options: options,
+	}
+}
+
+// Map registers a mapping that converts the errors it applies to.
+func (handler *ErrorHandler) Map(mapper ErrorMapper) *ErrorHandler {
+	handler.mappers = append(handler.mappers, mapper)
+
+	return handler
+}
+
+// MapIs registers a mapping that renders the given error instead of
+// the errors that match the target using [errors.Is]. When the given
+// error is a [problem.Problem] without an underlying error, the
+// original error is attached to it so that it's still reported and
+// shown in development stack traces.
+func (handler *ErrorHandler) MapIs(target error, mapped error) *ErrorHandler {
+	return handler.Map(func(err error) (error, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+
+		return withCause(mapped, err), true
+	})
+}
+
+// MapAs registers a mapping on the given handler that converts the
+// errors matching the type T using [errors.As]. Like
+// [ErrorHandler.MapIs], the original error is attached to the
+// returned [problem.Problem] when it has no underlying error.
+func MapAs[T error](handler *ErrorHandler, mapper func(err T) error) *ErrorHandler {
+	return handler.Map(func(err error) (error, bool) {
+		var target T
+
+		if !errors.As(err, &target) {
+			return nil, false
+		}
+
+		return withCause(mapper(target), err), true
+	})
+}
+
+// Middleware returns a [Middleware] that installs the handler for the
+// requests it serves, so that their errors are rendered by it. It
+// should be the first middleware of the application, as errors are
+// rendered by the default handler until it runs:
+//
+//	app.Use(renderer.Middleware())
+func (handler *ErrorHandler) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if slot, ok := r.Context().Value(errorHandlerKey{}).(*errorHandlerSlot); ok {
+				slot.handler = handler
+			}
+
+			return next(w, r)
+		}
+	}
+}
+
+// Status returns the status code the given error is rendered with.
+func (handler *ErrorHandler) Status(err error) int {
+	mapped, _ := handler.mapped(err)
+
+	return errorStatus(mapped)
+}
+
+// Render writes the response of the given error and reports it.
+func (handler *ErrorHandler) Render(w http.ResponseWriter, r *http.Request, err error) {
+	mapped, ok := handler.mapped(err)
+	status := errorStatus(mapped)
+
+	handler.report(r, err, status)
+
+	if handler.options.Development {
+		handler.renderDevelopment(w, r, mapped, status)
+
+		return
+	}
+
+	if handler.options.Redact && !ok && status >= http.StatusInternalServerError {
+		problem.NewProblem(err, status).ServeHTTP(w, r)
+
+		return
+	}
+
+	// When the error itself implements http.Handler, delegate
+	// rendering entirely to it. This allows error types like
+	// problem.Problem to control their own HTTP response format.
+	if target := (http.Handler)(nil); errors.As(mapped, &target) {
+		target.ServeHTTP(w, r)
+
+		return
+	}
+
+	problem.NewProblem(mapped, status).ServeHTTP(w, r)
+}
+
+// renderDevelopment writes the response of the given error including
+// its stack trace whenever it renders as a [problem.Problem].
+func (handler *ErrorHandler) renderDevelopment(w http.ResponseWriter, r *http.Request, err error, status int) {
+	if target := (problem.Problem{}); errors.As(err, &target) {
+		target.ServeHTTPDev(w, r)
+
+		return
+	}
+
+	if target := (http.Handler)(nil); errors.As(err, &target) {
+		target.ServeHTTP(w, r)
+
+		return
+	}
+
+	problem.NewProblem(err, status).ServeHTTPDev(w, r)
+}
+
+// report sends the given error to the configured [ErrorReporter].
+func (handler *ErrorHandler) report(r *http.Request, err error, status int) {
+	if handler.options.Reporter != nil {
+		handler.options.Reporter(r, err, status)
+	}
+}
+
+// mapped returns the error the given one is rendered as, reporting
+// whether any mapping applied to it.
+func (handler *ErrorHandler) mapped(err error) (error, bool) {
+	for _, mapper := range handler.mappers {
+		if mapped, ok := mapper(err); ok && mapped != nil {
+			return mapped, true
+		}
+	}
+
+	return err, false
+}
+
+// errorStatus returns the status code of the given error, inspecting
+// it for context cancellation and custom status codes via [HTTPStatus].
+func errorStatus(err error) int {
+	status := http.StatusInternalServerError
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		status = StatusClientClosedRequest
+	}
+
+	if target := (HTTPStatus)(nil); errors.As(err, &target) {
+		status = target.HTTPStatus()
+	}
+
+	return status
+}
+
+// withCause attaches the given cause to the mapped error when it's a
+// [problem.Problem] without an underlying error.
+func withCause(mapped error, cause error) error {
+	if target, ok := mapped.(problem.Problem); ok && target.Unwrap() == nil {
+		return target.WithError(cause)
+	}
+
+	return mapped
+}
+
+// errorHandlerFrom returns the [ErrorHandler] installed in the given
+// slot, or the default one when there's none.
+func errorHandlerFrom(slot *errorHandlerSlot) *ErrorHandler {
+	if slot.handler != nil {
+		return slot.handler
+	}
+
+	return defaultErrorHandler
+}
@@ -0,0 +1,234 @@
+package framework_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/problem"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errMissingUser = errors.New("missing user")
+
+type validationError struct {
+	field string
+}
+
+func (err *validationError) Error() string {
+	return "invalid " + err.field
+}
+
+func failing(err error) framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return err
+	}
+}
+
+func decodeProblem(t *testing.T, res *http.Response) problem.Problem {
+	t.Helper()
+
+	var decoded problem.Problem
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&decoded))
+
+	return decoded
+}
+
+func TestErrorHandlerMapIs(t *testing.T) {
+	t.Parallel()
+
+	renderer := framework.NewErrorHandler().MapIs(errMissingUser, framework.ErrNotFound)
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Get("/users/{id}", failing(errMissingUser))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Equal(t, framework.ErrNotFound.Title, decodeProblem(t, res).Title)
+}
+
+func TestErrorHandlerMapAs(t *testing.T) {
+	t.Parallel()
+
+	renderer := framework.NewErrorHandler()
+
+	framework.MapAs(renderer, func(err *validationError) error {
+		return problem.Problem{Status: http.StatusUnprocessableEntity}.With("field", err.field)
+	})
+
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Post("/users", failing(&validationError{field: "email"}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+	field, _ := decodeProblem(t, res).Additional("field")
+
+	require.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	require.Equal(t, "email", field)
+}
+
+func TestErrorHandlerUsesFirstMatchingMapping(t *testing.T) {
+	t.Parallel()
+
+	renderer := framework.NewErrorHandler().
+		MapIs(errMissingUser, framework.ErrNotFound).
+		MapIs(errMissingUser, framework.ErrMethodNotAllowed)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	require.Equal(t, http.StatusNotFound, renderer.Status(errMissingUser))
+
+	rec := httptest.NewRecorder()
+	renderer.Render(rec, req, errMissingUser)
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestErrorHandlerKeepsDefaultRendering(t *testing.T) {
+	t.Parallel()
+
+	renderer := framework.NewErrorHandler().MapIs(errMissingUser, framework.ErrNotFound)
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Get("/", failing(problem.Problem{Status: http.StatusConflict}))
+
+	res := app.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+}
+
+func TestErrorHandlerDevelopmentRendersStackTrace(t *testing.T) {
+	t.Parallel()
+
+	renderer := framework.ErrorHandlerWith(framework.ErrorHandlerOptions{Development: true})
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Get("/", failing(errMissingUser))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+	trace, ok := decodeProblem(t, res).Additional(problem.StackTraceKey)
+
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.True(t, ok)
+	require.Contains(t, trace, "missing user")
+}
+
+func TestErrorHandlerRedactsServerErrors(t *testing.T) {
+	t.Parallel()
+
+	renderer := framework.ErrorHandlerWith(framework.ErrorHandlerOptions{Redact: true})
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Get("/server", failing(problem.Problem{
+		Detail: "connection to 10.0.0.1 refused",
+		Status: http.StatusBadGateway,
+	}))
+	app.Get("/client", failing(problem.Problem{
+		Detail: "the email is taken",
+		Status: http.StatusConflict,
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/server", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+
+	require.Equal(t, http.StatusBadGateway, res.StatusCode)
+	require.Empty(t, decodeProblem(t, res).Detail)
+
+	req = httptest.NewRequest(http.MethodGet, "/client", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res = app.Record(req)
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+	require.Equal(t, "the email is taken", decodeProblem(t, res).Detail)
+}
+
+func TestErrorHandlerReportsErrors(t *testing.T) {
+	t.Parallel()
+
+	var reported error
+	var status int
+
+	renderer := framework.ErrorHandlerWith(framework.ErrorHandlerOptions{
+		Reporter: func(r *http.Request, err error, code int) {
+			reported, status = err, code
+		},
+	}).MapIs(errMissingUser, framework.ErrNotFound)
+
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Get("/", failing(errMissingUser))
+
+	app.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.ErrorIs(t, reported, errMissingUser)
+	require.Equal(t, http.StatusNotFound, status)
+}
+
+func TestErrorHandlerReportsErrorsAfterPartialWrite(t *testing.T) {
+	t.Parallel()
+
+	var reported error
+
+	renderer := framework.ErrorHandlerWith(framework.ErrorHandlerOptions{
+		Reporter: func(r *http.Request, err error, code int) {
+			reported = err
+		},
+	})
+
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		_ = response.Status(w, http.StatusOK)
+
+		return errMissingUser
+	})
+
+	res := app.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.ErrorIs(t, reported, errMissingUser)
+}
+
+func TestErrorHandlerAfterResponseHooksReceiveOriginalError(t *testing.T) {
+	t.Parallel()
+
+	var received error
+
+	renderer := framework.NewErrorHandler().MapIs(errMissingUser, framework.ErrNotFound)
+	app := framework.New()
+	app.Use(renderer.Middleware())
+	app.Use(func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			request.Hooks(r).AfterResponse(func(err error) {
+				received = err
+			})
+
+			return next(w, r)
+		}
+	})
+	app.Get("/", failing(errMissingUser))
+
+	app.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.ErrorIs(t, received, errMissingUser)
+}
@@ -1,11 +1,61 @@
 package framework
 
 import (
+	"net/http"
+
 	_ "github.com/studiolambda/cosmos/contract"
-	_ "github.com/studiolambda/cosmos/problem"
+	"github.com/studiolambda/cosmos/problem"
 	"github.com/studiolambda/cosmos/router"
 )
 
+// ErrNotFound is the error returned by the default handler that
+// [New] registers with [router.Router.NotFound], rendered as an
+// RFC 9457 problem like any other handler error.
+var ErrNotFound = problem.Problem{
+	Title:  "Not Found",
+	Detail: "The requested resource could not be found.",
+	Status: http.StatusNotFound,
+}
+
+// ErrMethodNotAllowed is the error returned by the default handler
+// that [New] registers with [router.Router.MethodNotAllowed]. The
+// Allow header listing the supported methods is set by the router
+// before the handler runs.
+var ErrMethodNotAllowed = problem.Problem{
+	Title:  "Method Not Allowed",
+	Detail: "The requested method is not supported by the resource.",
+	Status: http.StatusMethodNotAllowed,
+}
+
+// ErrInvalidParameter is the error returned by [InvalidParameter] when
+// a route wildcard value doesn't satisfy its constraint. The problem
+// carries the "parameter" and "constraint" extensions, but never the
+// rejected value.
+var ErrInvalidParameter = problem.Problem{
+	Title:  "Invalid Parameter",
+	Detail: "A route parameter does not have the expected format.",
+	Status: http.StatusBadRequest,
+}
+
+// InvalidParameter is a [Handler] that answers the requests whose
+// route wildcard values don't satisfy the route constraints with an
+// [ErrInvalidParameter] problem. By default such requests are treated
+// as not found, register it to answer them with a 400 instead:
+//
+//	app.InvalidParameter(framework.InvalidParameter)
+//	app.Get("/users/{id}", show).Where("id", router.Int())
+func InvalidParameter(w http.ResponseWriter, r *http.Request) error {
+	err, ok := router.ParameterErrorFrom(r)
+
+	if !ok {
+		return ErrInvalidParameter
+	}
+
+	return ErrInvalidParameter.
+		With("parameter", err.Parameter).
+		With("constraint", err.Constraint.String())
+}
+
 // Router is the HTTP router type used by Cosmos applications.
 // It provides routing functionality with support for path parameters,
 // middleware, and handler composition. The router uses generics to
@@ -31,6 +81,10 @@ type Middleware = router.Middleware[Handler]
 //   - Path parameters and wildcards
 //   - Middleware composition
 //   - Route groups for organizing related endpoints
+//   - RFC 9457 problem responses for unmatched routes, see [ErrNotFound]
+//     and [ErrMethodNotAllowed], which can be replaced with
+//     [router.Router.NotFound] and [router.Router.MethodNotAllowed]
+//   - Wildcard constraints, see [router.Route.Where] and [InvalidParameter]
 //
 // Example usage:
 //
@@ -38,5 +92,15 @@ type Middleware = router.Middleware[Handler]
 //	app.Get("/users/{id}", getUserHandler)
 //	http.ListenAndServe(":8080", app)
 func New() *Router {
-	return router.New[Handler]()
+	app := router.New[Handler]()
+
+	app.NotFound(func(w http.ResponseWriter, r *http.Request) error {
+		return ErrNotFound
+	})
+
+	app.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) error {
+		return ErrMethodNotAllowed
+	})
+
+	return app
 }
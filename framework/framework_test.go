@@ -1,9 +1,14 @@
 package framework_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/problem"
+	"github.com/studiolambda/cosmos/router"
 
 	"github.com/stretchr/testify/require"
 )
@@ -15,3 +20,116 @@ func TestNewCreatesRouter(t *testing.T) {
 
 	require.NotNil(t, router)
 }
+
+func TestNewRendersNotFoundProblem(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+}
+
+func TestNewRendersMethodNotAllowedProblem(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+
+	require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	require.Equal(t, "GET, HEAD", res.Header.Get("Allow"))
+	require.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+}
+
+func TestNewNotFoundRunsThroughMiddleware(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Use(func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("X-Middleware", "applied")
+
+			return next(w, r)
+		}
+	})
+
+	res := app.Record(httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Equal(t, "applied", res.Header.Get("X-Middleware"))
+}
+
+type users struct{}
+
+func (users) Show() framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+
+		return nil
+	}
+}
+
+func TestResourceRegistersFrameworkHandlers(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Resource("/users", users{})
+
+	res := app.Record(httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestConstraintViolationIsNotFoundByDefault(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}).Where("id", router.Int())
+
+	res := app.Record(httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestInvalidParameterRendersProblem(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.InvalidParameter(framework.InvalidParameter)
+	app.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}).Where("id", router.Int())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/abc", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := app.Record(req)
+
+	var decoded problem.Problem
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&decoded))
+
+	parameter, _ := decoded.Additional("parameter")
+	constraint, _ := decoded.Additional("constraint")
+
+	require.Equal(t, "id", parameter)
+	require.Equal(t, "int", constraint)
+}
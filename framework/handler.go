@@ -2,13 +2,11 @@ package framework
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 
 	"github.com/studiolambda/cosmos/contract"
-	"github.com/studiolambda/cosmos/problem"
 )
 
 // Handler defines the function signature for HTTP request handlers in Cosmos.
@@ -58,56 +56,39 @@ type HTTPStatus interface {
 // when the client closes the connection before the server responds.
 const StatusClientClosedRequest = 499
 
-// handleError writes an error response by inspecting the error for context
-// cancellation, custom status codes via [HTTPStatus], or self-rendering
-// capability via [http.Handler], falling back to a Problem Details response.
-func handleError(w http.ResponseWriter, r *http.Request, err error) {
-	status := http.StatusInternalServerError
-
-	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-		status = StatusClientClosedRequest
-	}
-
-	if target := (HTTPStatus)(nil); errors.As(err, &target) {
-		status = target.HTTPStatus()
-	}
-
-	// When the error itself implements http.Handler, delegate
-	// rendering entirely to it. This allows error types like
-	// problem.Problem to control their own HTTP response format.
-	if target := (http.Handler)(nil); errors.As(err, &target) {
-		target.ServeHTTP(w, r)
-		return
-	}
-
-	problem.NewProblem(err, status).ServeHTTP(w, r)
-}
-
 // ServeHTTP implements the http.Handler interface, bridging Cosmos's
 // error-returning handlers with Go's standard handler contract.
 //
 // It wraps the response writer with lifecycle hooks, calls the
-// handler, and delegates error rendering to handleError when the
-// handler fails. If the handler already started writing the
-// response (WriteHeader was called), the error is logged instead
-// of attempting a second write which would corrupt the response.
+// handler, and delegates error rendering to the [ErrorHandler]
+// installed by [ErrorHandler.Middleware], or to the default one,
+// when the handler fails. If the handler already started writing
+// the response (WriteHeader was called), the error is logged and
+// reported instead of attempting a second write which would
+// corrupt the response.
 //
-// Errors implementing [HTTPStatus] get their custom status code,
-// and errors implementing [http.Handler] render themselves
-// directly. If no status code has been written after the handler
-// returns, a 204 No Content is sent as the default.
+// By default, errors implementing [HTTPStatus] get their custom
+// status code, and errors implementing [http.Handler] render
+// themselves directly. If no status code has been written after
+// the handler returns, a 204 No Content is sent as the default.
 // AfterResponse hooks run last, regardless of success or failure.
 func (handler Handler) ServeHTTP(
 	w http.ResponseWriter,
 	r *http.Request,
 ) {
 	hooks := contract.NewHooks()
+	slot := &errorHandlerSlot{}
 	wrapped := NewResponseWriter(w, hooks)
 	ctx := context.WithValue(r.Context(), contract.HooksKey, hooks)
+	ctx = context.WithValue(ctx, errorHandlerKey{}, slot)
 	err := handler(wrapped, r.WithContext(ctx))
 
 	if err != nil {
+		renderer := errorHandlerFrom(slot)
+
 		if wrapped.WriteHeaderCalled() {
+			renderer.report(r, err, renderer.Status(err))
+
 			slog.ErrorContext(
 				r.Context(),
 				"handler error after partial response write",
@@ -116,7 +97,7 @@ func (handler Handler) ServeHTTP(
 				"err", err,
 			)
 		} else {
-			handleError(wrapped, r.WithContext(ctx), err)
+			renderer.Render(wrapped, r.WithContext(ctx), err)
 		}
 	}
 
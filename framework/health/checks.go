@@ -0,0 +1,88 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// ErrCacheMismatch is reported by the [Cache] check when the value
+// read back from the cache differs from the one written.
+var ErrCacheMismatch = errors.New("cache returned a different value")
+
+// checkTTL is the time to live of the keys written by the [Cache]
+// check, in case deleting them fails.
+const checkTTL = time.Minute
+
+// Database returns a [Check] that pings the given database driver.
+func Database(driver contract.DatabaseDriver) Check {
+	return driver.Ping
+}
+
+// Cache returns a [Check] that performs a put, get and delete round
+// trip of a unique key on the given cache driver.
+func Cache(driver contract.CacheDriver) Check {
+	return func(ctx context.Context) error {
+		key := "health:" + rand.Text()
+		value := []byte(rand.Text())
+
+		if err := driver.Put(ctx, key, value, checkTTL); err != nil {
+			return err
+		}
+
+		stored, err := driver.Get(ctx, key)
+
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(stored, value) {
+			return ErrCacheMismatch
+		}
+
+		return driver.Delete(ctx, key)
+	}
+}
+
+// Events returns a [Check] that subscribes to a unique event on the
+// given event driver and waits until a payload published to it is
+// delivered back.
+func Events(driver contract.EventDriver) Check {
+	return func(ctx context.Context) error {
+		event := "health." + rand.Text()
+		payload := []byte(rand.Text())
+		received := make(chan struct{}, 1)
+
+		unsubscribe, err := driver.Subscribe(ctx, event, func(delivered []byte) {
+			if bytes.Equal(delivered, payload) {
+				select {
+				case received <- struct{}{}:
+				default:
+				}
+			}
+		})
+
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = unsubscribe()
+		}()
+
+		if err := driver.Publish(ctx, event, payload); err != nil {
+			return err
+		}
+
+		select {
+		case <-received:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/framework/cache"
+	"github.com/studiolambda/cosmos/framework/event"
+	"github.com/studiolambda/cosmos/framework/health"
+
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabasePingsDriver(t *testing.T) {
+	t.Parallel()
+
+	driver := mock.NewDatabaseDriverMock(t)
+	driver.On("Ping", tmock.Anything).Return(errors.New("down")).Once()
+
+	require.EqualError(t, health.Database(driver)(context.Background()), "down")
+}
+
+func TestCachePerformsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	driver := cache.NewMemory(time.Minute, time.Minute)
+
+	require.NoError(t, health.Cache(driver)(t.Context()))
+}
+
+func TestCacheDetectsMismatch(t *testing.T) {
+	t.Parallel()
+
+	driver := mock.NewCacheDriverMock(t)
+	driver.On("Put", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).Return(nil).Once()
+	driver.On("Get", tmock.Anything, tmock.Anything).Return([]byte("other"), nil).Once()
+
+	require.ErrorIs(t, health.Cache(driver)(t.Context()), health.ErrCacheMismatch)
+}
+
+func TestEventsPerformsLoopback(t *testing.T) {
+	t.Parallel()
+
+	broker := event.NewMemoryBroker()
+	defer broker.Close()
+
+	require.NoError(t, health.Events(broker)(t.Context()))
+}
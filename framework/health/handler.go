@@ -0,0 +1,49 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+)
+
+// ContentType is the media type of the reports served by the handlers.
+const ContentType = "application/health+json"
+
+// LivenessHandler returns a [framework.Handler] that serves the
+// liveness [Report], see [Health.Liveness].
+func (health *Health) LivenessHandler() framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return serve(w, health.Liveness(r.Context()))
+	}
+}
+
+// ReadinessHandler returns a [framework.Handler] that serves the
+// readiness [Report], see [Health.Readiness].
+func (health *Health) ReadinessHandler() framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return serve(w, health.Readiness(r.Context()))
+	}
+}
+
+// serve writes the given report with a 200 status code, or with a
+// 503 when it failed.
+func serve(w http.ResponseWriter, report Report) error {
+	encoded, err := json.Marshal(report)
+
+	if err != nil {
+		return err
+	}
+
+	status := http.StatusOK
+
+	if report.Status == StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+
+	return response.Raw(w, status, encoded)
+}
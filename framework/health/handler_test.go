@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/health"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessHandlerServesReport(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().Register("database", passing)
+	res := checks.ReadinessHandler().Record(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report health.Report
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, health.ContentType, res.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	require.Equal(t, health.StatusPass, report.Status)
+	require.Equal(t, "ms", report.Checks["database"][0].ObservedUnit)
+}
+
+func TestReadinessHandlerFailsWithServiceUnavailable(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().Register("database", failing)
+	res := checks.ReadinessHandler().Record(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
+
+func TestReadinessHandlerFailsWhileDraining(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New()
+	checks.Drain()
+
+	res := checks.ReadinessHandler().Record(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
+
+func TestLivenessHandlerServesReport(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().Register("database", failing)
+	res := checks.LivenessHandler().Record(httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
@@ -0,0 +1,389 @@
+// Package health provides liveness and readiness checks for Cosmos
+// applications and the handlers that serve them.
+//
+// Checks are registered by name on a [Health] and run concurrently,
+// each one within its own timeout. Their results are aggregated into
+// a [Report] that follows the "application/health+json" format:
+//
+//	checks := health.New()
+//	checks.Register("database", health.Database(db))
+//	checks.RegisterWith("cache", health.Cache(redis), health.CheckOptions{
+//	    Optional: true,
+//	    CacheTTL: 10 * time.Second,
+//	})
+//
+//	app.Get("/livez", checks.LivenessHandler())
+//	app.Get("/readyz", checks.ReadinessHandler())
+package health
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the status of a check or of a whole [Report].
+type Status string
+
+const (
+	// StatusPass reports a healthy check.
+	StatusPass Status = "pass"
+
+	// StatusWarn reports an optional check that failed. It does not
+	// fail the aggregate status.
+	StatusWarn Status = "warn"
+
+	// StatusFail reports a critical check that failed, which fails
+	// the aggregate status.
+	StatusFail Status = "fail"
+)
+
+// DefaultTimeout is the default time a check has to complete.
+const DefaultTimeout = 5 * time.Second
+
+// ErrDraining is reported by the readiness checks once [Health.Drain]
+// has been called.
+var ErrDraining = errors.New("the application is shutting down")
+
+// ErrTimeout is reported by checks that did not complete within
+// their timeout.
+var ErrTimeout = errors.New("health check timed out")
+
+// Check reports the health of a dependency, returning nil when healthy.
+// The given context is cancelled once the check timeout elapses.
+type Check func(ctx context.Context) error
+
+// CheckOptions configures a check registered with [Health.RegisterWith].
+type CheckOptions struct {
+	// Timeout limits the time the check has to complete.
+	// Defaults to [DefaultTimeout].
+	Timeout time.Duration
+
+	// Optional reports failures of the check as [StatusWarn]
+	// without failing the aggregate status.
+	Optional bool
+
+	// CacheTTL caches the result of the check for the given
+	// duration, so that frequent probes don't overload the
+	// dependency. Defaults to no caching.
+	CacheTTL time.Duration
+
+	// Liveness includes the check in the liveness report as well.
+	// By default checks only run for readiness, as liveness should
+	// only fail when restarting the process would fix it.
+	Liveness bool
+}
+
+// Result is the result of a single check.
+type Result struct {
+	// Status is the status of the check.
+	Status Status `json:"status"`
+
+	// Output is the error message of a failed check.
+	Output string `json:"output,omitempty"`
+
+	// ObservedValue is the time the check took to complete.
+	ObservedValue int64 `json:"observedValue"`
+
+	// ObservedUnit is the unit of ObservedValue, always "ms".
+	ObservedUnit string `json:"observedUnit"`
+
+	// Time is the time the check completed.
+	Time time.Time `json:"time"`
+}
+
+// Report is the aggregated result of the checks, in the
+// "application/health+json" format.
+type Report struct {
+	// Status is the aggregate status: [StatusFail] when any critical
+	// check failed, [StatusWarn] when any optional check failed and
+	// [StatusPass] otherwise.
+	Status Status `json:"status"`
+
+	// Checks maps the name of each check to its results.
+	Checks map[string][]Result `json:"checks,omitempty"`
+}
+
+// Health stores the registered checks and runs them.
+// It is safe for concurrent use.
+type Health struct {
+	// mutex guards the registered checks.
+	mutex sync.RWMutex
+
+	// checks stores the registered checks keyed by their name.
+	checks map[string]*registeredCheck
+
+	// draining reports whether [Health.Drain] was called.
+	draining atomic.Bool
+}
+
+// registeredCheck is a check registered in a [Health] along
+// with its options and cached result.
+type registeredCheck struct {
+	// check stores the check function.
+	check Check
+
+	// options stores the options of the check.
+	options CheckOptions
+
+	// mutex guards the fields below.
+	mutex sync.Mutex
+
+	// cached stores the last result when caching is enabled.
+	cached Result
+
+	// expires stores when the cached result expires.
+	expires time.Time
+
+	// pending stores the run in progress, if any, whose
+	// result concurrent callers wait for.
+	pending *pendingRun
+}
+
+// pendingRun is a run of a check in progress.
+type pendingRun struct {
+	// done is closed once the result is available.
+	done chan struct{}
+
+	// result stores the result of the run.
+	result Result
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options CheckOptions) withDefaults() CheckOptions {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	return options
+}
+
+// New creates a [Health] with no registered checks.
+func New() *Health {
+	return &Health{
+		checks: make(map[string]*registeredCheck),
+	}
+}
+
+// Register registers a critical readiness check with the given name
+// and the default options. A check with the same name is replaced.
+func (health *Health) Register(name string, check Check) *Health {
+	return health.RegisterWith(name, check, CheckOptions{})
+}
+
+// RegisterWith registers a check with the given name and options.
+// A check with the same name is replaced.
+func (health *Health) RegisterWith(name string, check Check, options CheckOptions) *Health {
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+
+	health.checks[name] = &registeredCheck{
+		check:   check,
+		options: options.withDefaults(),
+	}
+
+	return health
+}
+
+// Drain makes the readiness report fail from now on, while liveness
+// keeps reporting the checks as usual. It is meant to be called when
+// the graceful shutdown starts, so that load balancers stop routing
+// requests before the connections drain:
+//
+//	app.OnShutdown(checks.Drain)
+func (health *Health) Drain() {
+	health.draining.Store(true)
+}
+
+// Draining reports whether [Health.Drain] was called.
+func (health *Health) Draining() bool {
+	return health.draining.Load()
+}
+
+// Liveness runs the checks registered with [CheckOptions.Liveness]
+// and returns their aggregated report.
+func (health *Health) Liveness(ctx context.Context) Report {
+	return health.run(ctx, func(check *registeredCheck) bool {
+		return check.options.Liveness
+	})
+}
+
+// Readiness runs all the registered checks and returns their
+// aggregated report. Once [Health.Drain] was called, the report
+// fails without running them.
+func (health *Health) Readiness(ctx context.Context) Report {
+	if health.Draining() {
+		return Report{
+			Status: StatusFail,
+			Checks: map[string][]Result{
+				"shutdown": {{
+					Status:       StatusFail,
+					Output:       ErrDraining.Error(),
+					ObservedUnit: "ms",
+					Time:         time.Now(),
+				}},
+			},
+		}
+	}
+
+	return health.run(ctx, func(check *registeredCheck) bool {
+		return true
+	})
+}
+
+// run concurrently runs the registered checks the filter selects
+// and aggregates their results.
+func (health *Health) run(ctx context.Context, filter func(check *registeredCheck) bool) Report {
+	health.mutex.RLock()
+	checks := maps.Clone(health.checks)
+	health.mutex.RUnlock()
+
+	var mutex sync.Mutex
+	var group sync.WaitGroup
+
+	report := Report{
+		Status: StatusPass,
+		Checks: make(map[string][]Result),
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(checks)) {
+		check := checks[name]
+
+		if !filter(check) {
+			continue
+		}
+
+		group.Go(func() {
+			result := check.result(ctx)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			report.Checks[name] = []Result{result}
+			report.Status = worst(report.Status, result.Status)
+		})
+	}
+
+	group.Wait()
+
+	return report
+}
+
+// result returns the cached result of the check when still fresh,
+// or runs the check otherwise. Concurrent callers share the run in
+// progress instead of running the check again, and the lock is never
+// held while the check runs, so a slow check doesn't queue them.
+//
+// The shared run is detached from the cancellation of the caller that
+// started it, so a caller that goes away doesn't fail the others.
+// Callers whose context is done stop waiting and get a failed result
+// of their own, which is never cached.
+func (check *registeredCheck) result(ctx context.Context) Result {
+	check.mutex.Lock()
+
+	if check.options.CacheTTL > 0 && time.Now().Before(check.expires) {
+		defer check.mutex.Unlock()
+
+		return check.cached
+	}
+
+	pending := check.pending
+
+	if pending == nil {
+		pending = &pendingRun{done: make(chan struct{})}
+		check.pending = pending
+
+		go check.share(context.WithoutCancel(ctx), pending)
+	}
+
+	check.mutex.Unlock()
+
+	start := time.Now()
+
+	select {
+	case <-pending.done:
+		return pending.result
+	case <-ctx.Done():
+		return check.resultOf(start, ctx.Err())
+	}
+}
+
+// share runs the check for the given pending run, caching the result
+// when caching is enabled, and releases the callers waiting for it.
+func (check *registeredCheck) share(ctx context.Context, pending *pendingRun) {
+	pending.result = check.run(ctx)
+
+	check.mutex.Lock()
+	check.pending = nil
+
+	if check.options.CacheTTL > 0 {
+		check.cached = pending.result
+		check.expires = pending.result.Time.Add(check.options.CacheTTL)
+	}
+
+	check.mutex.Unlock()
+	close(pending.done)
+}
+
+// run runs the check within its timeout and returns its result.
+func (check *registeredCheck) run(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, check.options.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check.check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ErrTimeout
+	}
+
+	return check.resultOf(start, err)
+}
+
+// resultOf returns the result of a run of the check that started
+// at the given time and completed with the given error.
+func (check *registeredCheck) resultOf(start time.Time, err error) Result {
+	result := Result{
+		Status:        StatusPass,
+		ObservedValue: time.Since(start).Milliseconds(),
+		ObservedUnit:  "ms",
+		Time:          time.Now(),
+	}
+
+	if err != nil {
+		result.Status = StatusFail
+		result.Output = err.Error()
+
+		if check.options.Optional {
+			result.Status = StatusWarn
+		}
+	}
+
+	return result
+}
+
+// worst returns the most severe of the given statuses.
+func worst(current Status, status Status) Status {
+	if current == StatusFail || status == StatusFail {
+		return StatusFail
+	}
+
+	if current == StatusWarn || status == StatusWarn {
+		return StatusWarn
+	}
+
+	return StatusPass
+}
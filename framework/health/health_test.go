@@ -0,0 +1,198 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/health"
+
+	"github.com/stretchr/testify/require"
+)
+
+func passing(ctx context.Context) error {
+	return nil
+}
+
+func failing(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestReadinessPassesWithoutChecks(t *testing.T) {
+	t.Parallel()
+
+	report := health.New().Readiness(t.Context())
+
+	require.Equal(t, health.StatusPass, report.Status)
+}
+
+func TestReadinessFailsOnCriticalCheck(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().
+		Register("cache", passing).
+		Register("database", failing)
+
+	report := checks.Readiness(t.Context())
+
+	require.Equal(t, health.StatusFail, report.Status)
+	require.Equal(t, health.StatusPass, report.Checks["cache"][0].Status)
+	require.Equal(t, health.StatusFail, report.Checks["database"][0].Status)
+	require.Equal(t, "connection refused", report.Checks["database"][0].Output)
+}
+
+func TestReadinessWarnsOnOptionalCheck(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().RegisterWith("cache", failing, health.CheckOptions{Optional: true})
+	report := checks.Readiness(t.Context())
+
+	require.Equal(t, health.StatusWarn, report.Status)
+	require.Equal(t, health.StatusWarn, report.Checks["cache"][0].Status)
+}
+
+func TestCheckTimesOut(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().RegisterWith("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+
+		return nil
+	}, health.CheckOptions{Timeout: 10 * time.Millisecond})
+
+	report := checks.Readiness(t.Context())
+
+	require.Equal(t, health.StatusFail, report.Status)
+	require.Equal(t, health.ErrTimeout.Error(), report.Checks["slow"][0].Output)
+}
+
+func TestCheckResultIsCached(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+
+	checks := health.New().RegisterWith("database", func(ctx context.Context) error {
+		calls.Add(1)
+
+		return nil
+	}, health.CheckOptions{CacheTTL: time.Minute})
+
+	checks.Readiness(t.Context())
+	checks.Readiness(t.Context())
+
+	require.Equal(t, int64(1), calls.Load())
+}
+
+func TestConcurrentChecksShareTheRunInProgress(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	checks := health.New().Register("slow", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+
+		<-release
+
+		return nil
+	})
+
+	reports := make(chan health.Report, 3)
+
+	for range 3 {
+		go func() {
+			reports <- checks.Readiness(t.Context())
+		}()
+	}
+
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	for range 3 {
+		require.Equal(t, health.StatusPass, (<-reports).Status)
+	}
+
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestCancelledCallersDoNotFailTheSharedRun(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	checks := health.New().RegisterWith("slow", func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, health.CheckOptions{CacheTTL: time.Hour})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	first := make(chan health.Report, 1)
+	second := make(chan health.Report, 1)
+
+	go func() {
+		first <- checks.Readiness(ctx)
+	}()
+
+	<-started
+
+	go func() {
+		second <- checks.Readiness(t.Context())
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	cancelled := <-first
+
+	require.Equal(t, health.StatusFail, cancelled.Status)
+	require.Equal(t, context.Canceled.Error(), cancelled.Checks["slow"][0].Output)
+
+	close(release)
+
+	require.Equal(t, health.StatusPass, (<-second).Status)
+	require.Equal(t, health.StatusPass, checks.Readiness(t.Context()).Status)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestLivenessOnlyRunsLivenessChecks(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().
+		Register("database", failing).
+		RegisterWith("deadlock", passing, health.CheckOptions{Liveness: true})
+
+	report := checks.Liveness(t.Context())
+
+	require.Equal(t, health.StatusPass, report.Status)
+	require.Contains(t, report.Checks, "deadlock")
+	require.NotContains(t, report.Checks, "database")
+}
+
+func TestDrainFailsReadinessButNotLiveness(t *testing.T) {
+	t.Parallel()
+
+	checks := health.New().Register("database", passing)
+	checks.Drain()
+
+	require.True(t, checks.Draining())
+	require.Equal(t, health.StatusFail, checks.Readiness(t.Context()).Status)
+	require.Equal(t, health.StatusPass, checks.Liveness(t.Context()).Status)
+}
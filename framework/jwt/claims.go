@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Claims are the registered claims of RFC 7519. Application
+// claims are usually structs embedding them:
+//
+//	type UserClaims struct {
+//	    jwt.Claims
+//	    Role string `json:"role"`
+//	}
+type Claims struct {
+	// Issuer identifies who issued the token ("iss").
+	Issuer string `json:"iss,omitempty"`
+
+	// Subject identifies the principal of the token ("sub").
+	Subject string `json:"sub,omitempty"`
+
+	// Audience identifies who the token is intended for ("aud").
+	Audience Audience `json:"aud,omitempty"`
+
+	// ExpiresAt is when the token expires ("exp").
+	ExpiresAt NumericDate `json:"exp,omitzero"`
+
+	// NotBefore is when the token starts being valid ("nbf").
+	NotBefore NumericDate `json:"nbf,omitzero"`
+
+	// IssuedAt is when the token was issued ("iat").
+	IssuedAt NumericDate `json:"iat,omitzero"`
+
+	// ID uniquely identifies the token ("jti"),
+	// such as to revoke it.
+	ID string `json:"jti,omitempty"`
+}
+
+// NewClaims returns the claims of a token of the given subject issued
+// now and expiring after the given duration, with a random ID.
+func NewClaims(subject string, ttl time.Duration) Claims {
+	now := time.Now()
+
+	return Claims{
+		Subject:   subject,
+		ExpiresAt: NewNumericDate(now.Add(ttl)),
+		IssuedAt:  NewNumericDate(now),
+		ID:        rand.Text(),
+	}
+}
+
+// NumericDate is a time encoded as the number of seconds since the
+// Unix epoch, as used by the time claims. It's truncated to seconds.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns the given time truncated to seconds.
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate{Time: t.Truncate(time.Second)}
+}
+
+// MarshalJSON encodes the date as a number of seconds.
+func (date NumericDate) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, date.Unix(), 10), nil
+}
+
+// UnmarshalJSON decodes the date from a number of seconds,
+// which may have a fractional part.
+func (date *NumericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+
+	whole, fraction := math.Modf(seconds)
+	date.Time = time.Unix(int64(whole), int64(fraction*1e9))
+
+	return nil
+}
+
+// Audience is the "aud" claim, which is either a
+// single string or an array of strings.
+type Audience []string
+
+// Contains reports whether the audience contains the given one.
+func (audience Audience) Contains(value string) bool {
+	return slices.Contains(audience, value)
+}
+
+// MarshalJSON encodes audiences of a single value as
+// a string and the others as an array.
+func (audience Audience) MarshalJSON() ([]byte, error) {
+	if len(audience) == 1 {
+		return json.Marshal(audience[0])
+	}
+
+	return json.Marshal([]string(audience))
+}
+
+// UnmarshalJSON decodes the audience from a string or an array.
+func (audience *Audience) UnmarshalJSON(data []byte) error {
+	var value string
+
+	if err := json.Unmarshal(data, &value); err == nil {
+		*audience = Audience{value}
+
+		return nil
+	}
+
+	var values []string
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*audience = values
+
+	return nil
+}
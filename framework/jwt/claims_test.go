@@ -0,0 +1,67 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/jwt"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClaimsSetsTimesAndID(t *testing.T) {
+	t.Parallel()
+
+	claims := jwt.NewClaims("42", time.Hour)
+
+	require.Equal(t, "42", claims.Subject)
+	require.NotEmpty(t, claims.ID)
+	require.Equal(t, time.Hour, claims.ExpiresAt.Sub(claims.IssuedAt.Time))
+	require.WithinDuration(t, time.Now(), claims.IssuedAt.Time, 2*time.Second)
+}
+
+func TestClaimsOmitEmptyValues(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(jwt.Claims{Issuer: "auth"})
+
+	require.NoError(t, err)
+	require.JSONEq(t, `{"iss":"auth"}`, string(data))
+}
+
+func TestNumericDateDecodesFractionalSeconds(t *testing.T) {
+	t.Parallel()
+
+	var date jwt.NumericDate
+
+	require.NoError(t, json.Unmarshal([]byte(`1700000000.5`), &date))
+	require.Equal(t, time.Unix(1700000000, 5e8), date.Time)
+
+	data, err := json.Marshal(date)
+
+	require.NoError(t, err)
+	require.Equal(t, `1700000000`, string(data))
+}
+
+func TestAudienceAcceptsStringsAndArrays(t *testing.T) {
+	t.Parallel()
+
+	var single, multiple jwt.Audience
+
+	require.NoError(t, json.Unmarshal([]byte(`"api"`), &single))
+	require.NoError(t, json.Unmarshal([]byte(`["api","admin"]`), &multiple))
+	require.Error(t, json.Unmarshal([]byte(`42`), &single))
+
+	require.Equal(t, jwt.Audience{"api"}, single)
+	require.True(t, multiple.Contains("admin"))
+	require.False(t, multiple.Contains("web"))
+
+	data, err := json.Marshal(single)
+	require.NoError(t, err)
+	require.Equal(t, `"api"`, string(data))
+
+	data, err = json.Marshal(multiple)
+	require.NoError(t, err)
+	require.Equal(t, `["api","admin"]`, string(data))
+}
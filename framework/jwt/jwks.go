@@ -0,0 +1,228 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+)
+
+// ContentType is the media type of the JSON Web Key Sets
+// served by [Handler].
+const ContentType = "application/jwk-set+json"
+
+// ErrSecretKey is returned when encoding an HMAC key as a
+// [JWK], as its secret must never be published.
+var ErrSecretKey = errors.New("secret keys can't be published")
+
+// JWK is a public JSON Web Key (RFC 7517).
+type JWK struct {
+	// KeyType is the key family, "OKP", "EC" or "RSA" ("kty").
+	KeyType string `json:"kty"`
+
+	// ID is the ID of the key ("kid").
+	ID string `json:"kid,omitempty"`
+
+	// Use is the intended use of the key, "sig" for signatures ("use").
+	Use string `json:"use,omitempty"`
+
+	// Algorithm is the algorithm the key is used with ("alg").
+	Algorithm Algorithm `json:"alg,omitempty"`
+
+	// Curve is the curve of OKP and EC keys ("crv").
+	Curve string `json:"crv,omitempty"`
+
+	// X is the public key of OKP keys or the X coordinate of EC keys.
+	X string `json:"x,omitempty"`
+
+	// Y is the Y coordinate of EC keys.
+	Y string `json:"y,omitempty"`
+
+	// N is the modulus of RSA keys.
+	N string `json:"n,omitempty"`
+
+	// E is the exponent of RSA keys.
+	E string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	// Keys are the keys of the set.
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key of the key as a [JWK], or [ErrSecretKey]
+// for HMAC keys.
+func (key *Key) JWK() (JWK, error) {
+	jwk := JWK{ID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+
+	switch public := key.public.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType, jwk.Curve = "OKP", "Ed25519"
+		jwk.X = encode(public)
+	case *ecdsa.PublicKey:
+		point, err := public.Bytes()
+
+		if err != nil {
+			return JWK{}, err
+		}
+
+		jwk.KeyType, jwk.Curve = "EC", "P-256"
+		jwk.X = encode(point[1:33])
+		jwk.Y = encode(point[33:])
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encode(public.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(public.E)).Bytes())
+	default:
+		return JWK{}, ErrSecretKey
+	}
+
+	return jwk, nil
+}
+
+// Key returns the verification key of the JWK. Its algorithm is
+// inferred from its type and curve when the "alg" member is missing.
+func (jwk JWK) Key() (*Key, error) {
+	algorithm := jwk.Algorithm
+
+	switch jwk.KeyType {
+	case "OKP":
+		x, err := decode(jwk.X)
+
+		if err != nil || jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("%w: invalid OKP key %q", ErrUnsupportedAlgorithm, jwk.ID)
+		}
+
+		return NewVerificationKey(jwk.ID, inferred(algorithm, EdDSA), ed25519.PublicKey(x))
+	case "EC":
+		x, errX := decode(jwk.X)
+		y, errY := decode(jwk.Y)
+
+		if errX != nil || errY != nil || jwk.Curve != "P-256" || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%w: invalid EC key %q", ErrUnsupportedAlgorithm, jwk.ID)
+		}
+
+		public, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewVerificationKey(jwk.ID, inferred(algorithm, ES256), public)
+	case "RSA":
+		n, errN := decode(jwk.N)
+		e, errE := decode(jwk.E)
+
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: invalid RSA key %q", ErrUnsupportedAlgorithm, jwk.ID)
+		}
+
+		public := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+
+		return NewVerificationKey(jwk.ID, inferred(algorithm, RS256), public)
+	default:
+		return nil, fmt.Errorf("%w: key type %q", ErrUnsupportedAlgorithm, jwk.KeyType)
+	}
+}
+
+// JWKS returns the public keys of the keyset as a [JWKS],
+// leaving HMAC keys out.
+func (keyset *Keyset) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	for _, key := range keyset.Keys() {
+		if jwk, err := key.JWK(); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// ParseJWKS parses the given JSON Web Key Set into a [Keyset] that
+// only verifies tokens. Keys that are not meant for signatures, or
+// whose type is not supported, are skipped as RFC 7517 requires, and
+// so are keys that are too weak.
+func ParseJWKS(data []byte) (*Keyset, error) {
+	var set JWKS
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keyset := NewKeyset(nil)
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.Key()
+
+		if errors.Is(err, ErrUnsupportedAlgorithm) || errors.Is(err, ErrWeakKey) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		keyset.Add(key)
+	}
+
+	return keyset, nil
+}
+
+// LoadJWKS reads the JSON Web Key Set of the given file into a
+// [Keyset] that only verifies tokens, see [ParseJWKS].
+func LoadJWKS(path string) (*Keyset, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
+
+// Handler returns a [framework.Handler] that serves the public keys
+// of the given keyset as a JSON Web Key Set, conventionally at
+// "/.well-known/jwks.json". Responses can be cached for five minutes,
+// so keys should be added that long before rotating to them.
+func Handler(keyset *Keyset) framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		data, err := json.Marshal(keyset.JWKS())
+
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
+		return response.Raw(w, http.StatusOK, data)
+	}
+}
+
+// inferred returns the given algorithm, or the
+// fallback one when it's empty.
+func inferred(algorithm Algorithm, fallback Algorithm) Algorithm {
+	if algorithm == "" {
+		return fallback
+	}
+
+	return algorithm
+}
@@ -0,0 +1,131 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/jwt"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireJWKRoundTripsPublicKey(t *testing.T, algorithm jwt.Algorithm) {
+	t.Helper()
+
+	key := newKey(t, "k1", algorithm)
+	jwk, err := key.JWK()
+	require.NoError(t, err)
+	require.Equal(t, "sig", jwk.Use)
+
+	jwk.Algorithm = ""
+	public, err := jwk.Key()
+	require.NoError(t, err)
+	require.Equal(t, algorithm, public.Algorithm)
+	require.False(t, public.CanSign())
+
+	token, err := jwt.Sign(key, jwt.NewClaims("42", time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, jwt.NewVerifier(jwt.NewKeyset(nil, public)).Verify(t.Context(), token, &jwt.Claims{}))
+}
+
+func TestJWKRoundTripsPublicKeysEdDSA(t *testing.T) {
+	t.Parallel()
+
+	requireJWKRoundTripsPublicKey(t, jwt.EdDSA)
+}
+
+func TestJWKRoundTripsPublicKeysES256(t *testing.T) {
+	t.Parallel()
+
+	requireJWKRoundTripsPublicKey(t, jwt.ES256)
+}
+
+func TestJWKRoundTripsPublicKeysRS256(t *testing.T) {
+	t.Parallel()
+
+	requireJWKRoundTripsPublicKey(t, jwt.RS256)
+}
+
+func TestJWKRejectsSecretKeys(t *testing.T) {
+	t.Parallel()
+
+	_, err := newKey(t, "k1", jwt.HS256).JWK()
+
+	require.ErrorIs(t, err, jwt.ErrSecretKey)
+}
+
+func TestParseJWKSSkipsUnsupportedKeys(t *testing.T) {
+	t.Parallel()
+
+	key := newKey(t, "k1", jwt.ES256)
+	jwk, err := key.JWK()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(map[string]any{
+		"keys": []any{
+			jwk,
+			map[string]any{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"},
+			map[string]any{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			map[string]any{"kty": "EC", "kid": "p384", "crv": "P-384", "x": "AA", "y": "AA"},
+		},
+	})
+	require.NoError(t, err)
+
+	keyset, err := jwt.ParseJWKS(data)
+	require.NoError(t, err)
+	require.Len(t, keyset.Keys(), 1)
+	require.Nil(t, keyset.Signing())
+
+	_, err = jwt.ParseJWKS([]byte(`{"keys":`))
+	require.Error(t, err)
+}
+
+func TestLoadJWKSReadsFiles(t *testing.T) {
+	t.Parallel()
+
+	key := newKey(t, "k1", jwt.EdDSA)
+	data, err := json.Marshal(jwt.NewKeyset(key).JWKS())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	keyset, err := jwt.LoadJWKS(path)
+	require.NoError(t, err)
+
+	public, err := keyset.Key(t.Context(), "k1")
+	require.NoError(t, err)
+	require.Equal(t, key.Public(), public.Public())
+
+	_, err = jwt.LoadJWKS(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestHandlerServesPublicKeys(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k2", jwt.EdDSA), newKey(t, "k1", jwt.ES256), newKey(t, "secret", jwt.HS256))
+
+	app := framework.New()
+	app.Get("/.well-known/jwks.json", jwt.Handler(keyset))
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, jwt.ContentType, rec.Header().Get("Content-Type"))
+	require.NotEmpty(t, rec.Header().Get("Cache-Control"))
+
+	var set jwt.JWKS
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, "k2", set.Keys[0].ID)
+	require.Equal(t, "k1", set.Keys[1].ID)
+	require.NotContains(t, rec.Body.String(), "secret")
+}
@@ -0,0 +1,223 @@
+// Package jwt issues and verifies JSON Web Tokens (RFC 7519) signed
+// with the HS256, HS384, HS512, EdDSA, ES256 and RS256 algorithms.
+//
+// Tokens are signed with the current key of a [Keyset] and verified by
+// a [Verifier] against any [KeySource], such as a [Keyset] holding the
+// previous keys during a rotation or a [RemoteKeyset] that fetches the
+// JSON Web Key Set of another service. Public keys are served as a JSON
+// Web Key Set with [Handler], and [Middleware] authenticates requests
+// with Bearer tokens, storing their claims in the request context.
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// Algorithm is a JWS signing algorithm, as registered in RFC 7518.
+type Algorithm string
+
+const (
+	// HS256 is HMAC using SHA-256.
+	HS256 Algorithm = "HS256"
+
+	// HS384 is HMAC using SHA-384.
+	HS384 Algorithm = "HS384"
+
+	// HS512 is HMAC using SHA-512.
+	HS512 Algorithm = "HS512"
+
+	// EdDSA is EdDSA using the Ed25519 curve (RFC 8037).
+	EdDSA Algorithm = "EdDSA"
+
+	// ES256 is ECDSA using the P-256 curve and SHA-256.
+	ES256 Algorithm = "ES256"
+
+	// RS256 is RSASSA-PKCS1-v1_5 using SHA-256.
+	RS256 Algorithm = "RS256"
+)
+
+// ErrUnsupportedAlgorithm is returned when creating a key for an
+// algorithm that is not supported, or with a key of the wrong type.
+var ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+
+// ErrKeyNotFound is returned by a [KeySource] when it
+// has no key with the requested ID.
+var ErrKeyNotFound = errors.New("key not found")
+
+// ErrTokenMissing is returned by [Middleware] when the request has no
+// Bearer token. It uses HTTP 401 Unauthorized.
+var ErrTokenMissing = problem.Problem{
+	Type:   "urn:cosmos:problem:token-missing",
+	Title:  "Token Missing",
+	Detail: "A Bearer token is required to access this resource.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenMalformed is returned when the token is not a well formed
+// JWS compact serialization. It uses HTTP 401 Unauthorized.
+var ErrTokenMalformed = problem.Problem{
+	Type:   "urn:cosmos:problem:token-malformed",
+	Title:  "Token Malformed",
+	Detail: "The token is not a well formed JSON Web Token.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenUnknownKey is returned when the key the token was signed
+// with is not known. It uses HTTP 401 Unauthorized.
+var ErrTokenUnknownKey = problem.Problem{
+	Type:   "urn:cosmos:problem:token-unknown-key",
+	Title:  "Token Key Unknown",
+	Detail: "The token was signed with an unknown key.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenAlgorithm is returned when the token algorithm does not
+// match the one of its key. It uses HTTP 401 Unauthorized.
+var ErrTokenAlgorithm = problem.Problem{
+	Type:   "urn:cosmos:problem:token-algorithm",
+	Title:  "Token Algorithm Invalid",
+	Detail: "The token was signed with an unexpected algorithm.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenSignature is returned when the token signature is
+// invalid. It uses HTTP 401 Unauthorized.
+var ErrTokenSignature = problem.Problem{
+	Type:   "urn:cosmos:problem:token-signature",
+	Title:  "Token Signature Invalid",
+	Detail: "The token signature is invalid.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenExpired is returned when the token expired, or has no
+// expiration time when one is required. It uses HTTP 401 Unauthorized.
+var ErrTokenExpired = problem.Problem{
+	Type:   "urn:cosmos:problem:token-expired",
+	Title:  "Token Expired",
+	Detail: "The token has expired.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenNotYetValid is returned when the token is used before its
+// "nbf" or "iat" time. It uses HTTP 401 Unauthorized.
+var ErrTokenNotYetValid = problem.Problem{
+	Type:   "urn:cosmos:problem:token-not-yet-valid",
+	Title:  "Token Not Yet Valid",
+	Detail: "The token is not valid yet.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenIssuer is returned when the token was not issued by the
+// expected issuer. It uses HTTP 401 Unauthorized.
+var ErrTokenIssuer = problem.Problem{
+	Type:   "urn:cosmos:problem:token-issuer",
+	Title:  "Token Issuer Invalid",
+	Detail: "The token was issued by an unexpected issuer.",
+	Status: http.StatusUnauthorized,
+}
+
+// ErrTokenAudience is returned when the token is not intended for the
+// expected audience. It uses HTTP 401 Unauthorized.
+var ErrTokenAudience = problem.Problem{
+	Type:   "urn:cosmos:problem:token-audience",
+	Title:  "Token Audience Invalid",
+	Detail: "The token is not intended for this audience.",
+	Status: http.StatusUnauthorized,
+}
+
+// header is the JOSE header of tokens.
+type header struct {
+	// Algorithm is the signing algorithm.
+	Algorithm Algorithm `json:"alg"`
+
+	// Type is the media type of the token, "JWT".
+	Type string `json:"typ,omitempty"`
+
+	// KeyID is the ID of the signing key.
+	KeyID string `json:"kid,omitempty"`
+}
+
+// Sign signs the given claims with the given key and returns the token
+// in the JWS compact serialization. Claims are encoded as JSON, usually
+// as a struct embedding [Claims]. Most applications sign with the
+// current key of a [Keyset] through [Keyset.Sign] instead.
+func Sign(key *Key, claims any) (string, error) {
+	header, err := json.Marshal(header{
+		Algorithm: key.Algorithm,
+		Type:      "JWT",
+		KeyID:     key.ID,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	input := encode(header) + "." + encode(payload)
+	signature, err := key.sign([]byte(input))
+
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + encode(signature), nil
+}
+
+// split splits a token in its decoded header and payload, and the
+// signing input and signature.
+func split(token string) (header, []byte, []byte, []byte, error) {
+	var head header
+
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return head, nil, nil, nil, ErrTokenMalformed.WithError(errors.New("token must have three parts"))
+	}
+
+	rawHeader, err := decode(parts[0])
+
+	if err != nil {
+		return head, nil, nil, nil, ErrTokenMalformed.WithError(err)
+	}
+
+	if err := json.Unmarshal(rawHeader, &head); err != nil {
+		return head, nil, nil, nil, ErrTokenMalformed.WithError(err)
+	}
+
+	payload, err := decode(parts[1])
+
+	if err != nil {
+		return head, nil, nil, nil, ErrTokenMalformed.WithError(err)
+	}
+
+	signature, err := decode(parts[2])
+
+	if err != nil {
+		return head, nil, nil, nil, ErrTokenMalformed.WithError(err)
+	}
+
+	input := token[:len(parts[0])+1+len(parts[1])]
+
+	return head, payload, []byte(input), signature, nil
+}
+
+// encode encodes the given bytes with unpadded base64url.
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decode decodes the given unpadded base64url string.
+func decode(data string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(data)
+}
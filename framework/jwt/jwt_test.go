@@ -0,0 +1,59 @@
+package jwt_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/jwt"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignEncodesHeaderAndClaims(t *testing.T) {
+	t.Parallel()
+
+	key, err := jwt.GenerateKey("k1", jwt.HS256)
+	require.NoError(t, err)
+
+	token, err := jwt.Sign(key, jwt.Claims{Subject: "42", ExpiresAt: jwt.NewNumericDate(time.Unix(1700000000, 0))})
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"alg":"HS256","typ":"JWT","kid":"k1"}`, string(header))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"sub":"42","exp":1700000000}`, string(payload))
+}
+
+func TestSignRejectsVerificationKeys(t *testing.T) {
+	t.Parallel()
+
+	key, err := jwt.GenerateKey("k1", jwt.EdDSA)
+	require.NoError(t, err)
+
+	public, err := jwt.NewVerificationKey("k1", jwt.EdDSA, key.Public())
+	require.NoError(t, err)
+
+	_, err = jwt.Sign(public, jwt.Claims{})
+	require.ErrorIs(t, err, jwt.ErrVerificationOnly)
+}
+
+func TestSignFailsForUnencodableClaims(t *testing.T) {
+	t.Parallel()
+
+	key, err := jwt.GenerateKey("k1", jwt.HS256)
+	require.NoError(t, err)
+
+	_, err = jwt.Sign(key, map[string]any{"bad": make(chan int)})
+
+	var unsupported *json.UnsupportedTypeError
+	require.ErrorAs(t, err, &unsupported)
+}
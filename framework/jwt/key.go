@@ -0,0 +1,240 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+// ErrWeakKey is returned when creating a key that is too short
+// for its algorithm.
+var ErrWeakKey = errors.New("key too short for algorithm")
+
+// ErrVerificationOnly is returned when signing with a key
+// that only holds a public key.
+var ErrVerificationOnly = errors.New("key can only verify")
+
+// Key is a signing or verification key of an [Algorithm], identified
+// by its ID, which is the "kid" of the tokens it signs.
+type Key struct {
+	// ID identifies the key in a [Keyset] and in the
+	// header of the tokens it signs.
+	ID string
+
+	// Algorithm is the only algorithm the key is used with,
+	// so that tokens can't pick a weaker one.
+	Algorithm Algorithm
+
+	// secret stores the secret of HMAC keys.
+	secret []byte
+
+	// private stores the private key of asymmetric keys,
+	// nil for verification keys.
+	private crypto.Signer
+
+	// public stores the public key of asymmetric keys.
+	public crypto.PublicKey
+}
+
+// NewHMACKey creates a key for the HS256, HS384 or HS512 algorithms
+// with the given secret, which must be at least as long as the output
+// of the hash function, as required by RFC 7518. HMAC keys both sign
+// and verify tokens, so they are never published by [Keyset.JWKS].
+func NewHMACKey(id string, algorithm Algorithm, secret []byte) (*Key, error) {
+	function, ok := hashes[algorithm]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s with a secret", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	if size := function().Size(); len(secret) < size {
+		return nil, fmt.Errorf("%w: %s requires %d bytes", ErrWeakKey, algorithm, size)
+	}
+
+	return &Key{ID: id, Algorithm: algorithm, secret: secret}, nil
+}
+
+// NewSigningKey creates a key for the given asymmetric algorithm with
+// the given private key, such as an [ed25519.PrivateKey] for [EdDSA],
+// an [*ecdsa.PrivateKey] on the P-256 curve for [ES256] or an
+// [*rsa.PrivateKey] of at least 2048 bits for [RS256]. Any signer with
+// such a public key works, including ones backed by a KMS.
+func NewSigningKey(id string, algorithm Algorithm, private crypto.Signer) (*Key, error) {
+	key, err := NewVerificationKey(id, algorithm, private.Public())
+
+	if err != nil {
+		return nil, err
+	}
+
+	key.private = private
+
+	return key, nil
+}
+
+// NewVerificationKey creates a key that only verifies the tokens of the
+// given asymmetric algorithm with the given public key, see
+// [NewSigningKey] for the key types of each algorithm.
+func NewVerificationKey(id string, algorithm Algorithm, public crypto.PublicKey) (*Key, error) {
+	valid := false
+
+	switch public := public.(type) {
+	case ed25519.PublicKey:
+		valid = algorithm == EdDSA && len(public) == ed25519.PublicKeySize
+	case *ecdsa.PublicKey:
+		valid = algorithm == ES256 && public.Curve == elliptic.P256()
+	case *rsa.PublicKey:
+		if algorithm == RS256 && public.Size() < 256 {
+			return nil, fmt.Errorf("%w: %s requires 2048 bits", ErrWeakKey, algorithm)
+		}
+
+		valid = algorithm == RS256
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("%w: %s with a %T", ErrUnsupportedAlgorithm, algorithm, public)
+	}
+
+	return &Key{ID: id, Algorithm: algorithm, public: public}, nil
+}
+
+// GenerateKey generates a random key of the given algorithm, such as
+// the next key of a rotation. HMAC secrets are as long as the output of
+// their hash function and RSA keys have 2048 bits.
+func GenerateKey(id string, algorithm Algorithm) (*Key, error) {
+	if isHMAC(algorithm) {
+		secret := make([]byte, hashes[algorithm]().Size())
+		_, _ = rand.Read(secret)
+
+		return NewHMACKey(id, algorithm, secret)
+	}
+
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case ES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigningKey(id, algorithm, private)
+}
+
+// Public returns the public key of asymmetric keys,
+// or nil for HMAC keys.
+func (key *Key) Public() crypto.PublicKey {
+	return key.public
+}
+
+// CanSign reports whether the key can sign tokens.
+func (key *Key) CanSign() bool {
+	return key.secret != nil || key.private != nil
+}
+
+// sign returns the signature of the given input.
+func (key *Key) sign(input []byte) ([]byte, error) {
+	if isHMAC(key.Algorithm) {
+		return key.mac(input), nil
+	}
+
+	if key.private == nil {
+		return nil, ErrVerificationOnly
+	}
+
+	if key.Algorithm == EdDSA {
+		return key.private.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(input)
+	signature, err := key.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+
+	if err != nil || key.Algorithm != ES256 {
+		return signature, err
+	}
+
+	// Signers return ASN.1 encoded ECDSA signatures, while JWS
+	// uses the fixed-size concatenation of R and S.
+	var values struct {
+		R, S *big.Int
+	}
+
+	if _, err := asn1.Unmarshal(signature, &values); err != nil {
+		return nil, err
+	}
+
+	signature = make([]byte, 64)
+	values.R.FillBytes(signature[:32])
+	values.S.FillBytes(signature[32:])
+
+	return signature, nil
+}
+
+// verify reports whether the given signature of the input is valid.
+func (key *Key) verify(input []byte, signature []byte) bool {
+	if isHMAC(key.Algorithm) {
+		return hmac.Equal(key.mac(input), signature)
+	}
+
+	switch public := key.public.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(public, input, signature)
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return false
+		}
+
+		digest := sha256.Sum256(input)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(public, digest[:], r, s)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(input)
+
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// mac returns the HMAC of the given input.
+func (key *Key) mac(input []byte) []byte {
+	mac := hmac.New(hashes[key.Algorithm], key.secret)
+	mac.Write(input)
+
+	return mac.Sum(nil)
+}
+
+// hashes maps the HMAC algorithms to their hash functions.
+var hashes = map[Algorithm]func() hash.Hash{
+	HS256: sha256.New,
+	HS384: sha512.New384,
+	HS512: sha512.New,
+}
+
+// isHMAC reports whether the algorithm is an HMAC one.
+func isHMAC(algorithm Algorithm) bool {
+	_, ok := hashes[algorithm]
+
+	return ok
+}
@@ -0,0 +1,116 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/jwt"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireGeneratedKeySignsAndVerifies(t *testing.T, algorithm jwt.Algorithm) {
+	t.Helper()
+
+	key, err := jwt.GenerateKey("k1", algorithm)
+	require.NoError(t, err)
+	require.True(t, key.CanSign())
+
+	token, err := jwt.Sign(key, jwt.NewClaims("42", time.Minute))
+	require.NoError(t, err)
+
+	claims, err := jwt.Verify[jwt.Claims](t.Context(), jwt.NewVerifier(jwt.NewKeyset(key)), token)
+	require.NoError(t, err)
+	require.Equal(t, "42", claims.Subject)
+}
+
+func TestGeneratedKeySignsAndVerifiesHS256(t *testing.T) {
+	t.Parallel()
+
+	requireGeneratedKeySignsAndVerifies(t, jwt.HS256)
+}
+
+func TestGeneratedKeySignsAndVerifiesHS384(t *testing.T) {
+	t.Parallel()
+
+	requireGeneratedKeySignsAndVerifies(t, jwt.HS384)
+}
+
+func TestGeneratedKeySignsAndVerifiesHS512(t *testing.T) {
+	t.Parallel()
+
+	requireGeneratedKeySignsAndVerifies(t, jwt.HS512)
+}
+
+func TestGeneratedKeySignsAndVerifiesEdDSA(t *testing.T) {
+	t.Parallel()
+
+	requireGeneratedKeySignsAndVerifies(t, jwt.EdDSA)
+}
+
+func TestGeneratedKeySignsAndVerifiesES256(t *testing.T) {
+	t.Parallel()
+
+	requireGeneratedKeySignsAndVerifies(t, jwt.ES256)
+}
+
+func TestGeneratedKeySignsAndVerifiesRS256(t *testing.T) {
+	t.Parallel()
+
+	requireGeneratedKeySignsAndVerifies(t, jwt.RS256)
+}
+
+func TestGenerateKeyRejectsUnknownAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	_, err := jwt.GenerateKey("k1", "none")
+
+	require.ErrorIs(t, err, jwt.ErrUnsupportedAlgorithm)
+}
+
+func TestNewHMACKeyRejectsShortSecrets(t *testing.T) {
+	t.Parallel()
+
+	_, err := jwt.NewHMACKey("k1", jwt.HS256, []byte("short"))
+	require.ErrorIs(t, err, jwt.ErrWeakKey)
+
+	_, err = jwt.NewHMACKey("k1", jwt.HS512, make([]byte, 32))
+	require.ErrorIs(t, err, jwt.ErrWeakKey)
+
+	_, err = jwt.NewHMACKey("k1", jwt.RS256, make([]byte, 64))
+	require.ErrorIs(t, err, jwt.ErrUnsupportedAlgorithm)
+
+	key, err := jwt.NewHMACKey("k1", jwt.HS256, make([]byte, 32))
+	require.NoError(t, err)
+	require.Nil(t, key.Public())
+}
+
+func TestNewSigningKeyChecksKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = jwt.NewSigningKey("k1", jwt.EdDSA, ed)
+	require.NoError(t, err)
+
+	_, err = jwt.NewSigningKey("k1", jwt.ES256, ed)
+	require.ErrorIs(t, err, jwt.ErrUnsupportedAlgorithm)
+
+	_, err = jwt.NewSigningKey("k1", jwt.ES256, p384)
+	require.ErrorIs(t, err, jwt.ErrUnsupportedAlgorithm)
+
+	_, err = jwt.NewSigningKey("k1", jwt.RS256, weak)
+	require.ErrorIs(t, err, jwt.ErrWeakKey)
+}
@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrNoSigningKey is returned when signing with
+// a [Keyset] that has no signing key.
+var ErrNoSigningKey = errors.New("no signing key")
+
+// KeySource finds the keys tokens are verified with,
+// such as a [Keyset] or a [RemoteKeyset].
+type KeySource interface {
+	// Key returns the key of the given ID, or an error
+	// wrapping [ErrKeyNotFound] when there's none.
+	Key(ctx context.Context, id string) (*Key, error)
+}
+
+// Keyset holds the keys of an issuer: the current key, which signs new
+// tokens, and the previous ones, which still verify the tokens they
+// signed until those expire. It's safe for concurrent use.
+//
+// Rotating keys without rejecting valid tokens takes three steps:
+// [Keyset.Add] the next key so that it's published by [Handler] before
+// it's used, [Keyset.Rotate] to it once verifiers had time to refresh
+// their key sets, and [Keyset.Remove] the previous key once the tokens
+// it signed have expired.
+type Keyset struct {
+	// mutex guards the fields below.
+	mutex sync.RWMutex
+
+	// keys stores the keys in insertion order.
+	keys []*Key
+
+	// current stores the signing key.
+	current *Key
+}
+
+// NewKeyset creates a [Keyset] that signs tokens with the given key
+// and verifies them with it and the given previous keys. A nil signing
+// key creates a keyset that only verifies tokens.
+func NewKeyset(signing *Key, previous ...*Key) *Keyset {
+	keyset := &Keyset{}
+
+	if signing != nil {
+		keyset.Rotate(signing)
+	}
+
+	for _, key := range previous {
+		keyset.Add(key)
+	}
+
+	return keyset
+}
+
+// Key returns the key of the given ID.
+func (keyset *Keyset) Key(ctx context.Context, id string) (*Key, error) {
+	keyset.mutex.RLock()
+	defer keyset.mutex.RUnlock()
+
+	if i := keyset.index(id); i >= 0 {
+		return keyset.keys[i], nil
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// Keys returns the keys of the keyset.
+func (keyset *Keyset) Keys() []*Key {
+	keyset.mutex.RLock()
+	defer keyset.mutex.RUnlock()
+
+	return slices.Clone(keyset.keys)
+}
+
+// Signing returns the signing key, or nil when there's none.
+func (keyset *Keyset) Signing() *Key {
+	keyset.mutex.RLock()
+	defer keyset.mutex.RUnlock()
+
+	return keyset.current
+}
+
+// Add adds the given key to verify tokens with, replacing the
+// one with the same ID, if any, without signing with it.
+func (keyset *Keyset) Add(key *Key) {
+	keyset.mutex.Lock()
+	defer keyset.mutex.Unlock()
+
+	keyset.add(key)
+}
+
+// Rotate makes the given key the signing key, adding it when it's
+// not in the keyset. The previous signing key keeps verifying tokens
+// until it's removed.
+func (keyset *Keyset) Rotate(key *Key) {
+	keyset.mutex.Lock()
+	defer keyset.mutex.Unlock()
+
+	keyset.add(key)
+	keyset.current = key
+}
+
+// Remove removes the key of the given ID, so that the tokens it
+// signed are rejected. Removing the signing key leaves the keyset
+// without one until the next rotation.
+func (keyset *Keyset) Remove(id string) {
+	keyset.mutex.Lock()
+	defer keyset.mutex.Unlock()
+
+	if i := keyset.index(id); i >= 0 {
+		keyset.keys = slices.Delete(keyset.keys, i, i+1)
+	}
+
+	if keyset.current != nil && keyset.current.ID == id {
+		keyset.current = nil
+	}
+}
+
+// Sign signs the given claims with the signing key, see [Sign].
+func (keyset *Keyset) Sign(claims any) (string, error) {
+	key := keyset.Signing()
+
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	return Sign(key, claims)
+}
+
+// add adds or replaces the given key. The mutex must be held.
+func (keyset *Keyset) add(key *Key) {
+	if i := keyset.index(key.ID); i >= 0 {
+		if keyset.current == keyset.keys[i] {
+			keyset.current = key
+		}
+
+		keyset.keys[i] = key
+
+		return
+	}
+
+	keyset.keys = append(keyset.keys, key)
+}
+
+// index returns the index of the key of the given ID, or -1.
+// The mutex must be held.
+func (keyset *Keyset) index(id string) int {
+	return slices.IndexFunc(keyset.keys, func(key *Key) bool {
+		return key.ID == id
+	})
+}
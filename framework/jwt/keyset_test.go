@@ -0,0 +1,84 @@
+package jwt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/jwt"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetRotationKeepsPreviousKeys(t *testing.T) {
+	t.Parallel()
+
+	first, err := jwt.GenerateKey("2026-01", jwt.EdDSA)
+	require.NoError(t, err)
+
+	second, err := jwt.GenerateKey("2026-02", jwt.EdDSA)
+	require.NoError(t, err)
+
+	keyset := jwt.NewKeyset(first)
+	verifier := jwt.NewVerifier(keyset)
+
+	old, err := keyset.Sign(jwt.NewClaims("42", time.Hour))
+	require.NoError(t, err)
+
+	keyset.Add(second)
+	require.Equal(t, first, keyset.Signing())
+
+	keyset.Rotate(second)
+	require.Equal(t, second, keyset.Signing())
+	require.Len(t, keyset.Keys(), 2)
+
+	current, err := keyset.Sign(jwt.NewClaims("42", time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, verifier.Verify(t.Context(), old, &jwt.Claims{}))
+	require.NoError(t, verifier.Verify(t.Context(), current, &jwt.Claims{}))
+
+	keyset.Remove(first.ID)
+
+	err = verifier.Verify(t.Context(), old, &jwt.Claims{})
+	require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+	require.NoError(t, verifier.Verify(t.Context(), current, &jwt.Claims{}))
+}
+
+func TestKeysetKeyReturnsKeyNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := jwt.NewKeyset(nil).Key(t.Context(), "missing")
+
+	require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+}
+
+func TestKeysetSignRequiresSigningKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := jwt.GenerateKey("k1", jwt.HS256)
+	require.NoError(t, err)
+
+	keyset := jwt.NewKeyset(key)
+	keyset.Remove(key.ID)
+
+	_, err = keyset.Sign(jwt.Claims{})
+
+	require.ErrorIs(t, err, jwt.ErrNoSigningKey)
+	require.Nil(t, keyset.Signing())
+}
+
+func TestKeysetAddReplacesKeysWithSameID(t *testing.T) {
+	t.Parallel()
+
+	first, err := jwt.GenerateKey("k1", jwt.HS256)
+	require.NoError(t, err)
+
+	replacement, err := jwt.GenerateKey("k1", jwt.HS256)
+	require.NoError(t, err)
+
+	keyset := jwt.NewKeyset(first)
+	keyset.Add(replacement)
+
+	require.Equal(t, []*jwt.Key{replacement}, keyset.Keys())
+	require.Equal(t, replacement, keyset.Signing())
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// MiddlewareOptions configures the Bearer token middleware.
+type MiddlewareOptions struct {
+	// Verifier verifies the tokens. It's required.
+	Verifier *Verifier
+
+	// Realm is the protection space of the WWW-Authenticate challenge.
+	Realm string
+
+	// Optional lets requests without a token through as guests,
+	// while requests with invalid tokens still fail.
+	Optional bool
+}
+
+// Middleware returns middleware that requires requests to carry a
+// Bearer token verified by the given verifier, see [MiddlewareWith].
+func Middleware[T any](verifier *Verifier) framework.Middleware {
+	return MiddlewareWith[T](MiddlewareOptions{Verifier: verifier})
+}
+
+// MiddlewareWith returns middleware that verifies the Bearer token of
+// requests and stores its claims of type T in the request context,
+// where [request.User] reads them from, as the token is the principal
+// of stateless requests.
+//
+// Requests without a token fail with [ErrTokenMissing], and requests
+// whose token is invalid with the problem returned by
+// [Verifier.Verify], both along with a Bearer WWW-Authenticate
+// challenge.
+//
+// Example usage:
+//
+//	type UserClaims struct {
+//	    jwt.Claims
+//	    Role string `json:"role"`
+//	}
+//
+//	app.Use(jwt.Middleware[UserClaims](verifier))
+//
+//	app.Get("/me", func(w http.ResponseWriter, r *http.Request) error {
+//	    claims, _ := request.User[UserClaims](r)
+//	    // ...
+//	})
+//
+// It panics if the verifier is nil, as it's a programming error.
+func MiddlewareWith[T any](options MiddlewareOptions) framework.Middleware {
+	if options.Verifier == nil {
+		panic("jwt: middleware requires a verifier")
+	}
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			token, ok := bearer(r)
+
+			if !ok && options.Optional {
+				return next(w, r)
+			}
+
+			if !ok {
+				w.Header().Set("WWW-Authenticate", middleware.BearerChallenge(options.Realm, middleware.ErrNoCredentials))
+
+				return ErrTokenMissing.WithError(middleware.ErrNoCredentials)
+			}
+
+			claims, err := Verify[T](r.Context(), options.Verifier, token)
+
+			if err != nil {
+				if errors.As(err, &problem.Problem{}) {
+					w.Header().Set("WWW-Authenticate", middleware.BearerChallenge(options.Realm, err))
+				}
+
+				return err
+			}
+
+			ctx := context.WithValue(r.Context(), contract.UserKey, claims)
+
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// bearer returns the Bearer token of the request, if any.
+func bearer(r *http.Request) (string, bool) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
@@ -0,0 +1,120 @@
+package jwt_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/jwt"
+	"github.com/studiolambda/cosmos/problem"
+
+	"github.com/stretchr/testify/require"
+)
+
+func jwtApp(options jwt.MiddlewareOptions) *framework.Router {
+	app := framework.New()
+	app.Use(jwt.MiddlewareWith[userClaims](options))
+	app.Get("/me", func(w http.ResponseWriter, r *http.Request) error {
+		claims, ok := request.User[userClaims](r)
+
+		if !ok {
+			return response.String(w, http.StatusOK, "guest")
+		}
+
+		return response.String(w, http.StatusOK, claims.Subject+":"+claims.Role)
+	})
+
+	return app
+}
+
+func jwtRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req
+}
+
+func TestMiddlewareStoresClaims(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	app := jwtApp(jwt.MiddlewareOptions{Verifier: jwt.NewVerifier(keyset)})
+
+	token, err := keyset.Sign(userClaims{Claims: jwt.NewClaims("42", time.Minute), Role: "admin"})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, jwtRequest(token))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "42:admin", rec.Body.String())
+}
+
+func TestMiddlewareRejectsMissingTokens(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	app := jwtApp(jwt.MiddlewareOptions{Verifier: jwt.NewVerifier(keyset), Realm: "api"})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, jwtRequest(""))
+
+	var body problem.Problem
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, `Bearer realm="api"`, rec.Header().Get("WWW-Authenticate"))
+	require.NoError(t, body.UnmarshalJSON(rec.Body.Bytes()))
+	require.Equal(t, jwt.ErrTokenMissing.Type, body.Type)
+}
+
+func TestMiddlewareRejectsInvalidTokensWithTheirProblem(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	app := jwtApp(jwt.MiddlewareOptions{Verifier: jwt.NewVerifier(keyset), Realm: "api", Optional: true})
+
+	claims := jwt.NewClaims("42", time.Minute)
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	token, err := keyset.Sign(userClaims{Claims: claims})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, jwtRequest(token))
+
+	var body problem.Problem
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, `Bearer realm="api", error="invalid_token"`, rec.Header().Get("WWW-Authenticate"))
+	require.NoError(t, body.UnmarshalJSON(rec.Body.Bytes()))
+	require.Equal(t, jwt.ErrTokenExpired.Type, body.Type)
+}
+
+func TestMiddlewareLetsGuestsThroughWhenOptional(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	app := jwtApp(jwt.MiddlewareOptions{Verifier: jwt.NewVerifier(keyset), Optional: true})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, jwtRequest(""))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "guest", rec.Body.String())
+}
+
+func TestMiddlewareWithPanicsWithoutVerifier(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		jwt.MiddlewareWith[userClaims](jwt.MiddlewareOptions{})
+	})
+}
@@ -0,0 +1,265 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RemoteKeysetOptions configures a [RemoteKeyset].
+type RemoteKeysetOptions struct {
+	// URL is the location of the JSON Web Key Set,
+	// such as "https://auth.example.com/.well-known/jwks.json".
+	URL string
+
+	// Client sends the requests. Defaults to a client
+	// with a 10 second timeout.
+	Client *http.Client
+
+	// RefreshInterval is how long fetched keys are used
+	// before fetching them again. Defaults to 1 hour.
+	RefreshInterval time.Duration
+
+	// MinRefreshInterval is the minimum time between fetches
+	// caused by tokens of unknown keys, which are usually signed
+	// by a key the issuer just rotated to, or by failed fetches.
+	// Defaults to 1 minute.
+	MinRefreshInterval time.Duration
+}
+
+// DefaultRemoteKeysetOptions holds sensible defaults
+// for a [RemoteKeyset].
+var DefaultRemoteKeysetOptions = RemoteKeysetOptions{
+	Client:             &http.Client{Timeout: 10 * time.Second},
+	RefreshInterval:    time.Hour,
+	MinRefreshInterval: time.Minute,
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultRemoteKeysetOptions] fields.
+func (options RemoteKeysetOptions) withDefaults() RemoteKeysetOptions {
+	if options.Client == nil {
+		options.Client = DefaultRemoteKeysetOptions.Client
+	}
+
+	if options.RefreshInterval == 0 {
+		options.RefreshInterval = DefaultRemoteKeysetOptions.RefreshInterval
+	}
+
+	if options.MinRefreshInterval == 0 {
+		options.MinRefreshInterval = DefaultRemoteKeysetOptions.MinRefreshInterval
+	}
+
+	return options
+}
+
+// RemoteKeyset is a [KeySource] that fetches the keys of another
+// issuer from its JSON Web Key Set URL. Keys are fetched on first use
+// and cached, and fetched again when a token refers to an unknown key,
+// so that rotations of the issuer are picked up. It's safe for
+// concurrent use.
+type RemoteKeyset struct {
+	// options stores the remote keyset options.
+	options RemoteKeysetOptions
+
+	// mutex guards the fields below. It's never held while
+	// fetching, so cached keys are served during a fetch.
+	mutex sync.RWMutex
+
+	// keyset stores the fetched keys, nil until the first fetch.
+	keyset *Keyset
+
+	// fetched stores when the keys were last fetched.
+	fetched time.Time
+
+	// attempted stores when the keys were last requested,
+	// whether the request succeeded or not.
+	attempted time.Time
+
+	// failure stores the error of the last request,
+	// nil when it succeeded.
+	failure error
+
+	// pending stores the fetch in progress, if any, whose
+	// result concurrent callers wait for.
+	pending *pendingFetch
+}
+
+// pendingFetch is a fetch of the keys in progress.
+type pendingFetch struct {
+	// done is closed once the fetch completed.
+	done chan struct{}
+
+	// err stores the error of the fetch.
+	err error
+}
+
+// NewRemoteKeyset creates a [RemoteKeyset] for the JSON Web Key Set
+// of the given URL using [DefaultRemoteKeysetOptions].
+func NewRemoteKeyset(url string) *RemoteKeyset {
+	options := DefaultRemoteKeysetOptions
+	options.URL = url
+
+	return NewRemoteKeysetWith(options)
+}
+
+// NewRemoteKeysetWith creates a [RemoteKeyset] with the given options.
+func NewRemoteKeysetWith(options RemoteKeysetOptions) *RemoteKeyset {
+	return &RemoteKeyset{options: options.withDefaults()}
+}
+
+// Key returns the key of the given ID, fetching the keys when they
+// were never fetched, are stale, or don't include it. Stale keys are
+// still used when fetching them fails, and fetches are retried at
+// most once per [RemoteKeysetOptions.MinRefreshInterval], including
+// the first one. Until the first fetch succeeds, the returned error
+// wraps both [ErrKeyNotFound] and the error of the last fetch.
+func (remote *RemoteKeyset) Key(ctx context.Context, id string) (*Key, error) {
+	keyset, fetched := remote.current()
+
+	if keyset == nil {
+		if _, err := remote.refresh(ctx, false); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+		}
+
+		if keyset, fetched = remote.current(); keyset == nil {
+			return nil, ErrKeyNotFound
+		}
+	}
+
+	if time.Since(fetched) >= remote.options.RefreshInterval {
+		if refreshed, err := remote.refresh(ctx, false); refreshed && err != nil {
+			slog.WarnContext(ctx, "failed to refresh key set", "url", remote.options.URL, "err", err)
+		}
+
+		keyset, _ = remote.current()
+	}
+
+	key, err := keyset.Key(ctx, id)
+
+	if err == nil {
+		return key, nil
+	}
+
+	refreshed, err := remote.refresh(ctx, false)
+
+	if !refreshed {
+		return nil, ErrKeyNotFound
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "failed to refresh key set", "url", remote.options.URL, "err", err)
+
+		return nil, ErrKeyNotFound
+	}
+
+	keyset, _ = remote.current()
+
+	return keyset.Key(ctx, id)
+}
+
+// Refresh fetches the keys, or waits for the fetch in progress.
+func (remote *RemoteKeyset) Refresh(ctx context.Context) error {
+	_, err := remote.refresh(ctx, true)
+
+	return err
+}
+
+// current returns the fetched keys and when they were fetched.
+func (remote *RemoteKeyset) current() (*Keyset, time.Time) {
+	remote.mutex.RLock()
+	defer remote.mutex.RUnlock()
+
+	return remote.keyset, remote.fetched
+}
+
+// refresh fetches the keys and reports whether it did, along with
+// the error of the fetch. Concurrent callers share the fetch in
+// progress, which is detached from the cancellation of the caller
+// that started it, while each caller stops waiting once its own
+// context is done. Unless forced, no fetch is made when the last
+// attempt is more recent than [RemoteKeysetOptions.MinRefreshInterval],
+// and the error of that attempt is reported instead.
+func (remote *RemoteKeyset) refresh(ctx context.Context, force bool) (bool, error) {
+	remote.mutex.Lock()
+
+	pending := remote.pending
+
+	if pending == nil {
+		if !force && time.Since(remote.attempted) < remote.options.MinRefreshInterval {
+			defer remote.mutex.Unlock()
+
+			return false, remote.failure
+		}
+
+		pending = &pendingFetch{done: make(chan struct{})}
+		remote.pending = pending
+		remote.attempted = time.Now()
+
+		go remote.share(context.WithoutCancel(ctx), pending)
+	}
+
+	remote.mutex.Unlock()
+
+	select {
+	case <-pending.done:
+		return true, pending.err
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+// share fetches the keys for the given pending fetch, stores them
+// and releases the callers waiting for them.
+func (remote *RemoteKeyset) share(ctx context.Context, pending *pendingFetch) {
+	keyset, err := remote.fetch(ctx)
+
+	remote.mutex.Lock()
+	remote.pending = nil
+	remote.failure = err
+
+	if err == nil {
+		remote.keyset = keyset
+		remote.fetched = time.Now()
+	}
+
+	remote.mutex.Unlock()
+
+	pending.err = err
+	close(pending.done)
+}
+
+// fetch fetches and parses the keys.
+func (remote *RemoteKeyset) fetch(ctx context.Context) (*Keyset, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, remote.options.URL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", ContentType+", application/json")
+
+	res, err := remote.options.Client.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching key set", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
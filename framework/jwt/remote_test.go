@@ -0,0 +1,203 @@
+package jwt_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/jwt"
+
+	"github.com/stretchr/testify/require"
+)
+
+func serveKeyset(t *testing.T, keyset *jwt.Keyset, fetches *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", jwt.ContentType)
+		_ = json.NewEncoder(w).Encode(keyset.JWKS())
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRemoteKeysetFetchesAndCachesKeys(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.EdDSA))
+	server := serveKeyset(t, keyset, &fetches)
+	verifier := jwt.NewVerifier(jwt.NewRemoteKeyset(server.URL))
+
+	token, err := keyset.Sign(jwt.NewClaims("42", time.Minute))
+	require.NoError(t, err)
+
+	require.NoError(t, verifier.Verify(t.Context(), token, &jwt.Claims{}))
+	require.NoError(t, verifier.Verify(t.Context(), token, &jwt.Claims{}))
+	require.Equal(t, int32(1), fetches.Load())
+}
+
+func TestRemoteKeysetRefetchesForUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.ES256))
+	server := serveKeyset(t, keyset, &fetches)
+	remote := jwt.NewRemoteKeysetWith(jwt.RemoteKeysetOptions{
+		URL:                server.URL,
+		MinRefreshInterval: time.Nanosecond,
+	})
+
+	_, err := remote.Key(t.Context(), "k1")
+	require.NoError(t, err)
+
+	keyset.Rotate(newKey(t, "k2", jwt.ES256))
+
+	key, err := remote.Key(t.Context(), "k2")
+	require.NoError(t, err)
+	require.Equal(t, "k2", key.ID)
+	require.Equal(t, int32(2), fetches.Load())
+
+	_, err = remote.Key(t.Context(), "k3")
+	require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+}
+
+func TestRemoteKeysetThrottlesRefetches(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+
+	server := serveKeyset(t, jwt.NewKeyset(newKey(t, "k1", jwt.ES256)), &fetches)
+	remote := jwt.NewRemoteKeyset(server.URL)
+
+	for range 3 {
+		_, err := remote.Key(t.Context(), "unknown")
+		require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+	}
+
+	require.Equal(t, int32(1), fetches.Load())
+}
+
+func TestRemoteKeysetReportsFetchFailures(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	t.Cleanup(server.Close)
+
+	remote := jwt.NewRemoteKeyset(server.URL)
+
+	_, err := remote.Key(t.Context(), "k1")
+	require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+	require.ErrorContains(t, err, "unexpected status 503")
+	require.Error(t, remote.Refresh(t.Context()))
+}
+
+func TestRemoteKeysetThrottlesInitialFetches(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	t.Cleanup(server.Close)
+
+	remote := jwt.NewRemoteKeyset(server.URL)
+
+	for range 3 {
+		_, err := remote.Key(t.Context(), "k1")
+		require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+		require.ErrorContains(t, err, "unexpected status 503")
+	}
+
+	require.Equal(t, int32(1), fetches.Load())
+}
+
+func TestRemoteKeysetServesCachedKeysDuringFetches(t *testing.T) {
+	t.Parallel()
+
+	var fetches atomic.Int32
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.ES256))
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			close(started)
+			<-release
+		}
+
+		w.Header().Set("Content-Type", jwt.ContentType)
+		_ = json.NewEncoder(w).Encode(keyset.JWKS())
+	}))
+
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		close(release)
+	})
+
+	remote := jwt.NewRemoteKeysetWith(jwt.RemoteKeysetOptions{
+		URL:                server.URL,
+		MinRefreshInterval: time.Nanosecond,
+	})
+
+	_, err := remote.Key(t.Context(), "k1")
+	require.NoError(t, err)
+
+	go remote.Key(context.WithoutCancel(t.Context()), "k2")
+
+	<-started
+
+	key, err := remote.Key(t.Context(), "k1")
+	require.NoError(t, err)
+	require.Equal(t, "k1", key.ID)
+}
+
+func TestRemoteKeysetStopsWaitingWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		close(release)
+	})
+
+	remote := jwt.NewRemoteKeyset(server.URL)
+	ctx, cancel := context.WithCancel(t.Context())
+	errs := make(chan error, 1)
+
+	go func() {
+		_, err := remote.Key(ctx, "k1")
+		errs <- err
+	}()
+
+	<-started
+	cancel()
+
+	err := <-errs
+	require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// VerifierOptions configures a [Verifier].
+type VerifierOptions struct {
+	// Keys finds the keys tokens are verified with. It's required.
+	Keys KeySource
+
+	// Issuer is the issuer tokens must have in their "iss"
+	// claim. Any issuer is accepted when empty.
+	Issuer string
+
+	// Audience is the audience tokens must have in their "aud"
+	// claim. Any audience is accepted when empty.
+	Audience string
+
+	// Leeway is the clock skew tolerated when validating the
+	// "exp", "nbf" and "iat" claims.
+	Leeway time.Duration
+
+	// OptionalExpiry accepts tokens without an "exp" claim,
+	// which never expire and are rejected otherwise.
+	OptionalExpiry bool
+
+	// Now returns the current time. Defaults to [time.Now].
+	Now func() time.Time
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options VerifierOptions) withDefaults() VerifierOptions {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	return options
+}
+
+// Verifier verifies the signature and the registered claims of tokens.
+// It's safe for concurrent use.
+type Verifier struct {
+	// options stores the verifier options.
+	options VerifierOptions
+}
+
+// NewVerifier creates a [Verifier] of the tokens signed with
+// the keys of the given source.
+func NewVerifier(keys KeySource) *Verifier {
+	return NewVerifierWith(VerifierOptions{Keys: keys})
+}
+
+// NewVerifierWith creates a [Verifier] with the given options.
+//
+// It panics if the key source is nil, as it's a programming error.
+func NewVerifierWith(options VerifierOptions) *Verifier {
+	if options.Keys == nil {
+		panic("jwt: verifier requires a key source")
+	}
+
+	return &Verifier{options: options.withDefaults()}
+}
+
+// Verify verifies the given token and decodes its claims into the
+// value pointed to by claims, usually a struct embedding [Claims].
+//
+// The token must be signed by the key of its "kid" header with the
+// algorithm of that key, and its registered claims must be valid.
+// Failures are reported as 401 problems of distinct types, such as
+// [ErrTokenExpired] or [ErrTokenSignature], wrapping the cause. Errors
+// of the key source other than [ErrKeyNotFound] are returned as is.
+func (verifier *Verifier) Verify(ctx context.Context, token string, claims any) error {
+	head, payload, input, signature, err := split(token)
+
+	if err != nil {
+		return err
+	}
+
+	key, err := verifier.options.Keys.Key(ctx, head.KeyID)
+
+	if errors.Is(err, ErrKeyNotFound) {
+		return ErrTokenUnknownKey.WithError(fmt.Errorf("%w: %q", err, head.KeyID))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Tokens can't pick the algorithm they are verified with, so that
+	// public keys are never used as HMAC secrets and "none" is never
+	// accepted.
+	if head.Algorithm != key.Algorithm {
+		return ErrTokenAlgorithm.WithError(fmt.Errorf("expected %s, got %q", key.Algorithm, head.Algorithm))
+	}
+
+	if !key.verify(input, signature) {
+		return ErrTokenSignature
+	}
+
+	var registered Claims
+
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return ErrTokenMalformed.WithError(err)
+	}
+
+	if err := verifier.validate(registered); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return ErrTokenMalformed.WithError(err)
+	}
+
+	return nil
+}
+
+// validate validates the registered claims.
+func (verifier *Verifier) validate(claims Claims) error {
+	now := verifier.options.Now()
+	leeway := verifier.options.Leeway
+
+	if claims.ExpiresAt.IsZero() && !verifier.options.OptionalExpiry {
+		return ErrTokenExpired.WithError(errors.New("missing expiration time"))
+	}
+
+	if !claims.ExpiresAt.IsZero() && !now.Before(claims.ExpiresAt.Add(leeway)) {
+		return ErrTokenExpired.WithError(fmt.Errorf("expired at %s", claims.ExpiresAt.Time))
+	}
+
+	if !claims.NotBefore.IsZero() && now.Add(leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotYetValid.WithError(fmt.Errorf("valid from %s", claims.NotBefore.Time))
+	}
+
+	if !claims.IssuedAt.IsZero() && now.Add(leeway).Before(claims.IssuedAt.Time) {
+		return ErrTokenNotYetValid.WithError(fmt.Errorf("issued in the future at %s", claims.IssuedAt.Time))
+	}
+
+	if verifier.options.Issuer != "" && claims.Issuer != verifier.options.Issuer {
+		return ErrTokenIssuer.WithError(fmt.Errorf("unexpected issuer %q", claims.Issuer))
+	}
+
+	if verifier.options.Audience != "" && !claims.Audience.Contains(verifier.options.Audience) {
+		return ErrTokenAudience.WithError(fmt.Errorf("unexpected audience %q", claims.Audience))
+	}
+
+	return nil
+}
+
+// Verify verifies the given token with the given verifier and returns
+// its claims of type T, see [Verifier.Verify].
+func Verify[T any](ctx context.Context, verifier *Verifier, token string) (T, error) {
+	var claims T
+
+	if err := verifier.Verify(ctx, token, &claims); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return claims, nil
+}
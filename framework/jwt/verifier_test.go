@@ -0,0 +1,246 @@
+package jwt_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/jwt"
+	"github.com/studiolambda/cosmos/problem"
+
+	"github.com/stretchr/testify/require"
+)
+
+type userClaims struct {
+	jwt.Claims
+	Role string `json:"role"`
+}
+
+func newKey(t *testing.T, id string, algorithm jwt.Algorithm) *jwt.Key {
+	t.Helper()
+
+	key, err := jwt.GenerateKey(id, algorithm)
+	require.NoError(t, err)
+
+	return key
+}
+
+func requireProblem(t *testing.T, err error, expected problem.Problem) {
+	t.Helper()
+
+	var actual problem.Problem
+
+	require.ErrorAs(t, err, &actual)
+	require.Equal(t, expected.Type, actual.Type)
+	require.Equal(t, expected.Status, actual.Status)
+}
+
+func TestVerifyDecodesTypedClaims(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.ES256))
+	token, err := keyset.Sign(userClaims{Claims: jwt.NewClaims("42", time.Minute), Role: "admin"})
+	require.NoError(t, err)
+
+	claims, err := jwt.Verify[userClaims](t.Context(), jwt.NewVerifier(keyset), token)
+
+	require.NoError(t, err)
+	require.Equal(t, "42", claims.Subject)
+	require.Equal(t, "admin", claims.Role)
+}
+
+// verifyTimesNow is the current time of the verifiers of verifyTimes.
+var verifyTimesNow = time.Unix(1700000000, 0)
+
+// verifyTimes signs the given claims and verifies them at
+// verifyTimesNow with a leeway of 30 seconds.
+func verifyTimes(t *testing.T, claims jwt.Claims) error {
+	t.Helper()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	verifier := jwt.NewVerifierWith(jwt.VerifierOptions{
+		Keys:   keyset,
+		Leeway: 30 * time.Second,
+		Now:    func() time.Time { return verifyTimesNow },
+	})
+
+	token, err := keyset.Sign(claims)
+	require.NoError(t, err)
+
+	return verifier.Verify(t.Context(), token, &jwt.Claims{})
+}
+
+func TestVerifyAcceptsUnexpiredTokens(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{ExpiresAt: jwt.NewNumericDate(verifyTimesNow.Add(time.Minute))})
+
+	require.NoError(t, err)
+}
+
+func TestVerifyAcceptsTokensExpiredWithinLeeway(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{ExpiresAt: jwt.NewNumericDate(verifyTimesNow.Add(-10 * time.Second))})
+
+	require.NoError(t, err)
+}
+
+func TestVerifyRejectsExpiredTokens(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{ExpiresAt: jwt.NewNumericDate(verifyTimesNow.Add(-time.Minute))})
+
+	requireProblem(t, err, jwt.ErrTokenExpired)
+}
+
+func TestVerifyRejectsTokensWithoutExpiry(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{})
+
+	requireProblem(t, err, jwt.ErrTokenExpired)
+}
+
+func TestVerifyRejectsTokensNotYetValid(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{
+		ExpiresAt: jwt.NewNumericDate(verifyTimesNow.Add(time.Hour)),
+		NotBefore: jwt.NewNumericDate(verifyTimesNow.Add(time.Minute)),
+	})
+
+	requireProblem(t, err, jwt.ErrTokenNotYetValid)
+}
+
+func TestVerifyAcceptsTokensNotYetValidWithinLeeway(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{
+		ExpiresAt: jwt.NewNumericDate(verifyTimesNow.Add(time.Hour)),
+		NotBefore: jwt.NewNumericDate(verifyTimesNow.Add(10 * time.Second)),
+	})
+
+	require.NoError(t, err)
+}
+
+func TestVerifyRejectsTokensIssuedInTheFuture(t *testing.T) {
+	t.Parallel()
+
+	err := verifyTimes(t, jwt.Claims{
+		ExpiresAt: jwt.NewNumericDate(verifyTimesNow.Add(time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(verifyTimesNow.Add(time.Minute)),
+	})
+
+	requireProblem(t, err, jwt.ErrTokenNotYetValid)
+}
+
+func TestVerifyAcceptsMissingExpiryWhenOptional(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	verifier := jwt.NewVerifierWith(jwt.VerifierOptions{Keys: keyset, OptionalExpiry: true})
+
+	token, err := keyset.Sign(jwt.Claims{Subject: "42"})
+	require.NoError(t, err)
+
+	require.NoError(t, verifier.Verify(t.Context(), token, &jwt.Claims{}))
+}
+
+func TestVerifyValidatesIssuerAndAudience(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.HS256))
+	verifier := jwt.NewVerifierWith(jwt.VerifierOptions{
+		Keys:     keyset,
+		Issuer:   "https://auth.example.com",
+		Audience: "api",
+	})
+
+	claims := jwt.NewClaims("42", time.Minute)
+	claims.Issuer = "https://auth.example.com"
+	claims.Audience = jwt.Audience{"web", "api"}
+
+	valid, err := keyset.Sign(claims)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(t.Context(), valid, &jwt.Claims{}))
+
+	claims.Issuer = "https://evil.example.com"
+	issuer, err := keyset.Sign(claims)
+	require.NoError(t, err)
+	requireProblem(t, verifier.Verify(t.Context(), issuer, &jwt.Claims{}), jwt.ErrTokenIssuer)
+
+	claims.Issuer = "https://auth.example.com"
+	claims.Audience = jwt.Audience{"web"}
+	audience, err := keyset.Sign(claims)
+	require.NoError(t, err)
+	requireProblem(t, verifier.Verify(t.Context(), audience, &jwt.Claims{}), jwt.ErrTokenAudience)
+}
+
+func TestVerifyRejectsTamperedTokens(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.EdDSA))
+	verifier := jwt.NewVerifier(keyset)
+
+	token, err := keyset.Sign(userClaims{Claims: jwt.NewClaims("42", time.Minute), Role: "user"})
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"42","role":"admin","exp":9999999999}`))
+	tampered := strings.Join(parts, ".")
+
+	requireProblem(t, verifier.Verify(t.Context(), tampered, &userClaims{}), jwt.ErrTokenSignature)
+}
+
+func TestVerifyRejectsAlgorithmSubstitution(t *testing.T) {
+	t.Parallel()
+
+	keyset := jwt.NewKeyset(newKey(t, "k1", jwt.RS256))
+	verifier := jwt.NewVerifier(keyset)
+
+	token, err := keyset.Sign(jwt.NewClaims("42", time.Minute))
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+
+	for _, algorithm := range []string{"none", "HS256"} {
+		parts[0] = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + algorithm + `","kid":"k1"}`))
+		forged := strings.Join(parts, ".")
+
+		requireProblem(t, verifier.Verify(t.Context(), forged, &jwt.Claims{}), jwt.ErrTokenAlgorithm)
+	}
+}
+
+func TestVerifyRejectsUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	token, err := jwt.Sign(newKey(t, "other", jwt.HS256), jwt.NewClaims("42", time.Minute))
+	require.NoError(t, err)
+
+	verifier := jwt.NewVerifier(jwt.NewKeyset(newKey(t, "k1", jwt.HS256)))
+	err = verifier.Verify(t.Context(), token, &jwt.Claims{})
+
+	requireProblem(t, err, jwt.ErrTokenUnknownKey)
+	require.ErrorIs(t, err, jwt.ErrKeyNotFound)
+}
+
+func TestVerifyRejectsMalformedTokens(t *testing.T) {
+	t.Parallel()
+
+	verifier := jwt.NewVerifier(jwt.NewKeyset(newKey(t, "k1", jwt.HS256)))
+	tokens := []string{"", "a.b", "a.b.c.d", "!.b.c", "e30.!.c", "e30.e30.!", "bm90LWpzb24.e30.e30"}
+
+	for _, token := range tokens {
+		requireProblem(t, verifier.Verify(t.Context(), token, &jwt.Claims{}), jwt.ErrTokenMalformed)
+	}
+}
+
+func TestNewVerifierWithPanicsWithoutKeys(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		jwt.NewVerifierWith(jwt.VerifierOptions{})
+	})
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// Cache wraps the given cache driver so that its reads are counted in
+// the given registry, labelled by the given cache name:
+//
+//   - cache_hits_total: a counter of the reads that found their key.
+//   - cache_misses_total: a counter of the reads that did not.
+//
+// Both Get and Has count as reads. The returned driver implements
+// [contract.CacheCounter] only when the given one does, so that
+// features that depend on it keep detecting its support.
+func Cache(registry *Registry, name string, driver contract.CacheDriver) contract.CacheDriver {
+	cache := &cacheDriver{
+		driver: driver,
+		name:   name,
+		hits: registry.Counter(
+			"cache_hits_total",
+			"Total number of cache reads that found their key.",
+			"cache",
+		),
+		misses: registry.Counter(
+			"cache_misses_total",
+			"Total number of cache reads that did not find their key.",
+			"cache",
+		),
+	}
+
+	if counter, ok := driver.(contract.CacheCounter); ok {
+		return &cacheCounterDriver{cacheDriver: cache, counter: counter}
+	}
+
+	return cache
+}
+
+// cacheDriver is a [contract.CacheDriver] that counts
+// the hits and misses of another one.
+type cacheDriver struct {
+	// driver stores the instrumented driver.
+	driver contract.CacheDriver
+
+	// name stores the cache label value.
+	name string
+
+	// hits counts the reads that found their key.
+	hits *Counter
+
+	// misses counts the reads that did not find their key.
+	misses *Counter
+}
+
+// Get retrieves the value of the given key, counting a hit when
+// it's found and a miss when it's not.
+func (cache *cacheDriver) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := cache.driver.Get(ctx, key)
+
+	switch {
+	case err == nil:
+		cache.hits.Inc(cache.name)
+	case errors.Is(err, contract.ErrCacheKeyNotFound):
+		cache.misses.Inc(cache.name)
+	}
+
+	return value, err
+}
+
+// Put stores the value of the given key.
+func (cache *cacheDriver) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return cache.driver.Put(ctx, key, value, ttl)
+}
+
+// Delete removes the given key.
+func (cache *cacheDriver) Delete(ctx context.Context, key string) error {
+	return cache.driver.Delete(ctx, key)
+}
+
+// Has reports whether the given key exists, counting a hit when
+// it does and a miss when it does not.
+func (cache *cacheDriver) Has(ctx context.Context, key string) (bool, error) {
+	exists, err := cache.driver.Has(ctx, key)
+
+	if err == nil {
+		if exists {
+			cache.hits.Inc(cache.name)
+		} else {
+			cache.misses.Inc(cache.name)
+		}
+	}
+
+	return exists, err
+}
+
+// cacheCounterDriver is a [cacheDriver] whose driver
+// implements [contract.CacheCounter].
+type cacheCounterDriver struct {
+	*cacheDriver
+
+	// counter stores the counter of the instrumented driver.
+	counter contract.CacheCounter
+}
+
+// Increment increments the counter of the given key.
+func (cache *cacheCounterDriver) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	return cache.counter.Increment(ctx, key, delta)
+}
+
+// Decrement decrements the counter of the given key.
+func (cache *cacheCounterDriver) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return cache.counter.Decrement(ctx, key, delta)
+}
@@ -0,0 +1,70 @@
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/framework/cache"
+	"github.com/studiolambda/cosmos/framework/metrics"
+
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheCountsHitsAndMisses(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	driver := metrics.Cache(registry, "default", cache.NewMemory(time.Minute, time.Minute))
+
+	require.NoError(t, driver.Put(t.Context(), "key", []byte("value"), time.Minute))
+
+	_, err := driver.Get(t.Context(), "key")
+	require.NoError(t, err)
+
+	_, err = driver.Get(t.Context(), "missing")
+	require.ErrorIs(t, err, contract.ErrCacheKeyNotFound)
+
+	exists, err := driver.Has(t.Context(), "missing")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.Equal(t, float64(1), registry.Counter("cache_hits_total", "", "cache").Value("default"))
+	require.Equal(t, float64(2), registry.Counter("cache_misses_total", "", "cache").Value("default"))
+}
+
+func TestCacheIgnoresFailedReads(t *testing.T) {
+	t.Parallel()
+
+	mocked := mock.NewCacheDriverMock(t)
+	mocked.On("Get", tmock.Anything, "key").Return(nil, errors.New("down")).Once()
+
+	registry := metrics.New()
+	driver := metrics.Cache(registry, "default", mocked)
+
+	_, err := driver.Get(t.Context(), "key")
+
+	require.EqualError(t, err, "down")
+	require.Equal(t, float64(0), registry.Counter("cache_misses_total", "", "cache").Value("default"))
+}
+
+func TestCacheKeepsCounterSupport(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	counting := metrics.Cache(registry, "memory", cache.NewMemory(time.Minute, time.Minute))
+	plain := metrics.Cache(registry, "mock", mock.NewCacheDriverMock(t))
+
+	require.Implements(t, (*contract.CacheCounter)(nil), counting)
+	require.NotImplements(t, (*contract.CacheCounter)(nil), plain)
+
+	require.NoError(t, counting.Put(t.Context(), "count", []byte("1"), time.Minute))
+
+	count, err := counting.(contract.CacheCounter).Increment(t.Context(), "count", 2)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// Database wraps the given database driver so that its queries are
+// measured in the given registry, labelled by the given database
+// name and the operation, one of "exec", "select", "find" or
+// "transaction":
+//
+//   - db_query_duration_seconds: a histogram of the time taken by
+//     queries, and by whole transactions, including the queries
+//     within them, which are measured on their own as well.
+//   - db_query_errors_total: a counter of the failed queries and
+//     transactions. Finds that match no rows are not failures.
+func Database(registry *Registry, name string, driver contract.DatabaseDriver) contract.DatabaseDriver {
+	return &databaseDriver{
+		driver: driver,
+		name:   name,
+		durations: registry.Histogram(
+			"db_query_duration_seconds",
+			"Time taken by database queries, in seconds.",
+			DefaultBuckets,
+			"database", "operation",
+		),
+		errors: registry.Counter(
+			"db_query_errors_total",
+			"Total number of failed database queries.",
+			"database", "operation",
+		),
+	}
+}
+
+// databaseDriver is a [contract.DatabaseDriver] that
+// measures the queries of another one.
+type databaseDriver struct {
+	// driver stores the instrumented driver.
+	driver contract.DatabaseDriver
+
+	// name stores the database label value.
+	name string
+
+	// durations measures the queries.
+	durations *Histogram
+
+	// errors counts the failed queries.
+	errors *Counter
+}
+
+// Close closes the instrumented driver.
+func (database *databaseDriver) Close() error {
+	return database.driver.Close()
+}
+
+// Ping pings the instrumented driver.
+func (database *databaseDriver) Ping(ctx context.Context) error {
+	return database.driver.Ping(ctx)
+}
+
+// Exec executes the given query, measuring it as "exec".
+func (database *databaseDriver) Exec(ctx context.Context, query string, args ...any) (int64, error) {
+	start := time.Now()
+	affected, err := database.driver.Exec(ctx, query, args...)
+	database.observe("exec", start, err)
+
+	return affected, err
+}
+
+// ExecNamed executes the given query, measuring it as "exec".
+func (database *databaseDriver) ExecNamed(ctx context.Context, query string, arg any) (int64, error) {
+	start := time.Now()
+	affected, err := database.driver.ExecNamed(ctx, query, arg)
+	database.observe("exec", start, err)
+
+	return affected, err
+}
+
+// Select executes the given query, measuring it as "select".
+func (database *databaseDriver) Select(ctx context.Context, query string, dest any, args ...any) error {
+	start := time.Now()
+	err := database.driver.Select(ctx, query, dest, args...)
+	database.observe("select", start, err)
+
+	return err
+}
+
+// SelectNamed executes the given query, measuring it as "select".
+func (database *databaseDriver) SelectNamed(ctx context.Context, query string, dest any, arg any) error {
+	start := time.Now()
+	err := database.driver.SelectNamed(ctx, query, dest, arg)
+	database.observe("select", start, err)
+
+	return err
+}
+
+// Find executes the given query, measuring it as "find".
+func (database *databaseDriver) Find(ctx context.Context, query string, dest any, args ...any) error {
+	start := time.Now()
+	err := database.driver.Find(ctx, query, dest, args...)
+	database.observe("find", start, err)
+
+	return err
+}
+
+// FindNamed executes the given query, measuring it as "find".
+func (database *databaseDriver) FindNamed(ctx context.Context, query string, dest any, arg any) error {
+	start := time.Now()
+	err := database.driver.FindNamed(ctx, query, dest, arg)
+	database.observe("find", start, err)
+
+	return err
+}
+
+// WithTransaction runs fn in a transaction, measuring it as
+// "transaction" and the queries of fn on their own.
+func (database *databaseDriver) WithTransaction(ctx context.Context, fn func(tx contract.DatabaseDriver) error) error {
+	start := time.Now()
+
+	err := database.driver.WithTransaction(ctx, func(tx contract.DatabaseDriver) error {
+		return fn(&databaseDriver{
+			driver:    tx,
+			name:      database.name,
+			durations: database.durations,
+			errors:    database.errors,
+		})
+	})
+
+	database.observe("transaction", start, err)
+
+	return err
+}
+
+// observe records the duration of an operation that started
+// at the given time, and its failure if any.
+func (database *databaseDriver) observe(operation string, start time.Time, err error) {
+	database.durations.Observe(time.Since(start).Seconds(), database.name, operation)
+
+	if err != nil && !errors.Is(err, contract.ErrDatabaseNoRows) {
+		database.errors.Inc(database.name, operation)
+	}
+}
@@ -0,0 +1,73 @@
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/framework/metrics"
+
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseMeasuresQueries(t *testing.T) {
+	t.Parallel()
+
+	driver := mock.NewDatabaseDriverMock(t)
+	driver.On("Exec", tmock.Anything, "DELETE FROM users").Return(int64(2), nil).Once()
+	driver.On("Find", tmock.Anything, "SELECT 1", tmock.Anything).Return(contract.ErrDatabaseNoRows).Once()
+	driver.On("Select", tmock.Anything, "SELECT *", tmock.Anything).Return(errors.New("down")).Once()
+
+	registry := metrics.New()
+	db := metrics.Database(registry, "main", driver)
+
+	var dest []int
+
+	affected, err := db.Exec(t.Context(), "DELETE FROM users")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), affected)
+
+	require.ErrorIs(t, db.Find(t.Context(), "SELECT 1", &dest), contract.ErrDatabaseNoRows)
+	require.EqualError(t, db.Select(t.Context(), "SELECT *", &dest), "down")
+
+	durations := registry.Histogram("db_query_duration_seconds", "", nil, "database", "operation")
+	failures := registry.Counter("db_query_errors_total", "", "database", "operation")
+
+	require.Equal(t, uint64(1), durations.Count("main", "exec"))
+	require.Equal(t, uint64(1), durations.Count("main", "find"))
+	require.Equal(t, uint64(1), durations.Count("main", "select"))
+	require.Equal(t, float64(0), failures.Value("main", "find"))
+	require.Equal(t, float64(1), failures.Value("main", "select"))
+}
+
+func TestDatabaseMeasuresTransactions(t *testing.T) {
+	t.Parallel()
+
+	tx := mock.NewDatabaseDriverMock(t)
+	tx.On("Exec", tmock.Anything, "UPDATE users").Return(int64(1), nil).Once()
+
+	driver := mock.NewDatabaseDriverMock(t)
+	driver.On("WithTransaction", tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			fn := args.Get(1).(func(contract.DatabaseDriver) error)
+			require.NoError(t, fn(tx))
+		}).
+		Return(nil).
+		Once()
+
+	registry := metrics.New()
+	db := metrics.Database(registry, "main", driver)
+
+	require.NoError(t, db.WithTransaction(t.Context(), func(tx contract.DatabaseDriver) error {
+		_, err := tx.Exec(t.Context(), "UPDATE users")
+
+		return err
+	}))
+
+	durations := registry.Histogram("db_query_duration_seconds", "", nil, "database", "operation")
+
+	require.Equal(t, uint64(1), durations.Count("main", "transaction"))
+	require.Equal(t, uint64(1), durations.Count("main", "exec"))
+}
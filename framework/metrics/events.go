@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// Events wraps the given event driver so that its events are counted
+// in the given registry, labelled by the given broker name and the
+// event:
+//
+//   - events_published_total: a counter of the published events.
+//   - events_publish_errors_total: a counter of the failed publishes.
+//   - events_delivered_total: a counter of the events delivered to
+//     subscribers, labelled by the subscribed event, which may be a
+//     wildcard pattern.
+//
+// Event names become label values, so they should have a bounded
+// cardinality, which rules out embedding identifiers in them.
+func Events(registry *Registry, name string, driver contract.EventDriver) contract.EventDriver {
+	return &eventDriver{
+		driver: driver,
+		name:   name,
+		published: registry.Counter(
+			"events_published_total",
+			"Total number of published events.",
+			"broker", "event",
+		),
+		failed: registry.Counter(
+			"events_publish_errors_total",
+			"Total number of events that failed to publish.",
+			"broker", "event",
+		),
+		delivered: registry.Counter(
+			"events_delivered_total",
+			"Total number of events delivered to subscribers.",
+			"broker", "event",
+		),
+	}
+}
+
+// eventDriver is a [contract.EventDriver] that counts
+// the events of another one.
+type eventDriver struct {
+	// driver stores the instrumented driver.
+	driver contract.EventDriver
+
+	// name stores the broker label value.
+	name string
+
+	// published counts the published events.
+	published *Counter
+
+	// failed counts the events that failed to publish.
+	failed *Counter
+
+	// delivered counts the events delivered to subscribers.
+	delivered *Counter
+}
+
+// Publish publishes the given event, counting it as
+// published or failed.
+func (events *eventDriver) Publish(ctx context.Context, event string, payload []byte) error {
+	if err := events.driver.Publish(ctx, event, payload); err != nil {
+		events.failed.Inc(events.name, event)
+
+		return err
+	}
+
+	events.published.Inc(events.name, event)
+
+	return nil
+}
+
+// Subscribe subscribes the given handler to the given event,
+// counting every delivery before calling it.
+func (events *eventDriver) Subscribe(ctx context.Context, event string, handler contract.EventHandler) (contract.EventUnsubscribeFunc, error) {
+	return events.driver.Subscribe(ctx, event, func(payload []byte) {
+		events.delivered.Inc(events.name, event)
+		handler(payload)
+	})
+}
+
+// Close closes the instrumented driver.
+func (events *eventDriver) Close() error {
+	return events.driver.Close()
+}
@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/framework/event"
+	"github.com/studiolambda/cosmos/framework/metrics"
+
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsCountsPublishedAndDelivered(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	broker := metrics.Events(registry, "memory", event.NewMemoryBroker())
+	defer broker.Close()
+
+	received := make(chan []byte, 1)
+
+	unsubscribe, err := broker.Subscribe(t.Context(), "user.*", func(payload []byte) {
+		received <- payload
+	})
+
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	require.NoError(t, broker.Publish(t.Context(), "user.created", []byte(`{}`)))
+
+	select {
+	case payload := <-received:
+		require.Equal(t, []byte(`{}`), payload)
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+	}
+
+	require.Equal(t, float64(1), registry.Counter("events_published_total", "", "broker", "event").Value("memory", "user.created"))
+	require.Equal(t, float64(1), registry.Counter("events_delivered_total", "", "broker", "event").Value("memory", "user.*"))
+}
+
+func TestEventsCountsFailedPublishes(t *testing.T) {
+	t.Parallel()
+
+	driver := mock.NewEventDriverMock(t)
+	driver.On("Publish", tmock.Anything, "user.created", tmock.Anything).Return(errors.New("down")).Once()
+
+	registry := metrics.New()
+	broker := metrics.Events(registry, "mock", driver)
+
+	require.EqualError(t, broker.Publish(t.Context(), "user.created", nil), "down")
+	require.Equal(t, float64(1), registry.Counter("events_publish_errors_total", "", "broker", "event").Value("mock", "user.created"))
+	require.Equal(t, float64(0), registry.Counter("events_published_total", "", "broker", "event").Value("mock", "user.created"))
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+)
+
+// ContentType is the media type of the Prometheus text
+// exposition format served by [Handler].
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler returns a [framework.Handler] that serves the metrics of
+// the given registry in the Prometheus text exposition format.
+//
+// Keep the endpoint away from the public, either by serving it on an
+// internal listener or behind authentication, as metrics reveal the
+// routes and traffic of the application.
+func Handler(registry *Registry) framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var buffer bytes.Buffer
+
+		if _, err := registry.WriteTo(&buffer); err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Cache-Control", "no-store")
+
+		return response.Raw(w, http.StatusOK, buffer.Bytes())
+	}
+}
@@ -0,0 +1,28 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerServesExposition(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	registry.Counter("jobs_total", "Total number of jobs.").Inc()
+
+	res := metrics.Handler(registry).Record(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, metrics.ContentType, res.Header.Get("Content-Type"))
+	require.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+	require.Equal(t, "# HELP jobs_total Total number of jobs.\n# TYPE jobs_total counter\njobs_total 1\n", string(body))
+}
@@ -0,0 +1,537 @@
+// Package metrics provides a dependency-free metrics registry for
+// Cosmos applications that's exposed in the Prometheus text format.
+//
+// Metrics are registered on a [Registry] as counters, gauges or
+// histograms, each one a family of series told apart by their label
+// values. The package instruments HTTP requests through [Middleware],
+// and cache, event and database drivers through [Cache], [Events] and
+// [Database], all of them recording into the same registry:
+//
+//	registry := metrics.New()
+//
+//	app.Use(metrics.Middleware(registry))
+//	app.Get("/metrics", metrics.Handler(registry))
+//
+//	cache := metrics.Cache(registry, "default", redis)
+//	events := metrics.Events(registry, "default", nats)
+//	db := metrics.Database(registry, "default", sql)
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Kind is the type of a metric family.
+type Kind string
+
+const (
+	// KindCounter is the kind of [Counter] families.
+	KindCounter Kind = "counter"
+
+	// KindGauge is the kind of [Gauge] families.
+	KindGauge Kind = "gauge"
+
+	// KindHistogram is the kind of [Histogram] families.
+	KindHistogram Kind = "histogram"
+)
+
+// DefaultBuckets are the default histogram buckets, in seconds,
+// which suit the latency of network services.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// SizeBuckets are histogram buckets, in bytes, which suit the
+// size of HTTP responses, from 100B to 100MB.
+var SizeBuckets = []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000}
+
+// metricName matches valid metric names.
+var metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// labelName matches valid label names.
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Registry holds metric families and writes them in the
+// Prometheus text format. It's safe for concurrent use.
+type Registry struct {
+	// mutex guards the families.
+	mutex sync.RWMutex
+
+	// families stores the registered families by name.
+	families map[string]*family
+}
+
+// New creates an empty [Registry].
+func New() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+	}
+}
+
+// Counter registers a counter with the given name, help text and
+// label names, or returns the existing one when it was already
+// registered with the same kind and labels.
+//
+// It panics if the name or labels are invalid or the name is taken
+// by a different metric, as they're programming errors.
+func (registry *Registry) Counter(name string, help string, labels ...string) *Counter {
+	return &Counter{family: registry.register(name, help, KindCounter, labels, nil)}
+}
+
+// Gauge registers a gauge with the given name, help text and label
+// names, or returns the existing one when it was already registered
+// with the same kind and labels.
+//
+// It panics if the name or labels are invalid or the name is taken
+// by a different metric, as they're programming errors.
+func (registry *Registry) Gauge(name string, help string, labels ...string) *Gauge {
+	return &Gauge{family: registry.register(name, help, KindGauge, labels, nil)}
+}
+
+// Histogram registers a histogram with the given name, help text,
+// bucket upper bounds and label names, or returns the existing one
+// when it was already registered with the same kind, buckets and
+// labels. Nil buckets default to [DefaultBuckets].
+//
+// It panics if the name, buckets or labels are invalid or the name is
+// taken by a different metric, as they're programming errors.
+func (registry *Registry) Histogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+
+	// The +Inf bucket is always written, so it's not stored.
+	if len(buckets) > 0 && math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+
+	if !slices.IsSorted(buckets) || len(slices.Compact(slices.Clone(buckets))) != len(buckets) {
+		panic("metrics: histogram " + strconv.Quote(name) + " buckets must be sorted and unique")
+	}
+
+	return &Histogram{family: registry.register(name, help, KindHistogram, labels, buckets)}
+}
+
+// WriteTo writes every family in the Prometheus text exposition
+// format, sorted by name, and returns the number of bytes written.
+func (registry *Registry) WriteTo(w io.Writer) (int64, error) {
+	registry.mutex.RLock()
+	families := make([]*family, 0, len(registry.families))
+
+	for _, family := range registry.families {
+		families = append(families, family)
+	}
+
+	registry.mutex.RUnlock()
+
+	slices.SortFunc(families, func(a, b *family) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	counter := &countingWriter{writer: w}
+	buffered := bufio.NewWriter(counter)
+
+	for _, family := range families {
+		family.write(buffered)
+	}
+
+	err := buffered.Flush()
+
+	return counter.written, err
+}
+
+// register returns the family with the given name, creating it
+// when it does not exist.
+func (registry *Registry) register(name string, help string, kind Kind, labels []string, buckets []float64) *family {
+	if !metricName.MatchString(name) {
+		panic("metrics: invalid metric name " + strconv.Quote(name))
+	}
+
+	for _, label := range labels {
+		if !labelName.MatchString(label) || strings.HasPrefix(label, "__") || (kind == KindHistogram && label == "le") {
+			panic("metrics: invalid label name " + strconv.Quote(label) + " in " + strconv.Quote(name))
+		}
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if existing, ok := registry.families[name]; ok {
+		if existing.kind != kind || !slices.Equal(existing.labels, labels) || !slices.Equal(existing.buckets, buckets) {
+			panic("metrics: " + strconv.Quote(name) + " is already registered with a different definition")
+		}
+
+		return existing
+	}
+
+	family := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  slices.Clone(labels),
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+
+	registry.families[name] = family
+
+	return family
+}
+
+// Counter is a metric whose value only goes up, such as the number
+// of handled requests.
+type Counter struct {
+	// family stores the series of the counter.
+	family *family
+}
+
+// Inc increments the series with the given label values by one.
+func (counter *Counter) Inc(labels ...string) {
+	counter.Add(1, labels...)
+}
+
+// Add increments the series with the given label values by the given
+// value. It panics if the value is negative, as counters only go up.
+func (counter *Counter) Add(value float64, labels ...string) {
+	if value < 0 {
+		panic("metrics: counter " + strconv.Quote(counter.family.name) + " can't decrease")
+	}
+
+	counter.family.get(labels).add(value)
+}
+
+// Value returns the value of the series with the given label values.
+func (counter *Counter) Value(labels ...string) float64 {
+	if series := counter.family.find(labels); series != nil {
+		return series.load()
+	}
+
+	return 0
+}
+
+// Gauge is a metric whose value goes up and down, such as the
+// number of requests in flight.
+type Gauge struct {
+	// family stores the series of the gauge.
+	family *family
+}
+
+// Set sets the series with the given label values to the given value.
+func (gauge *Gauge) Set(value float64, labels ...string) {
+	gauge.family.get(labels).store(value)
+}
+
+// Inc increments the series with the given label values by one.
+func (gauge *Gauge) Inc(labels ...string) {
+	gauge.Add(1, labels...)
+}
+
+// Dec decrements the series with the given label values by one.
+func (gauge *Gauge) Dec(labels ...string) {
+	gauge.Add(-1, labels...)
+}
+
+// Add adds the given value, which may be negative, to the
+// series with the given label values.
+func (gauge *Gauge) Add(value float64, labels ...string) {
+	gauge.family.get(labels).add(value)
+}
+
+// Value returns the value of the series with the given label values.
+func (gauge *Gauge) Value(labels ...string) float64 {
+	if series := gauge.family.find(labels); series != nil {
+		return series.load()
+	}
+
+	return 0
+}
+
+// Histogram is a metric that counts observations, such as request
+// durations, in buckets of cumulative upper bounds.
+type Histogram struct {
+	// family stores the series of the histogram.
+	family *family
+}
+
+// Observe records the given value in the series with
+// the given label values.
+func (histogram *Histogram) Observe(value float64, labels ...string) {
+	histogram.family.get(labels).observe(histogram.family.buckets, value)
+}
+
+// Count returns the number of observations of the series
+// with the given label values.
+func (histogram *Histogram) Count(labels ...string) uint64 {
+	series := histogram.family.find(labels)
+
+	if series == nil {
+		return 0
+	}
+
+	series.mutex.Lock()
+	defer series.mutex.Unlock()
+
+	return series.count
+}
+
+// Sum returns the sum of the observations of the series
+// with the given label values.
+func (histogram *Histogram) Sum(labels ...string) float64 {
+	series := histogram.family.find(labels)
+
+	if series == nil {
+		return 0
+	}
+
+	series.mutex.Lock()
+	defer series.mutex.Unlock()
+
+	return series.sum
+}
+
+// family is a named metric and its series.
+type family struct {
+	// name stores the metric name.
+	name string
+
+	// help stores the help text.
+	help string
+
+	// kind stores the metric kind.
+	kind Kind
+
+	// labels stores the label names.
+	labels []string
+
+	// buckets stores the upper bounds of histogram buckets,
+	// without the +Inf one.
+	buckets []float64
+
+	// mutex guards the series.
+	mutex sync.RWMutex
+
+	// series stores the series by their joined label values.
+	series map[string]*series
+}
+
+// get returns the series with the given label values, creating
+// it when it does not exist. It panics if the number of values
+// does not match the labels, as it's a programming error.
+func (family *family) get(values []string) *series {
+	if len(values) != len(family.labels) {
+		panic(fmt.Sprintf("metrics: %q expects %d label values, got %d", family.name, len(family.labels), len(values)))
+	}
+
+	if existing := family.find(values); existing != nil {
+		return existing
+	}
+
+	key := strings.Join(values, "\xff")
+
+	family.mutex.Lock()
+	defer family.mutex.Unlock()
+
+	if existing, ok := family.series[key]; ok {
+		return existing
+	}
+
+	created := &series{values: slices.Clone(values)}
+
+	if family.kind == KindHistogram {
+		created.counts = make([]uint64, len(family.buckets))
+	}
+
+	family.series[key] = created
+
+	return created
+}
+
+// find returns the series with the given label values,
+// or nil when it does not exist.
+func (family *family) find(values []string) *series {
+	family.mutex.RLock()
+	defer family.mutex.RUnlock()
+
+	return family.series[strings.Join(values, "\xff")]
+}
+
+// write writes the family in the text exposition format.
+func (family *family) write(w *bufio.Writer) {
+	family.mutex.RLock()
+	entries := make([]*series, 0, len(family.series))
+
+	for _, entry := range family.series {
+		entries = append(entries, entry)
+	}
+
+	family.mutex.RUnlock()
+
+	slices.SortFunc(entries, func(a, b *series) int {
+		return slices.Compare(a.values, b.values)
+	})
+
+	if family.help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", family.name, helpEscaper.Replace(family.help))
+	}
+
+	fmt.Fprintf(w, "# TYPE %s %s\n", family.name, family.kind)
+
+	for _, entry := range entries {
+		if family.kind != KindHistogram {
+			family.sample(w, "", entry.values, "", entry.load())
+
+			continue
+		}
+
+		entry.mutex.Lock()
+		counts, sum, count := slices.Clone(entry.counts), entry.sum, entry.count
+		entry.mutex.Unlock()
+
+		var cumulative uint64
+
+		for i, bound := range family.buckets {
+			cumulative += counts[i]
+			family.sample(w, "_bucket", entry.values, formatFloat(bound), float64(cumulative))
+		}
+
+		family.sample(w, "_bucket", entry.values, "+Inf", float64(count))
+		family.sample(w, "_sum", entry.values, "", sum)
+		family.sample(w, "_count", entry.values, "", float64(count))
+	}
+}
+
+// sample writes a sample line of the family with the given name
+// suffix, label values, histogram bucket bound and value.
+func (family *family) sample(w *bufio.Writer, suffix string, values []string, le string, value float64) {
+	w.WriteString(family.name + suffix)
+
+	if len(values) > 0 || le != "" {
+		w.WriteByte('{')
+
+		for i, label := range family.labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+
+			w.WriteString(label + `="` + labelEscaper.Replace(values[i]) + `"`)
+		}
+
+		if le != "" {
+			if len(values) > 0 {
+				w.WriteByte(',')
+			}
+
+			w.WriteString(`le="` + le + `"`)
+		}
+
+		w.WriteByte('}')
+	}
+
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+// series is the value of a family for a set of label values.
+type series struct {
+	// values stores the label values.
+	values []string
+
+	// bits stores the value of counters and gauges
+	// as the bits of a float64.
+	bits atomic.Uint64
+
+	// mutex guards the histogram fields below.
+	mutex sync.Mutex
+
+	// counts stores the observations per bucket.
+	counts []uint64
+
+	// sum stores the sum of the observations.
+	sum float64
+
+	// count stores the number of observations.
+	count uint64
+}
+
+// load returns the value of the series.
+func (series *series) load() float64 {
+	return math.Float64frombits(series.bits.Load())
+}
+
+// store sets the value of the series.
+func (series *series) store(value float64) {
+	series.bits.Store(math.Float64bits(value))
+}
+
+// add adds the given value to the series.
+func (series *series) add(value float64) {
+	for {
+		old := series.bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + value)
+
+		if series.bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+// observe records the given value in the first bucket whose
+// upper bound is greater than or equal to it.
+func (series *series) observe(buckets []float64, value float64) {
+	i, _ := slices.BinarySearch(buckets, value)
+
+	series.mutex.Lock()
+	defer series.mutex.Unlock()
+
+	if i < len(series.counts) {
+		series.counts[i]++
+	}
+
+	series.sum += value
+	series.count++
+}
+
+// countingWriter counts the bytes written to a writer.
+type countingWriter struct {
+	// writer stores the underlying writer.
+	writer io.Writer
+
+	// written stores the number of bytes written.
+	written int64
+}
+
+// Write writes the content to the underlying writer.
+func (writer *countingWriter) Write(content []byte) (int, error) {
+	n, err := writer.writer.Write(content)
+	writer.written += int64(n)
+
+	return n, err
+}
+
+// formatFloat formats a sample value as the text format expects.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// helpEscaper escapes help texts.
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// labelEscaper escapes label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
@@ -0,0 +1,210 @@
+package metrics_test
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+// exposition returns the text exposition of the given registry.
+func exposition(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+
+	var builder strings.Builder
+
+	written, err := registry.WriteTo(&builder)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(builder.Len()), written)
+
+	return builder.String()
+}
+
+func TestCounterWritesSeries(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	counter := registry.Counter("jobs_total", "Total number of jobs.", "queue")
+
+	counter.Inc("emails")
+	counter.Add(2, "emails")
+	counter.Inc("reports")
+
+	require.Equal(t, float64(3), counter.Value("emails"))
+	require.Equal(t, ""+
+		"# HELP jobs_total Total number of jobs.\n"+
+		"# TYPE jobs_total counter\n"+
+		"jobs_total{queue=\"emails\"} 3\n"+
+		"jobs_total{queue=\"reports\"} 1\n",
+		exposition(t, registry),
+	)
+}
+
+func TestCounterPanicsWhenDecreased(t *testing.T) {
+	t.Parallel()
+
+	counter := metrics.New().Counter("jobs_total", "")
+
+	require.Panics(t, func() {
+		counter.Add(-1)
+	})
+}
+
+func TestGaugeGoesUpAndDown(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	gauge := registry.Gauge("workers", "")
+
+	gauge.Set(5)
+	gauge.Inc()
+	gauge.Dec()
+	gauge.Add(-2.5)
+
+	require.Equal(t, 2.5, gauge.Value())
+	require.Equal(t, "# TYPE workers gauge\nworkers 2.5\n", exposition(t, registry))
+}
+
+func TestHistogramWritesCumulativeBuckets(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	histogram := registry.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+
+	histogram.Observe(0.05, "read")
+	histogram.Observe(0.1, "read")
+	histogram.Observe(0.5, "read")
+	histogram.Observe(3, "read")
+
+	require.Equal(t, uint64(4), histogram.Count("read"))
+	require.Equal(t, 3.65, histogram.Sum("read"))
+	require.Equal(t, ""+
+		"# HELP latency_seconds Latency.\n"+
+		"# TYPE latency_seconds histogram\n"+
+		"latency_seconds_bucket{op=\"read\",le=\"0.1\"} 2\n"+
+		"latency_seconds_bucket{op=\"read\",le=\"1\"} 3\n"+
+		"latency_seconds_bucket{op=\"read\",le=\"+Inf\"} 4\n"+
+		"latency_seconds_sum{op=\"read\"} 3.65\n"+
+		"latency_seconds_count{op=\"read\"} 4\n",
+		exposition(t, registry),
+	)
+}
+
+func TestHistogramIgnoresInfiniteBucket(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	registry.Histogram("size_bytes", "", []float64{10, math.Inf(1)}).Observe(20)
+
+	require.Equal(t, ""+
+		"# TYPE size_bytes histogram\n"+
+		"size_bytes_bucket{le=\"10\"} 0\n"+
+		"size_bytes_bucket{le=\"+Inf\"} 1\n"+
+		"size_bytes_sum 20\n"+
+		"size_bytes_count 1\n",
+		exposition(t, registry),
+	)
+}
+
+func TestRegistryEscapesHelpAndLabels(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	registry.Counter("escaped_total", "A \\ help\ntext.", "value").Inc("a \"quoted\"\nvalue\\")
+
+	require.Equal(t, ""+
+		"# HELP escaped_total A \\\\ help\\ntext.\n"+
+		"# TYPE escaped_total counter\n"+
+		"escaped_total{value=\"a \\\"quoted\\\"\\nvalue\\\\\"} 1\n",
+		exposition(t, registry),
+	)
+}
+
+func TestRegistrySortsFamilies(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	registry.Gauge("b", "").Set(1)
+	registry.Gauge("a", "").Set(1)
+
+	require.Equal(t, "# TYPE a gauge\na 1\n# TYPE b gauge\nb 1\n", exposition(t, registry))
+}
+
+func TestRegistryReturnsExistingMetric(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	registry.Counter("jobs_total", "", "queue").Inc("emails")
+
+	require.Equal(t, float64(1), registry.Counter("jobs_total", "", "queue").Value("emails"))
+}
+
+func TestRegistryPanicsOnConflictingDefinitions(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	registry.Counter("jobs_total", "", "queue")
+
+	require.Panics(t, func() {
+		registry.Gauge("jobs_total", "", "queue")
+	})
+
+	require.Panics(t, func() {
+		registry.Counter("jobs_total", "", "worker")
+	})
+}
+
+func TestRegistryPanicsOnInvalidDefinitions(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+
+	require.Panics(t, func() {
+		registry.Counter("jobs-total", "")
+	})
+
+	require.Panics(t, func() {
+		registry.Counter("jobs_total", "", "__name")
+	})
+
+	require.Panics(t, func() {
+		registry.Histogram("latency_seconds", "", nil, "le")
+	})
+
+	require.Panics(t, func() {
+		registry.Histogram("latency_seconds", "", []float64{1, 0.5})
+	})
+}
+
+func TestSeriesPanicOnWrongLabelCount(t *testing.T) {
+	t.Parallel()
+
+	counter := metrics.New().Counter("jobs_total", "", "queue")
+
+	require.Panics(t, func() {
+		counter.Inc()
+	})
+}
+
+func TestCounterIsSafeForConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	counter := metrics.New().Counter("jobs_total", "")
+
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Go(func() {
+			counter.Inc()
+		})
+	}
+
+	wg.Wait()
+
+	require.Equal(t, float64(100), counter.Value())
+}
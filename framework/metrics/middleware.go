@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/router"
+)
+
+// MiddlewareOptions configures the HTTP metrics middleware.
+type MiddlewareOptions struct {
+	// Registry receives the metrics. It's required.
+	Registry *Registry
+
+	// DurationBuckets are the buckets of the request duration
+	// histogram, in seconds. Defaults to [DefaultBuckets].
+	DurationBuckets []float64
+
+	// SizeBuckets are the buckets of the response size histogram,
+	// in bytes. Defaults to [SizeBuckets].
+	SizeBuckets []float64
+
+	// SkipPaths lists request paths that are not measured,
+	// such as the metrics endpoint itself.
+	SkipPaths []string
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options MiddlewareOptions) withDefaults() MiddlewareOptions {
+	if options.DurationBuckets == nil {
+		options.DurationBuckets = DefaultBuckets
+	}
+
+	if options.SizeBuckets == nil {
+		options.SizeBuckets = SizeBuckets
+	}
+
+	return options
+}
+
+// Middleware returns middleware that records the metrics of every
+// request in the given registry, see [MiddlewareWith].
+func Middleware(registry *Registry) framework.Middleware {
+	return MiddlewareWith(MiddlewareOptions{Registry: registry})
+}
+
+// MiddlewareWith returns middleware that records the following metrics
+// of every request, labelled by method, route pattern and status class,
+// such as "2xx":
+//
+//   - http_requests_total: a counter of the handled requests.
+//   - http_requests_in_flight: a gauge of the requests being handled,
+//     labelled by method and route only.
+//   - http_request_duration_seconds: a histogram of the time taken
+//     to respond.
+//   - http_response_size_bytes: a histogram of the response body sizes.
+//
+// Methods other than the standard ones are labelled "OTHER", and
+// routes are labelled as [router.RoutePattern] reports them, such as
+// "/users/{id}" for both "/users/42" and "/users/42/", which unlike
+// the path has a bounded cardinality, so the middleware is meant to be
+// registered with the router's Use. Requests without a matched route
+// are labelled "unmatched".
+//
+// It panics if the registry is nil, as it's a programming error.
+func MiddlewareWith(options MiddlewareOptions) framework.Middleware {
+	options = options.withDefaults()
+
+	if options.Registry == nil {
+		panic("metrics: middleware requires a registry")
+	}
+
+	labels := []string{"method", "route", "status"}
+
+	requests := options.Registry.Counter(
+		"http_requests_total",
+		"Total number of handled HTTP requests.",
+		labels...,
+	)
+
+	inFlight := options.Registry.Gauge(
+		"http_requests_in_flight",
+		"Number of HTTP requests being handled.",
+		"method", "route",
+	)
+
+	durations := options.Registry.Histogram(
+		"http_request_duration_seconds",
+		"Time taken to respond to HTTP requests, in seconds.",
+		options.DurationBuckets,
+		labels...,
+	)
+
+	sizes := options.Registry.Histogram(
+		"http_response_size_bytes",
+		"Size of the HTTP response bodies, in bytes.",
+		options.SizeBuckets,
+		labels...,
+	)
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if slices.Contains(options.SkipPaths, r.URL.Path) {
+				return next(w, r)
+			}
+
+			start := time.Now()
+			hooks := request.Hooks(r)
+			method := methodLabel(r.Method)
+			route := router.RoutePattern(r.Pattern)
+
+			if route == "" {
+				route = "unmatched"
+			}
+
+			var status atomic.Int64
+			var bytes atomic.Int64
+
+			hooks.BeforeWriteHeader(func(w http.ResponseWriter, code int) {
+				status.CompareAndSwap(0, int64(code))
+			})
+
+			hooks.BeforeWrite(func(w http.ResponseWriter, content []byte) {
+				bytes.Add(int64(len(content)))
+			})
+
+			hooks.AfterResponse(func(err error) {
+				class := statusClass(int(status.Load()))
+
+				requests.Inc(method, route, class)
+				durations.Observe(time.Since(start).Seconds(), method, route, class)
+				sizes.Observe(float64(bytes.Load()), method, route, class)
+			})
+
+			inFlight.Inc(method, route)
+			defer inFlight.Dec(method, route)
+
+			return next(w, r)
+		}
+	}
+}
+
+// methodLabel returns the given method when it's a standard
+// one, or "OTHER", so that clients can't inflate the cardinality.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// statusClass returns the class of the given status
+// code, such as "2xx" for 200.
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+
+	return strconv.Itoa(status/100) + "xx"
+}
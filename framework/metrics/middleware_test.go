@@ -0,0 +1,137 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+func metricsApp(registry *metrics.Registry, options metrics.MiddlewareOptions) *framework.Router {
+	options.Registry = registry
+
+	app := framework.New()
+	app.Use(metrics.MiddlewareWith(options))
+	app.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, "hello")
+	})
+	app.Get("/missing", func(w http.ResponseWriter, r *http.Request) error {
+		return framework.ErrNotFound
+	})
+	app.Get("/metrics", metrics.Handler(registry))
+
+	return app
+}
+
+func TestMiddlewareRecordsRequests(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	app := metricsApp(registry, metrics.MiddlewareOptions{})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	requests := registry.Counter("http_requests_total", "", "method", "route", "status")
+	durations := registry.Histogram("http_request_duration_seconds", "", nil, "method", "route", "status")
+	sizes := registry.Histogram("http_response_size_bytes", "", metrics.SizeBuckets, "method", "route", "status")
+
+	require.Equal(t, float64(2), requests.Value("GET", "/users/{id}", "2xx"))
+	require.Equal(t, float64(1), requests.Value("GET", "/missing", "4xx"))
+	require.Equal(t, uint64(2), durations.Count("GET", "/users/{id}", "2xx"))
+	require.Equal(t, float64(10), sizes.Sum("GET", "/users/{id}", "2xx"))
+}
+
+func TestMiddlewareLabelsTrailingSlashesWithTheirRoute(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	app := metricsApp(registry, metrics.MiddlewareOptions{})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2/", nil))
+
+	requests := registry.Counter("http_requests_total", "", "method", "route", "status")
+
+	require.Equal(t, float64(2), requests.Value("GET", "/users/{id}", "2xx"))
+}
+
+func TestMiddlewareLabelsUnmatchedRequests(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	app := metricsApp(registry, metrics.MiddlewareOptions{})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	requests := registry.Counter("http_requests_total", "", "method", "route", "status")
+
+	require.Equal(t, float64(1), requests.Value("GET", "unmatched", "4xx"))
+}
+
+func TestMiddlewareTracksRequestsInFlight(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	inFlight := registry.Gauge("http_requests_in_flight", "", "method", "route")
+
+	var during float64
+
+	app := framework.New()
+	app.Use(metrics.Middleware(registry))
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		during = inFlight.Value("GET", "/")
+
+		return nil
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, float64(1), during)
+	require.Equal(t, float64(0), inFlight.Value("GET", "/"))
+}
+
+func TestMiddlewareLabelsNonStandardMethods(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+
+	app := framework.New()
+	app.Use(metrics.Middleware(registry))
+	app.Method("PURGE", "/", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PURGE", "/", nil))
+
+	requests := registry.Counter("http_requests_total", "", "method", "route", "status")
+
+	require.Equal(t, float64(1), requests.Value("OTHER", "/", "2xx"))
+}
+
+func TestMiddlewareSkipsPaths(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.New()
+	app := metricsApp(registry, metrics.MiddlewareOptions{SkipPaths: []string{"/metrics"}})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	requests := registry.Counter("http_requests_total", "", "method", "route", "status")
+
+	require.Equal(t, float64(0), requests.Value("GET", "/metrics", "2xx"))
+}
+
+func TestMiddlewarePanicsWithoutRegistry(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		metrics.MiddlewareWith(metrics.MiddlewareOptions{})
+	})
+}
@@ -0,0 +1,362 @@
+package middleware
+
+import (
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/router"
+)
+
+// AccessLogField is a field of the access log records.
+type AccessLogField string
+
+const (
+	// FieldMethod logs the request method as "method".
+	FieldMethod AccessLogField = "method"
+
+	// FieldRoute logs the pattern of the matched route as "route",
+	// such as "/users/{id}", which unlike the path has a bounded
+	// cardinality.
+	FieldRoute AccessLogField = "route"
+
+	// FieldPath logs the raw request path as "path".
+	FieldPath AccessLogField = "path"
+
+	// FieldQuery logs the redacted query string as "query".
+	FieldQuery AccessLogField = "query"
+
+	// FieldStatus logs the response status code as "status".
+	FieldStatus AccessLogField = "status"
+
+	// FieldDuration logs the time taken to respond as "duration".
+	FieldDuration AccessLogField = "duration"
+
+	// FieldBytes logs the number of body bytes written as "bytes".
+	FieldBytes AccessLogField = "bytes"
+
+	// FieldIP logs the client IP address as "ip", as resolved
+	// by [TrustedProxies].
+	FieldIP AccessLogField = "ip"
+
+	// FieldUserAgent logs the User-Agent header as "user_agent".
+	FieldUserAgent AccessLogField = "user_agent"
+
+	// FieldCorrelationID logs the correlation ID as "correlation_id".
+	// Leave it out when the logger already uses [correlation.Handler].
+	FieldCorrelationID AccessLogField = "correlation_id"
+
+	// FieldError logs the error returned by the handler as "err".
+	FieldError AccessLogField = "err"
+)
+
+// redacted replaces the values of redacted headers
+// and query parameters in the access log.
+const redacted = "[REDACTED]"
+
+// AccessLogOptions configures the access log middleware.
+type AccessLogOptions struct {
+	// Logger receives the access log records. Defaults to
+	// [slog.Default].
+	Logger *slog.Logger
+
+	// Message is the message of the records.
+	// Defaults to "request completed".
+	Message string
+
+	// Fields lists the logged fields. Defaults to every field
+	// but [FieldPath] and [FieldQuery].
+	Fields []AccessLogField
+
+	// Headers lists request headers logged in a "headers" group.
+	Headers []string
+
+	// RedactHeaders lists headers whose values are redacted when
+	// logged. Defaults to the usual credential headers.
+	RedactHeaders []string
+
+	// RedactQuery lists query parameters whose values are redacted
+	// when logged. Defaults to the usual credential parameters.
+	RedactQuery []string
+
+	// SampleRate is the fraction of successful requests that is
+	// logged, between 0 and 1, so that 0 logs failures only.
+	// Requests that fail or respond with a status code of 400 or
+	// above are always logged. Every request is logged when nil.
+	SampleRate *float64
+
+	// Level returns the level of the record of a response.
+	// Defaults to error for 5xx responses, warn for 4xx responses,
+	// error for failures after a partial response and info otherwise.
+	Level func(status int, err error) slog.Level
+
+	// SkipPaths lists request paths that are not logged,
+	// such as health check endpoints.
+	SkipPaths []string
+
+	// Skip reports whether a request is not logged.
+	Skip func(r *http.Request) bool
+}
+
+// DefaultAccessLogOptions holds sensible defaults for the
+// access log middleware.
+var DefaultAccessLogOptions = AccessLogOptions{
+	Message: "request completed",
+	Fields: []AccessLogField{
+		FieldMethod,
+		FieldRoute,
+		FieldStatus,
+		FieldDuration,
+		FieldBytes,
+		FieldIP,
+		FieldUserAgent,
+		FieldCorrelationID,
+		FieldError,
+	},
+	RedactHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "X-API-Key"},
+	RedactQuery:   []string{"token", "access_token", "api_key", "password", "secret", "signature"},
+	Level: func(status int, err error) slog.Level {
+		switch {
+		case status >= http.StatusInternalServerError:
+			return slog.LevelError
+		case status >= http.StatusBadRequest:
+			return slog.LevelWarn
+		case err != nil:
+			return slog.LevelError
+		default:
+			return slog.LevelInfo
+		}
+	},
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultAccessLogOptions] fields.
+func (options AccessLogOptions) withDefaults() AccessLogOptions {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+
+	if options.Message == "" {
+		options.Message = DefaultAccessLogOptions.Message
+	}
+
+	if len(options.Fields) == 0 {
+		options.Fields = DefaultAccessLogOptions.Fields
+	}
+
+	if len(options.RedactHeaders) == 0 {
+		options.RedactHeaders = DefaultAccessLogOptions.RedactHeaders
+	}
+
+	if len(options.RedactQuery) == 0 {
+		options.RedactQuery = DefaultAccessLogOptions.RedactQuery
+	}
+
+	if options.Level == nil {
+		options.Level = DefaultAccessLogOptions.Level
+	}
+
+	return options
+}
+
+// AccessLog returns middleware that logs every request to the given
+// logger using [DefaultAccessLogOptions].
+func AccessLog(logger *slog.Logger) framework.Middleware {
+	options := DefaultAccessLogOptions
+	options.Logger = logger
+
+	return AccessLogWith(options)
+}
+
+// AccessLogWith returns middleware that logs a record for every
+// request once its response is complete, unlike [Logger] which only
+// logs failures. Records include the configured fields, such as the
+// status, the duration and the bytes written, with a level based on
+// the status.
+//
+// Records are logged with the request context, so they compose with
+// [correlation.Handler] and any other context-aware [slog.Handler].
+//
+// Example usage:
+//
+//	rate := 0.1
+//
+//	app.Use(middleware.AccessLogWith(middleware.AccessLogOptions{
+//	    Logger:     logger,
+//	    SampleRate: &rate,
+//	    SkipPaths:  []string{"/livez", "/readyz"},
+//	}))
+func AccessLogWith(options AccessLogOptions) framework.Middleware {
+	options = options.withDefaults()
+	rate := 1.0
+
+	if options.SampleRate != nil {
+		rate = *options.SampleRate
+	}
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if slices.Contains(options.SkipPaths, r.URL.Path) || (options.Skip != nil && options.Skip(r)) {
+				return next(w, r)
+			}
+
+			start := time.Now()
+			hooks := request.Hooks(r)
+
+			var status atomic.Int64
+			var bytes atomic.Int64
+
+			hooks.BeforeWriteHeader(func(w http.ResponseWriter, code int) {
+				status.CompareAndSwap(0, int64(code))
+			})
+
+			hooks.BeforeWrite(func(w http.ResponseWriter, content []byte) {
+				bytes.Add(int64(len(content)))
+			})
+
+			hooks.AfterResponse(func(err error) {
+				code := int(status.Load())
+
+				if err == nil && code < http.StatusBadRequest && rate < 1 && rand.Float64() >= rate {
+					return
+				}
+
+				logger := options.Logger
+				level := options.Level(code, err)
+
+				if !logger.Enabled(r.Context(), level) {
+					return
+				}
+
+				entry := accessLogEntry{
+					request:  r,
+					options:  options,
+					status:   code,
+					bytes:    bytes.Load(),
+					duration: time.Since(start),
+					err:      err,
+				}
+
+				logger.LogAttrs(r.Context(), level, options.Message, entry.attrs()...)
+			})
+
+			return next(w, r)
+		}
+	}
+}
+
+// accessLogEntry is the data of an access log record.
+type accessLogEntry struct {
+	// request stores the logged request.
+	request *http.Request
+
+	// options stores the middleware options.
+	options AccessLogOptions
+
+	// status stores the response status code.
+	status int
+
+	// bytes stores the number of body bytes written.
+	bytes int64
+
+	// duration stores the time taken to respond.
+	duration time.Duration
+
+	// err stores the error returned by the handler.
+	err error
+}
+
+// attrs returns the attributes of the configured fields.
+func (entry accessLogEntry) attrs() []slog.Attr {
+	r := entry.request
+	attrs := make([]slog.Attr, 0, len(entry.options.Fields)+1)
+
+	for _, field := range entry.options.Fields {
+		key := string(field)
+
+		switch field {
+		case FieldMethod:
+			attrs = append(attrs, slog.String(key, r.Method))
+		case FieldRoute:
+			attrs = append(attrs, slog.String(key, router.RoutePattern(r.Pattern)))
+		case FieldPath:
+			attrs = append(attrs, slog.String(key, r.URL.Path))
+		case FieldQuery:
+			attrs = append(attrs, slog.String(key, redactQuery(r.URL.Query(), entry.options.RedactQuery)))
+		case FieldStatus:
+			attrs = append(attrs, slog.Int(key, entry.status))
+		case FieldDuration:
+			attrs = append(attrs, slog.Duration(key, entry.duration))
+		case FieldBytes:
+			attrs = append(attrs, slog.Int64(key, entry.bytes))
+		case FieldIP:
+			attrs = append(attrs, slog.String(key, request.ClientIP(r)))
+		case FieldUserAgent:
+			attrs = append(attrs, slog.String(key, r.UserAgent()))
+		case FieldCorrelationID:
+			if id := request.CorrelationID(r); id != "" {
+				attrs = append(attrs, slog.String(key, id))
+			}
+		case FieldError:
+			if entry.err != nil {
+				attrs = append(attrs, slog.Any(key, entry.err))
+			}
+		}
+	}
+
+	if len(entry.options.Headers) > 0 {
+		headers := make([]any, 0, len(entry.options.Headers))
+
+		for _, name := range entry.options.Headers {
+			if value := r.Header.Get(name); value != "" {
+				if slices.ContainsFunc(entry.options.RedactHeaders, func(redact string) bool {
+					return strings.EqualFold(redact, name)
+				}) {
+					value = redacted
+				}
+
+				headers = append(headers, slog.String(http.CanonicalHeaderKey(name), value))
+			}
+		}
+
+		attrs = append(attrs, slog.Group("headers", headers...))
+	}
+
+	return attrs
+}
+
+// redactQuery encodes the given query sorted by parameter,
+// replacing the values of the given parameters.
+func redactQuery(query url.Values, redact []string) string {
+	var builder strings.Builder
+
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		hidden := slices.ContainsFunc(redact, func(parameter string) bool {
+			return strings.EqualFold(parameter, name)
+		})
+
+		for _, value := range query[name] {
+			if builder.Len() > 0 {
+				builder.WriteByte('&')
+			}
+
+			if hidden {
+				value = redacted
+			} else {
+				value = url.QueryEscape(value)
+			}
+
+			builder.WriteString(url.QueryEscape(name) + "=" + value)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,234 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/correlation"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/stretchr/testify/require"
+)
+
+type logBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (log *logBuffer) Write(content []byte) (int, error) {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	return log.buffer.Write(content)
+}
+
+func (log *logBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	records := make([]map[string]any, 0)
+
+	for line := range strings.Lines(log.buffer.String()) {
+		var record map[string]any
+
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func accessLogApp(options middleware.AccessLogOptions) (*framework.Router, *logBuffer) {
+	output := &logBuffer{}
+	options.Logger = slog.New(slog.NewJSONHandler(output, nil))
+
+	app := framework.New()
+	app.Use(middleware.AccessLogWith(options))
+	app.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, "hello")
+	})
+	app.Get("/missing", func(w http.ResponseWriter, r *http.Request) error {
+		return framework.ErrNotFound
+	})
+	app.Get("/failing", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+	app.Get("/livez", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	return app, output
+}
+
+func TestAccessLogLogsEveryRequest(t *testing.T) {
+	t.Parallel()
+
+	app, output := accessLogApp(middleware.AccessLogOptions{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("User-Agent", "cosmos-test")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := output.records(t)
+
+	require.Len(t, records, 1)
+	require.Equal(t, "INFO", records[0]["level"])
+	require.Equal(t, "request completed", records[0]["msg"])
+	require.Equal(t, "GET", records[0]["method"])
+	require.Equal(t, "/users/{id}", records[0]["route"])
+	require.Equal(t, float64(http.StatusOK), records[0]["status"])
+	require.Equal(t, float64(5), records[0]["bytes"])
+	require.Equal(t, "192.0.2.1", records[0]["ip"])
+	require.Equal(t, "cosmos-test", records[0]["user_agent"])
+	require.Contains(t, records[0], "duration")
+	require.NotContains(t, records[0], "path")
+}
+
+func TestAccessLogLevelsByStatus(t *testing.T) {
+	t.Parallel()
+
+	app, output := accessLogApp(middleware.AccessLogOptions{})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failing", nil))
+
+	records := output.records(t)
+
+	require.Len(t, records, 2)
+	require.Equal(t, "WARN", records[0]["level"])
+	require.Equal(t, float64(http.StatusNotFound), records[0]["status"])
+	require.Equal(t, "ERROR", records[1]["level"])
+	require.Equal(t, "boom", records[1]["err"])
+}
+
+func TestAccessLogSkipsPaths(t *testing.T) {
+	t.Parallel()
+
+	app, output := accessLogApp(middleware.AccessLogOptions{
+		SkipPaths: []string{"/livez"},
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	require.Empty(t, output.records(t))
+}
+
+func TestAccessLogSamplesSuccessfulRequests(t *testing.T) {
+	t.Parallel()
+
+	rate := 0.000001
+
+	app, output := accessLogApp(middleware.AccessLogOptions{
+		SampleRate: &rate,
+	})
+
+	for range 10 {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failing", nil))
+
+	records := output.records(t)
+
+	require.Len(t, records, 1)
+	require.Equal(t, "ERROR", records[0]["level"])
+}
+
+func TestAccessLogLogsRegisteredRoutes(t *testing.T) {
+	t.Parallel()
+
+	app, output := accessLogApp(middleware.AccessLogOptions{})
+
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42/", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	records := output.records(t)
+
+	require.Len(t, records, 2)
+	require.Equal(t, "/users/{id}", records[0]["route"])
+	require.Equal(t, "/", records[1]["route"])
+}
+
+func TestAccessLogSamplesNoSuccessfulRequests(t *testing.T) {
+	t.Parallel()
+
+	rate := 0.0
+
+	app, output := accessLogApp(middleware.AccessLogOptions{
+		SampleRate: &rate,
+	})
+
+	for range 10 {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failing", nil))
+
+	records := output.records(t)
+
+	require.Len(t, records, 1)
+	require.Equal(t, "ERROR", records[0]["level"])
+}
+
+func TestAccessLogRedactsHeadersAndQuery(t *testing.T) {
+	t.Parallel()
+
+	app, output := accessLogApp(middleware.AccessLogOptions{
+		Fields:  []middleware.AccessLogField{middleware.FieldPath, middleware.FieldQuery},
+		Headers: []string{"Authorization", "Accept"},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42?page=2&token=secret", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Accept", "application/json")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := output.records(t)
+
+	require.Len(t, records, 1)
+	require.Equal(t, "/users/42", records[0]["path"])
+	require.Equal(t, "page=2&token=[REDACTED]", records[0]["query"])
+	require.Equal(t, map[string]any{
+		"Authorization": "[REDACTED]",
+		"Accept":        "application/json",
+	}, records[0]["headers"])
+	require.NotContains(t, records[0], "method")
+}
+
+func TestAccessLogIncludesCorrelationID(t *testing.T) {
+	t.Parallel()
+
+	output := &logBuffer{}
+	app := framework.New()
+	app.Use(correlation.Middleware())
+	app.Use(middleware.AccessLog(slog.New(slog.NewJSONHandler(output, nil))))
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Correlation-ID", "abc123")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := output.records(t)
+
+	require.Len(t, records, 1)
+	require.Equal(t, "abc123", records[0]["correlation_id"])
+	require.Equal(t, float64(http.StatusNoContent), records[0]["status"])
+}
@@ -0,0 +1,269 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// ErrNoCredentials is returned by an [Authenticator] when the request
+// does not carry its kind of credentials, so that the next one in the
+// chain is tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrInvalidCredentials is returned by credential lookups when the
+// credentials don't match any principal. Authentication then fails
+// with [ErrUnauthorized] without trying the next [Authenticator].
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrUnauthorized is the default error returned when a request is
+// not authenticated. It uses HTTP 401 Unauthorized.
+var ErrUnauthorized = problem.Problem{
+	Title:  "Unauthorized",
+	Detail: "Valid credentials are required to access this resource.",
+	Status: http.StatusUnauthorized,
+}
+
+// Authenticator resolves the principal of type T of a request
+// from its credentials.
+type Authenticator[T any] interface {
+	// Authenticate returns the principal of the credentials of the
+	// request. It returns [ErrNoCredentials] when the request does
+	// not carry them, [ErrInvalidCredentials] when they don't match
+	// any principal, or any other error when they can't be checked.
+	Authenticate(r *http.Request) (T, error)
+
+	// Challenge returns the WWW-Authenticate challenge sent when
+	// the authentication fails with the given error.
+	Challenge(err error) string
+}
+
+// AuthenticateOptions configures the authentication middleware.
+type AuthenticateOptions[T any] struct {
+	// Authenticators are tried in order until one finds its
+	// credentials in the request.
+	Authenticators []Authenticator[T]
+
+	// Optional lets requests without credentials through as guests,
+	// while requests with invalid credentials still fail.
+	Optional bool
+
+	// ErrorResponse is the problem returned when a request is not
+	// authenticated. Defaults to [ErrUnauthorized].
+	ErrorResponse problem.Problem
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options AuthenticateOptions[T]) withDefaults() AuthenticateOptions[T] {
+	if options.ErrorResponse.Status == 0 {
+		options.ErrorResponse = ErrUnauthorized
+	}
+
+	return options
+}
+
+// Authenticate returns middleware that requires requests to be
+// authenticated by one of the given authenticators.
+//
+// Example usage:
+//
+//	app.Use(middleware.Authenticate(
+//	    middleware.BearerAuth("api", findUserByToken),
+//	    middleware.BasicAuth("api", findUserByPassword),
+//	))
+func Authenticate[T any](authenticators ...Authenticator[T]) framework.Middleware {
+	return AuthenticateWith(AuthenticateOptions[T]{Authenticators: authenticators})
+}
+
+// AuthenticateWith returns middleware that authenticates requests with
+// the first authenticator that finds its credentials in them, storing
+// the resolved principal in the request context, where
+// [request.User] reads it from.
+//
+// Requests without credentials, or whose credentials are invalid, fail
+// with [AuthenticateOptions.ErrorResponse] wrapping the cause, along
+// with the WWW-Authenticate challenges of every authenticator. Lookup
+// failures other than [ErrInvalidCredentials] are returned as is.
+func AuthenticateWith[T any](options AuthenticateOptions[T]) framework.Middleware {
+	options = options.withDefaults()
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			cause := ErrNoCredentials
+			failed := -1
+
+			for i, authenticator := range options.Authenticators {
+				user, err := authenticator.Authenticate(r)
+
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+
+				if errors.Is(err, ErrInvalidCredentials) {
+					cause, failed = err, i
+
+					break
+				}
+
+				if err != nil {
+					return err
+				}
+
+				ctx := context.WithValue(r.Context(), contract.UserKey, user)
+
+				return next(w, r.WithContext(ctx))
+			}
+
+			if failed < 0 && options.Optional {
+				return next(w, r)
+			}
+
+			for i, authenticator := range options.Authenticators {
+				err := ErrNoCredentials
+
+				if i == failed {
+					err = cause
+				}
+
+				w.Header().Add("WWW-Authenticate", authenticator.Challenge(err))
+			}
+
+			return options.ErrorResponse.WithError(cause)
+		}
+	}
+}
+
+// AuthenticatorFunc adapts a function and a fixed challenge
+// into an [Authenticator].
+type AuthenticatorFunc[T any] struct {
+	// Func authenticates requests, see [Authenticator.Authenticate].
+	Func func(r *http.Request) (T, error)
+
+	// Scheme is the WWW-Authenticate challenge.
+	Scheme string
+}
+
+// Authenticate calls the function.
+func (authenticator AuthenticatorFunc[T]) Authenticate(r *http.Request) (T, error) {
+	return authenticator.Func(r)
+}
+
+// Challenge returns the fixed challenge.
+func (authenticator AuthenticatorFunc[T]) Challenge(err error) string {
+	return authenticator.Scheme
+}
+
+// BasicAuth returns an [Authenticator] for the HTTP Basic scheme that
+// resolves the username and password of requests with the given
+// lookup, which should return [ErrInvalidCredentials] when they don't
+// match, comparing passwords in constant time, such as with a
+// [contract.Hasher].
+func BasicAuth[T any](realm string, lookup func(ctx context.Context, username string, password string) (T, error)) Authenticator[T] {
+	return &basicAuthenticator[T]{realm: realm, lookup: lookup}
+}
+
+// basicAuthenticator authenticates requests with the HTTP Basic scheme.
+type basicAuthenticator[T any] struct {
+	// realm stores the protection space of the challenge.
+	realm string
+
+	// lookup resolves the credentials.
+	lookup func(ctx context.Context, username string, password string) (T, error)
+}
+
+// Authenticate resolves the Basic credentials of the request.
+func (authenticator *basicAuthenticator[T]) Authenticate(r *http.Request) (T, error) {
+	username, password, ok := r.BasicAuth()
+
+	if !ok {
+		var zero T
+
+		if _, present := authorization(r, "Basic"); present {
+			return zero, ErrInvalidCredentials
+		}
+
+		return zero, ErrNoCredentials
+	}
+
+	return authenticator.lookup(r.Context(), username, password)
+}
+
+// Challenge returns the Basic challenge of the realm.
+func (authenticator *basicAuthenticator[T]) Challenge(err error) string {
+	return `Basic realm=` + strconv.Quote(authenticator.realm) + `, charset="UTF-8"`
+}
+
+// BearerAuth returns an [Authenticator] for the Bearer scheme of
+// RFC 6750 that resolves the tokens of requests with the given
+// lookup, which should return [ErrInvalidCredentials] when they
+// don't match any principal.
+func BearerAuth[T any](realm string, lookup func(ctx context.Context, token string) (T, error)) Authenticator[T] {
+	return &bearerAuthenticator[T]{realm: realm, lookup: lookup}
+}
+
+// bearerAuthenticator authenticates requests with the Bearer scheme.
+type bearerAuthenticator[T any] struct {
+	// realm stores the protection space of the challenge.
+	realm string
+
+	// lookup resolves the tokens.
+	lookup func(ctx context.Context, token string) (T, error)
+}
+
+// Authenticate resolves the Bearer token of the request.
+func (authenticator *bearerAuthenticator[T]) Authenticate(r *http.Request) (T, error) {
+	token, ok := authorization(r, "Bearer")
+
+	if !ok {
+		var zero T
+
+		return zero, ErrNoCredentials
+	}
+
+	if token == "" {
+		var zero T
+
+		return zero, ErrInvalidCredentials
+	}
+
+	return authenticator.lookup(r.Context(), token)
+}
+
+// Challenge returns the Bearer challenge of the realm, flagging
+// the token as invalid when it was rejected.
+func (authenticator *bearerAuthenticator[T]) Challenge(err error) string {
+	return BearerChallenge(authenticator.realm, err)
+}
+
+// BearerChallenge returns the WWW-Authenticate challenge of the Bearer
+// scheme for the given realm, which includes the "invalid_token" error
+// code of RFC 6750 unless the error is [ErrNoCredentials].
+func BearerChallenge(realm string, err error) string {
+	challenge := `Bearer realm=` + strconv.Quote(realm)
+
+	if err != nil && !errors.Is(err, ErrNoCredentials) {
+		challenge += `, error="invalid_token"`
+	}
+
+	return challenge
+}
+
+// authorization returns the credentials of the Authorization header
+// of the request for the given scheme, which is case-insensitive.
+func authorization(r *http.Request, scheme string) (string, bool) {
+	header := r.Header.Get("Authorization")
+	prefix, credentials, _ := strings.Cut(header, " ")
+
+	if !strings.EqualFold(prefix, scheme) {
+		return "", false
+	}
+
+	return strings.TrimSpace(credentials), true
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// APIKeyOptions configures the API key [Authenticator].
+type APIKeyOptions[T any] struct {
+	// Realm is the protection space of the challenge.
+	Realm string
+
+	// Header is the header the key is read from.
+	// Defaults to "X-API-Key".
+	Header string
+
+	// Query is the query parameter the key is read from when the
+	// header is missing. Keys in URLs end up in logs and browser
+	// histories, so it's disabled unless set.
+	Query string
+
+	// Lookup resolves the key to its principal, returning
+	// [ErrInvalidCredentials] when it does not match any, such as
+	// the one returned by [VerifyAPIKey].
+	Lookup func(ctx context.Context, key string) (T, error)
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options APIKeyOptions[T]) withDefaults() APIKeyOptions[T] {
+	if options.Header == "" {
+		options.Header = "X-API-Key"
+	}
+
+	return options
+}
+
+// APIKeyAuth returns an [Authenticator] that resolves the keys of the
+// X-API-Key header of requests with the given lookup.
+func APIKeyAuth[T any](realm string, lookup func(ctx context.Context, key string) (T, error)) Authenticator[T] {
+	return APIKeyAuthWith(APIKeyOptions[T]{Realm: realm, Lookup: lookup})
+}
+
+// APIKeyAuthWith returns an [Authenticator] that resolves the API keys
+// of requests, read from a header or a query parameter, with the
+// lookup of the given options. Its challenge uses the non-standard
+// "APIKey" scheme, naming the header clients should use.
+func APIKeyAuthWith[T any](options APIKeyOptions[T]) Authenticator[T] {
+	return &apiKeyAuthenticator[T]{options: options.withDefaults()}
+}
+
+// apiKeyAuthenticator authenticates requests with API keys.
+type apiKeyAuthenticator[T any] struct {
+	// options stores the authenticator options.
+	options APIKeyOptions[T]
+}
+
+// Authenticate resolves the API key of the request.
+func (authenticator *apiKeyAuthenticator[T]) Authenticate(r *http.Request) (T, error) {
+	key := r.Header.Get(authenticator.options.Header)
+
+	if key == "" && authenticator.options.Query != "" {
+		key = r.URL.Query().Get(authenticator.options.Query)
+	}
+
+	if key == "" {
+		var zero T
+
+		return zero, ErrNoCredentials
+	}
+
+	return authenticator.options.Lookup(r.Context(), key)
+}
+
+// Challenge returns the APIKey challenge of the realm.
+func (authenticator *apiKeyAuthenticator[T]) Challenge(err error) string {
+	return `APIKey realm=` + strconv.Quote(authenticator.options.Realm) +
+		`, header=` + strconv.Quote(authenticator.options.Header)
+}
+
+// GenerateAPIKey generates a random API key and returns it along with
+// its ID and the hash of its secret made with the given hasher. Give
+// the key to the client and store the ID and hash, which
+// [VerifyAPIKey] checks keys against.
+//
+// Keys have the form "<id>.<secret>", where the ID identifies the key
+// in storage, as salted hashes can't be searched, and the secret is
+// never stored.
+func GenerateAPIKey(hasher contract.Hasher) (key string, id string, hash []byte, err error) {
+	id = rand.Text()
+	secret := rand.Text()
+	hash, err = hasher.Hash([]byte(secret))
+
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return id + "." + secret, id, hash, nil
+}
+
+// VerifyAPIKey returns an [APIKeyOptions.Lookup] for keys made by
+// [GenerateAPIKey]. It finds the principal and stored hash of the ID
+// of keys with the given function, which should return
+// [ErrInvalidCredentials] when the ID is unknown, and checks the
+// secret of keys against the hash with the given hasher.
+//
+// Example usage:
+//
+//	middleware.APIKeyAuth("api", middleware.VerifyAPIKey(hasher,
+//	    func(ctx context.Context, id string) (*models.Client, []byte, error) {
+//	        client, err := clients.FindByKeyID(ctx, id)
+//
+//	        if err != nil {
+//	            return nil, nil, middleware.ErrInvalidCredentials
+//	        }
+//
+//	        return client, client.KeyHash, nil
+//	    },
+//	))
+func VerifyAPIKey[T any](hasher contract.Hasher, find func(ctx context.Context, id string) (T, []byte, error)) func(ctx context.Context, key string) (T, error) {
+	return func(ctx context.Context, key string) (T, error) {
+		var zero T
+
+		id, secret, ok := strings.Cut(key, ".")
+
+		if !ok || id == "" || secret == "" {
+			return zero, ErrInvalidCredentials
+		}
+
+		user, hash, err := find(ctx, id)
+
+		if err != nil {
+			return zero, err
+		}
+
+		// The hasher zeroes the value it checks, so
+		// it's given a copy of the secret.
+		matches, err := hasher.Check([]byte(secret), hash)
+
+		if err != nil {
+			return zero, err
+		}
+
+		if !matches {
+			return zero, ErrInvalidCredentials
+		}
+
+		return user, nil
+	}
+}
@@ -0,0 +1,102 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/hash"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyAuthReadsHeaderAndQuery(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(ctx context.Context, key string) (*principal, error) {
+		if key != "key" {
+			return nil, middleware.ErrInvalidCredentials
+		}
+
+		return &principal{Name: "client"}, nil
+	}
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.APIKeyAuthWith(middleware.APIKeyOptions[*principal]{
+				Realm:  "api",
+				Query:  "api_key",
+				Lookup: lookup,
+			}),
+		},
+	})
+
+	header := httptest.NewRecorder()
+	req := authRequest("")
+	req.Header.Set("X-API-Key", "key")
+	app.ServeHTTP(header, req)
+
+	query := httptest.NewRecorder()
+	app.ServeHTTP(query, httptest.NewRequest(http.MethodGet, "/me?api_key=key", nil))
+
+	missing := httptest.NewRecorder()
+	app.ServeHTTP(missing, authRequest(""))
+
+	require.Equal(t, "client", header.Body.String())
+	require.Equal(t, "client", query.Body.String())
+	require.Equal(t, http.StatusUnauthorized, missing.Code)
+	require.Equal(t, `APIKey realm="api", header="X-API-Key"`, missing.Header().Get("WWW-Authenticate"))
+}
+
+func TestAPIKeyAuthIgnoresQueryByDefault(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.APIKeyAuth("api", func(ctx context.Context, key string) (*principal, error) {
+				return &principal{Name: "client"}, nil
+			}),
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/me?api_key=key", nil))
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestVerifyAPIKeyChecksHashedSecrets(t *testing.T) {
+	t.Parallel()
+
+	hasher := hash.NewBcryptWith(hash.BcryptOptions{Cost: 4})
+	key, id, stored, err := middleware.GenerateAPIKey(hasher)
+
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, id+"."))
+	require.NotContains(t, string(stored), strings.TrimPrefix(key, id+"."))
+
+	lookup := middleware.VerifyAPIKey(hasher, func(ctx context.Context, candidate string) (*principal, []byte, error) {
+		if candidate != id {
+			return nil, nil, middleware.ErrInvalidCredentials
+		}
+
+		return &principal{Name: "client"}, stored, nil
+	})
+
+	user, err := lookup(t.Context(), key)
+
+	require.NoError(t, err)
+	require.Equal(t, "client", user.Name)
+
+	_, err = lookup(t.Context(), id+".wrong")
+	require.ErrorIs(t, err, middleware.ErrInvalidCredentials)
+
+	_, err = lookup(t.Context(), "unknown."+strings.TrimPrefix(key, id+"."))
+	require.ErrorIs(t, err, middleware.ErrInvalidCredentials)
+
+	_, err = lookup(t.Context(), "malformed")
+	require.ErrorIs(t, err, middleware.ErrInvalidCredentials)
+}
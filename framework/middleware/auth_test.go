@@ -0,0 +1,225 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/stretchr/testify/require"
+)
+
+type principal struct {
+	Name string
+}
+
+func findByPassword(ctx context.Context, username string, password string) (*principal, error) {
+	if username == "admin" && subtle.ConstantTimeCompare([]byte(password), []byte("secret")) == 1 {
+		return &principal{Name: username}, nil
+	}
+
+	return nil, middleware.ErrInvalidCredentials
+}
+
+func findByToken(ctx context.Context, token string) (*principal, error) {
+	switch token {
+	case "valid":
+		return &principal{Name: "token"}, nil
+	case "failing":
+		return nil, errors.New("database down")
+	default:
+		return nil, middleware.ErrInvalidCredentials
+	}
+}
+
+func authApp(options middleware.AuthenticateOptions[*principal]) *framework.Router {
+	app := framework.New()
+	app.Use(middleware.AuthenticateWith(options))
+	app.Get("/me", func(w http.ResponseWriter, r *http.Request) error {
+		user, ok := request.User[*principal](r)
+
+		if !ok {
+			return response.String(w, http.StatusOK, "guest")
+		}
+
+		return response.String(w, http.StatusOK, user.Name)
+	})
+
+	return app
+}
+
+func authRequest(header string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+
+	return req
+}
+
+func TestAuthenticateStoresPrincipal(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.BearerAuth("api", findByToken),
+			middleware.BasicAuth("api", findByPassword),
+		},
+	})
+
+	bearer := httptest.NewRecorder()
+	app.ServeHTTP(bearer, authRequest("Bearer valid"))
+
+	basic := httptest.NewRecorder()
+	req := authRequest("")
+	req.SetBasicAuth("admin", "secret")
+	app.ServeHTTP(basic, req)
+
+	require.Equal(t, http.StatusOK, bearer.Code)
+	require.Equal(t, "token", bearer.Body.String())
+	require.Equal(t, http.StatusOK, basic.Code)
+	require.Equal(t, "admin", basic.Body.String())
+}
+
+func TestAuthenticateChallengesMissingCredentials(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.BearerAuth("api", findByToken),
+			middleware.BasicAuth("api", findByPassword),
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, authRequest(""))
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Equal(t, []string{
+		`Bearer realm="api"`,
+		`Basic realm="api", charset="UTF-8"`,
+	}, rec.Header().Values("WWW-Authenticate"))
+	require.Contains(t, rec.Body.String(), "Unauthorized")
+}
+
+func TestAuthenticateRejectsInvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.BearerAuth("api", findByToken),
+			middleware.BasicAuth("api", findByPassword),
+		},
+	})
+
+	bearer := httptest.NewRecorder()
+	app.ServeHTTP(bearer, authRequest("Bearer invalid"))
+
+	basic := httptest.NewRecorder()
+	req := authRequest("")
+	req.SetBasicAuth("admin", "wrong")
+	app.ServeHTTP(basic, req)
+
+	malformed := httptest.NewRecorder()
+	app.ServeHTTP(malformed, authRequest("Basic not-base64"))
+
+	require.Equal(t, http.StatusUnauthorized, bearer.Code)
+	require.Equal(t, `Bearer realm="api", error="invalid_token"`, bearer.Header().Values("WWW-Authenticate")[0])
+	require.Equal(t, http.StatusUnauthorized, basic.Code)
+	require.Equal(t, `Bearer realm="api"`, basic.Header().Values("WWW-Authenticate")[0])
+	require.Equal(t, http.StatusUnauthorized, malformed.Code)
+}
+
+func TestAuthenticateWrapsCause(t *testing.T) {
+	t.Parallel()
+
+	var captured error
+
+	handler := middleware.Authenticate(middleware.BearerAuth("api", findByToken))(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	captured = handler(httptest.NewRecorder(), authRequest("Bearer invalid"))
+
+	require.ErrorIs(t, captured, middleware.ErrInvalidCredentials)
+
+	captured = handler(httptest.NewRecorder(), authRequest(""))
+
+	require.ErrorIs(t, captured, middleware.ErrNoCredentials)
+}
+
+func TestAuthenticateReturnsLookupFailures(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.BearerAuth("api", findByToken),
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, authRequest("Bearer failing"))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Empty(t, rec.Header().Values("WWW-Authenticate"))
+}
+
+func TestAuthenticateLetsGuestsThroughWhenOptional(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.BearerAuth("api", findByToken),
+		},
+		Optional: true,
+	})
+
+	guest := httptest.NewRecorder()
+	app.ServeHTTP(guest, authRequest(""))
+
+	invalid := httptest.NewRecorder()
+	app.ServeHTTP(invalid, authRequest("Bearer invalid"))
+
+	require.Equal(t, http.StatusOK, guest.Code)
+	require.Equal(t, "guest", guest.Body.String())
+	require.Equal(t, http.StatusUnauthorized, invalid.Code)
+}
+
+func TestAuthenticatorFuncAdaptsFunctions(t *testing.T) {
+	t.Parallel()
+
+	app := authApp(middleware.AuthenticateOptions[*principal]{
+		Authenticators: []middleware.Authenticator[*principal]{
+			middleware.AuthenticatorFunc[*principal]{
+				Func: func(r *http.Request) (*principal, error) {
+					if r.Header.Get("X-Internal") == "" {
+						return nil, middleware.ErrNoCredentials
+					}
+
+					return &principal{Name: "internal"}, nil
+				},
+				Scheme: `Internal realm="mesh"`,
+			},
+		},
+	})
+
+	authenticated := httptest.NewRecorder()
+	req := authRequest("")
+	req.Header.Set("X-Internal", "1")
+	app.ServeHTTP(authenticated, req)
+
+	rejected := httptest.NewRecorder()
+	app.ServeHTTP(rejected, authRequest(""))
+
+	require.Equal(t, "internal", authenticated.Body.String())
+	require.Equal(t, `Internal realm="mesh"`, rejected.Header().Get("WWW-Authenticate"))
+}
@@ -0,0 +1,291 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/problem"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrRequestTooLarge is the error returned when reading a request
+// body that exceeds the configured size limit. It uses HTTP 413
+// Content Too Large per RFC 9110 and wraps [request.ErrBodyTooLarge],
+// so handlers can match it with [errors.Is] just like the errors
+// returned by the size-limited body reading functions.
+var ErrRequestTooLarge = problem.Problem{
+	Title:  "Content Too Large",
+	Detail: "The request body exceeds the maximum allowed size.",
+	Status: http.StatusRequestEntityTooLarge,
+}.WithError(request.ErrBodyTooLarge)
+
+// ErrUnsupportedEncoding is the error returned when a request body
+// uses a content coding that is not supported. It uses HTTP 415
+// Unsupported Media Type per RFC 9110.
+var ErrUnsupportedEncoding = problem.Problem{
+	Title:  "Unsupported Media Type",
+	Detail: "The request content encoding is not supported.",
+	Status: http.StatusUnsupportedMediaType,
+}
+
+// ErrMalformedEncoding is the error returned when a compressed
+// request body can't be decoded.
+var ErrMalformedEncoding = problem.Problem{
+	Title:  "Bad Request",
+	Detail: "The request body could not be decoded.",
+	Status: http.StatusBadRequest,
+}
+
+// BodyLimitOptions configures the request body limit middleware.
+type BodyLimitOptions struct {
+	// MaxSize is the maximum size in bytes of the request body as
+	// received, before decompression. Defaults to
+	// [request.DefaultMaxBodySize].
+	MaxSize int64
+
+	// MaxDecompressedSize is the maximum size in bytes of a
+	// compressed request body once decoded, protecting against
+	// decompression bombs. Defaults to MaxSize.
+	MaxDecompressedSize int64
+
+	// Encodings lists the request content codings that are
+	// transparently decoded. Supported values are "gzip" and
+	// "zstd". Defaults to both.
+	Encodings []string
+
+	// DisableDecompression leaves compressed request bodies
+	// untouched, only enforcing MaxSize.
+	DisableDecompression bool
+}
+
+// DefaultBodyLimitOptions holds sensible defaults: bodies up to
+// [request.DefaultMaxBodySize], both compressed and decompressed,
+// with gzip and zstd decoding.
+var DefaultBodyLimitOptions = BodyLimitOptions{
+	MaxSize:             request.DefaultMaxBodySize,
+	MaxDecompressedSize: request.DefaultMaxBodySize,
+	Encodings:           []string{"gzip", "zstd"},
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultBodyLimitOptions] fields.
+func (options BodyLimitOptions) withDefaults() BodyLimitOptions {
+	if options.MaxSize == 0 {
+		options.MaxSize = DefaultBodyLimitOptions.MaxSize
+	}
+
+	if options.MaxDecompressedSize == 0 {
+		options.MaxDecompressedSize = options.MaxSize
+	}
+
+	if len(options.Encodings) == 0 {
+		options.Encodings = DefaultBodyLimitOptions.Encodings
+	}
+
+	return options
+}
+
+// BodyLimit returns middleware that limits request bodies to the
+// given size in bytes using the [DefaultBodyLimitOptions] otherwise.
+func BodyLimit(maxSize int64) framework.Middleware {
+	options := DefaultBodyLimitOptions
+	options.MaxSize = maxSize
+	options.MaxDecompressedSize = maxSize
+
+	return BodyLimitWith(options)
+}
+
+// BodyLimitWith returns middleware that limits the size of request
+// bodies and transparently decodes gzip and zstd encoded ones. Reading
+// past the limit fails with [ErrRequestTooLarge], which handlers can
+// return as is to answer with a 413 problem. Requests with an unknown
+// Content-Encoding are rejected with [ErrUnsupportedEncoding].
+//
+// The limit applies to handlers that read [http.Request.Body]
+// directly as well as to the request body helpers. When nested,
+// the innermost middleware replaces the limits of the outer ones,
+// so that a route group can raise or lower the application limit:
+//
+//	app.Use(middleware.BodyLimit(1 << 20))
+//	app.With(middleware.BodyLimit(100 << 20)).Post("/uploads", upload)
+func BodyLimitWith(options BodyLimitOptions) framework.Middleware {
+	options = options.withDefaults()
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if r.Body == nil || r.Body == http.NoBody {
+				return next(w, r)
+			}
+
+			raw, encoding := r.Body, strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+
+			// An outer body limit already replaced the body and
+			// consumed the encoding, so take over its source.
+			if limited, ok := r.Body.(*limitedBody); ok {
+				raw, encoding = limited.raw, limited.encoding
+			}
+
+			if encoding == "x-gzip" {
+				encoding = "gzip"
+			}
+
+			if encoding == "identity" || options.DisableDecompression {
+				encoding = ""
+			}
+
+			if encoding != "" && !slices.Contains(options.Encodings, encoding) {
+				return ErrUnsupportedEncoding
+			}
+
+			if r.ContentLength > options.MaxSize {
+				return ErrRequestTooLarge
+			}
+
+			r = r.Clone(r.Context())
+			r.Body = &limitedBody{
+				raw:      raw,
+				encoding: encoding,
+				options:  options,
+			}
+
+			if encoding != "" {
+				r.Header.Del("Content-Encoding")
+				r.Header.Del("Content-Length")
+				r.ContentLength = -1
+			}
+
+			return next(w, r)
+		}
+	}
+}
+
+// limitedBody is a request body limited in size that decodes
+// its content coding on the first read.
+type limitedBody struct {
+	// raw stores the original request body.
+	raw io.ReadCloser
+
+	// encoding stores the content coding of the raw body, or an
+	// empty string when it's not decoded.
+	encoding string
+
+	// options stores the middleware options.
+	options BodyLimitOptions
+
+	// reader stores the limited and decoded reader once opened.
+	reader io.Reader
+
+	// decoder stores the decoder of the content coding
+	// once opened, if any.
+	decoder io.Closer
+}
+
+// Read reads from the limited and decoded body.
+func (body *limitedBody) Read(content []byte) (int, error) {
+	if body.reader == nil {
+		if err := body.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := body.reader.Read(content)
+
+	if err != nil && err != io.EOF && !errors.Is(err, request.ErrBodyTooLarge) && body.decoder != nil {
+		return n, ErrMalformedEncoding.WithError(err)
+	}
+
+	return n, err
+}
+
+// Close closes the decoder, if any, and the original body.
+func (body *limitedBody) Close() error {
+	if body.decoder != nil {
+		_ = body.decoder.Close()
+	}
+
+	return body.raw.Close()
+}
+
+// open creates the limited reader, decoding the
+// content coding of the body if needed.
+func (body *limitedBody) open() error {
+	raw := &limitReader{reader: body.raw, remaining: body.options.MaxSize}
+
+	switch body.encoding {
+	case "gzip":
+		decoder, err := gzip.NewReader(raw)
+
+		if err != nil {
+			if errors.Is(err, request.ErrBodyTooLarge) {
+				return err
+			}
+
+			return ErrMalformedEncoding.WithError(err)
+		}
+
+		body.decoder = decoder
+		body.reader = &limitReader{reader: decoder, remaining: body.options.MaxDecompressedSize}
+	case "zstd":
+		decoder, err := zstd.NewReader(
+			raw,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(uint64(body.options.MaxDecompressedSize)+1),
+		)
+
+		if err != nil {
+			return ErrMalformedEncoding.WithError(err)
+		}
+
+		body.decoder = decoder.IOReadCloser()
+		body.reader = &limitReader{reader: decoder, remaining: body.options.MaxDecompressedSize}
+	default:
+		body.reader = raw
+	}
+
+	return nil
+}
+
+// limitReader reads from a reader until the given number of bytes
+// is exceeded, failing with [ErrRequestTooLarge] afterwards.
+type limitReader struct {
+	// reader stores the limited reader.
+	reader io.Reader
+
+	// remaining stores the number of bytes that can still be
+	// read, or -1 once the limit was exceeded.
+	remaining int64
+}
+
+// Read reads from the underlying reader, failing once more bytes
+// than allowed were read.
+func (limiter *limitReader) Read(content []byte) (int, error) {
+	if limiter.remaining < 0 {
+		return 0, ErrRequestTooLarge
+	}
+
+	// Read one more byte than remaining to detect bodies
+	// that exceed the limit rather than just reaching it.
+	if int64(len(content)) > limiter.remaining+1 {
+		content = content[:limiter.remaining+1]
+	}
+
+	n, err := limiter.reader.Read(content)
+
+	if int64(n) > limiter.remaining {
+		n = int(limiter.remaining)
+		limiter.remaining = -1
+
+		return n, ErrRequestTooLarge
+	}
+
+	limiter.remaining -= int64(n)
+
+	return n, err
+}
@@ -0,0 +1,227 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func echoHandler(w http.ResponseWriter, r *http.Request) error {
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		return err
+	}
+
+	return response.Raw(w, http.StatusOK, body)
+}
+
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(content))
+
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func bodyRequest(body []byte, encoding string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	return req
+}
+
+func TestBodyLimitAllowsBodiesWithinTheLimit(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.BodyLimit(5)(echoHandler)
+	res := handler.Record(bodyRequest([]byte("hello"), ""))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "hello", string(body))
+}
+
+func TestBodyLimitRejectsDeclaredLargeBodies(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	handler := middleware.BodyLimit(4)(func(w http.ResponseWriter, r *http.Request) error {
+		called = true
+
+		return nil
+	})
+
+	res := handler.Record(bodyRequest([]byte("hello"), ""))
+
+	require.False(t, called)
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
+
+func TestBodyLimitRejectsStreamedLargeBodies(t *testing.T) {
+	t.Parallel()
+
+	var readErr error
+
+	handler := middleware.BodyLimit(4)(func(w http.ResponseWriter, r *http.Request) error {
+		_, readErr = io.ReadAll(r.Body)
+
+		return readErr
+	})
+
+	req := bodyRequest([]byte("hello"), "")
+	req.ContentLength = -1
+	res := handler.Record(req)
+
+	require.ErrorIs(t, readErr, request.ErrBodyTooLarge)
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
+
+func TestBodyLimitAppliesToBodyHelpers(t *testing.T) {
+	t.Parallel()
+
+	var decodeErr error
+
+	handler := middleware.BodyLimit(8)(func(w http.ResponseWriter, r *http.Request) error {
+		_, decodeErr = request.JSON[map[string]string](r)
+
+		return decodeErr
+	})
+
+	req := bodyRequest([]byte(`{"name":"cosmos"}`), "")
+	req.ContentLength = -1
+	res := handler.Record(req)
+
+	require.ErrorIs(t, decodeErr, request.ErrBodyTooLarge)
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
+
+func TestBodyLimitDecompressesGzip(t *testing.T) {
+	t.Parallel()
+
+	var encoding string
+
+	handler := middleware.BodyLimit(1024)(func(w http.ResponseWriter, r *http.Request) error {
+		encoding = r.Header.Get("Content-Encoding")
+
+		return echoHandler(w, r)
+	})
+
+	res := handler.Record(bodyRequest(gzipped(t, "hello cosmos"), "gzip"))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Empty(t, encoding)
+	require.Equal(t, "hello cosmos", string(body))
+}
+
+func TestBodyLimitDecompressesZstd(t *testing.T) {
+	t.Parallel()
+
+	encoder, err := zstd.NewWriter(nil)
+
+	require.NoError(t, err)
+
+	compressed := encoder.EncodeAll([]byte("hello cosmos"), nil)
+
+	require.NoError(t, encoder.Close())
+
+	res := middleware.BodyLimit(1024)(echoHandler).Record(bodyRequest(compressed, "zstd"))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "hello cosmos", string(body))
+}
+
+func TestBodyLimitRejectsDecompressionBombs(t *testing.T) {
+	t.Parallel()
+
+	compressed := gzipped(t, strings.Repeat("a", 1<<20))
+	handler := middleware.BodyLimitWith(middleware.BodyLimitOptions{
+		MaxSize:             1024 * 8,
+		MaxDecompressedSize: 1024,
+	})(echoHandler)
+
+	res := handler.Record(bodyRequest(compressed, "gzip"))
+
+	require.Less(t, len(compressed), 1024*8)
+	require.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+}
+
+func TestBodyLimitRejectsUnsupportedEncodings(t *testing.T) {
+	t.Parallel()
+
+	res := middleware.BodyLimit(1024)(echoHandler).Record(bodyRequest([]byte("hello"), "br"))
+
+	require.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+}
+
+func TestBodyLimitRejectsMalformedEncodings(t *testing.T) {
+	t.Parallel()
+
+	res := middleware.BodyLimit(1024)(echoHandler).Record(bodyRequest([]byte("not gzip"), "gzip"))
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestBodyLimitCanDisableDecompression(t *testing.T) {
+	t.Parallel()
+
+	compressed := gzipped(t, "hello cosmos")
+	handler := middleware.BodyLimitWith(middleware.BodyLimitOptions{
+		MaxSize:              1024,
+		DisableDecompression: true,
+	})(echoHandler)
+
+	res := handler.Record(bodyRequest(compressed, "gzip"))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, compressed, body)
+}
+
+func TestBodyLimitInnermostLimitWins(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Use(middleware.BodyLimit(4))
+	app.Post("/small", echoHandler)
+	app.With(middleware.BodyLimit(1024)).Post("/large", echoHandler)
+
+	small := httptest.NewRecorder()
+	app.ServeHTTP(small, httptest.NewRequest(http.MethodPost, "/small", strings.NewReader("hello")))
+
+	large := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/large", bytes.NewReader(gzipped(t, "hello")))
+	req.Header.Set("Content-Encoding", "gzip")
+	req.ContentLength = -1
+	app.ServeHTTP(large, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, small.Code)
+	require.Equal(t, http.StatusOK, large.Code)
+	require.Equal(t, "hello", large.Body.String())
+}
@@ -0,0 +1,480 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/studiolambda/cosmos/framework"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressOptions configures the response compression middleware.
+type CompressOptions struct {
+	// Encodings lists the supported content codings in order of
+	// preference, used to break ties between codings the client
+	// accepts with the same quality. Supported values are "zstd",
+	// "gzip" and "deflate". Defaults to all of them in that order.
+	Encodings []string
+
+	// MinSize is the minimum response size in bytes worth
+	// compressing. Smaller responses are sent uncompressed unless
+	// they are flushed before reaching it. Defaults to 1024.
+	MinSize int
+
+	// ContentTypes lists the compressible media types. Entries may
+	// use a "type/*" wildcard or a "type/*+suffix" structured
+	// syntax suffix wildcard. Defaults to text, JSON, XML,
+	// JavaScript and SVG media types.
+	ContentTypes []string
+}
+
+// DefaultCompressOptions holds sensible defaults: zstd, gzip and
+// deflate for textual responses of at least 1 KB.
+var DefaultCompressOptions = CompressOptions{
+	Encodings: []string{"zstd", "gzip", "deflate"},
+	MinSize:   1024,
+	ContentTypes: []string{
+		"text/*",
+		"application/json",
+		"application/*+json",
+		"application/x-ndjson",
+		"application/xml",
+		"application/*+xml",
+		"application/javascript",
+		"image/svg+xml",
+	},
+}
+
+// compressEncoder is implemented by the pooled encoders of every
+// supported content coding.
+type compressEncoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressPools stores a pool of encoders for every supported
+// content coding, as creating them allocates large buffers.
+var compressPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	"deflate": {New: func() any {
+		return zlib.NewWriter(io.Discard)
+	}},
+	"zstd": {New: func() any {
+		encoder, err := zstd.NewWriter(io.Discard, zstdOptions...)
+
+		if err != nil {
+			panic("compress: creating zstd encoder: " + err.Error())
+		}
+
+		return encoder
+	}},
+}
+
+// zstdOptions configures the pooled zstd encoders. Each one encodes a
+// single response at a time, so it runs on the calling goroutine with
+// a 1 MiB window rather than the library defaults of a goroutine per
+// CPU and an 8 MiB window, which are costly to keep for every
+// concurrent response.
+var zstdOptions = []zstd.EOption{
+	zstd.WithEncoderConcurrency(1),
+	zstd.WithWindowSize(1 << 20),
+	zstd.WithEncoderLevel(zstd.SpeedDefault),
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultCompressOptions] fields.
+func (options CompressOptions) withDefaults() CompressOptions {
+	if len(options.Encodings) == 0 {
+		options.Encodings = DefaultCompressOptions.Encodings
+	}
+
+	if options.MinSize == 0 {
+		options.MinSize = DefaultCompressOptions.MinSize
+	}
+
+	if len(options.ContentTypes) == 0 {
+		options.ContentTypes = DefaultCompressOptions.ContentTypes
+	}
+
+	return options
+}
+
+// Compress returns middleware that compresses responses using
+// [DefaultCompressOptions].
+func Compress() framework.Middleware {
+	return CompressWith(DefaultCompressOptions)
+}
+
+// CompressWith returns middleware that compresses responses with
+// the content coding negotiated from the Accept-Encoding request
+// header, honouring its quality values. Only responses with a
+// compressible content type of at least [CompressOptions.MinSize]
+// bytes are compressed, and responses that already have a
+// Content-Encoding or a "no-transform" Cache-Control are left
+// untouched. Compressed responses have their Content-Length removed
+// and strong ETags weakened. Vary: Accept-Encoding is always set.
+//
+// The compression happens beneath the [framework.ResponseWriter]
+// hooks, so BeforeWrite hooks still see the uncompressed bytes, and
+// flushing keeps working for [response.Stream] and [response.SSE].
+//
+// It panics if an encoding is not supported, as it's a programming
+// error.
+func CompressWith(options CompressOptions) framework.Middleware {
+	options = options.withDefaults()
+
+	for _, encoding := range options.Encodings {
+		if _, ok := compressPools[encoding]; !ok {
+			panic("compress: unsupported encoding " + strconv.Quote(encoding))
+		}
+	}
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) (err error) {
+			addVary(w.Header(), "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Values("Accept-Encoding"), options.Encodings)
+
+			if encoding == "" || r.Method == http.MethodHead {
+				return next(w, r)
+			}
+
+			compressor := &compressWriter{
+				options:  options,
+				encoding: encoding,
+			}
+
+			target, restore := compressTarget(w, compressor)
+			completed := false
+
+			defer func() {
+				restore()
+
+				// A panicking handler that did not send its headers yet
+				// leaves the response to the middleware recovering it,
+				// such as [Recover], which writes it uncompressed.
+				if !completed && !compressor.decided {
+					return
+				}
+
+				if closeErr := compressor.close(); err == nil {
+					err = closeErr
+				}
+			}()
+
+			err = next(target, r)
+			completed = true
+
+			return err
+		}
+	}
+}
+
+// compressTarget installs the compressor beneath the hooks of the
+// given writer when it's a [framework.ResponseWriter], returning the
+// writer handlers must use and a function that uninstalls it.
+func compressTarget(w http.ResponseWriter, compressor *compressWriter) (http.ResponseWriter, func()) {
+	switch writer := w.(type) {
+	case *framework.ResponseWriterFlusher:
+		inner, flusher := writer.ResponseWriter.ResponseWriter, writer.Flusher
+		compressor.ResponseWriter = inner
+		writer.ResponseWriter.ResponseWriter, writer.Flusher = compressor, compressor
+
+		return w, func() {
+			writer.ResponseWriter.ResponseWriter, writer.Flusher = inner, flusher
+		}
+	case *framework.ResponseWriter:
+		inner := writer.ResponseWriter
+		compressor.ResponseWriter = inner
+		writer.ResponseWriter = compressor
+
+		return w, func() {
+			writer.ResponseWriter = inner
+		}
+	default:
+		compressor.ResponseWriter = w
+
+		return compressor, func() {}
+	}
+}
+
+// compressWriter buffers the beginning of a response until it can
+// decide whether to compress it, and then writes it through the
+// encoder of the negotiated content coding.
+type compressWriter struct {
+	http.ResponseWriter
+
+	// options stores the middleware options.
+	options CompressOptions
+
+	// encoding stores the negotiated content coding.
+	encoding string
+
+	// encoder stores the encoder once the response is being
+	// compressed, nil otherwise.
+	encoder compressEncoder
+
+	// buffer stores the bytes written before deciding.
+	buffer []byte
+
+	// status stores the status code written by the handler.
+	status int
+
+	// decided reports whether the headers were written and the
+	// compression decided.
+	decided bool
+
+	// streaming reports whether the response was flushed
+	// before deciding, which ignores the minimum size.
+	streaming bool
+}
+
+// WriteHeader records the status code, which is written once the
+// compression is decided.
+func (writer *compressWriter) WriteHeader(status int) {
+	if writer.decided || writer.status != 0 {
+		return
+	}
+
+	writer.status = status
+}
+
+// Write buffers the content until the minimum size is reached and
+// writes it through the encoder afterwards.
+func (writer *compressWriter) Write(content []byte) (int, error) {
+	if writer.status == 0 {
+		writer.status = http.StatusOK
+	}
+
+	if writer.decided {
+		if writer.encoder != nil {
+			return writer.encoder.Write(content)
+		}
+
+		return writer.ResponseWriter.Write(content)
+	}
+
+	writer.buffer = append(writer.buffer, content...)
+
+	if len(writer.buffer) >= writer.options.MinSize {
+		if err := writer.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(content), nil
+}
+
+// Flush decides the compression if needed, regardless of the
+// minimum size, and flushes both the encoder and the underlying
+// writer.
+func (writer *compressWriter) Flush() {
+	if writer.status == 0 {
+		writer.status = http.StatusOK
+	}
+
+	if !writer.decided {
+		writer.streaming = true
+
+		if err := writer.decide(); err != nil {
+			return
+		}
+	}
+
+	if writer.encoder != nil {
+		_ = writer.encoder.Flush()
+	}
+
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying [http.ResponseWriter].
+func (writer *compressWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// decide writes the headers, compressing the response when it's
+// eligible, and writes the buffered content.
+func (writer *compressWriter) decide() error {
+	writer.decided = true
+	header := writer.Header()
+
+	if header.Get("Content-Type") == "" && len(writer.buffer) > 0 {
+		header.Set("Content-Type", http.DetectContentType(writer.buffer))
+	}
+
+	if writer.compressible() {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", writer.encoding)
+
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		writer.encoder = compressPools[writer.encoding].Get().(compressEncoder)
+		writer.encoder.Reset(writer.ResponseWriter)
+	}
+
+	writer.ResponseWriter.WriteHeader(writer.status)
+
+	buffer := writer.buffer
+	writer.buffer = nil
+
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	if writer.encoder != nil {
+		_, err := writer.encoder.Write(buffer)
+
+		return err
+	}
+
+	_, err := writer.ResponseWriter.Write(buffer)
+
+	return err
+}
+
+// compressible reports whether the response is eligible
+// for compression.
+func (writer *compressWriter) compressible() bool {
+	header := writer.Header()
+
+	switch {
+	case writer.status < http.StatusOK,
+		writer.status == http.StatusNoContent,
+		writer.status == http.StatusPartialContent,
+		writer.status == http.StatusNotModified:
+		return false
+	case header.Get("Content-Encoding") != "",
+		header.Get("Content-Range") != "",
+		strings.Contains(header.Get("Cache-Control"), "no-transform"):
+		return false
+	case !writer.streaming && len(writer.buffer) < writer.options.MinSize:
+		return false
+	}
+
+	media, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+
+	if err != nil {
+		return false
+	}
+
+	return slices.ContainsFunc(writer.options.ContentTypes, func(pattern string) bool {
+		return mediaMatches(pattern, media)
+	})
+}
+
+// close finishes the response: it decides the compression if the
+// handler wrote anything without reaching the minimum size, and
+// closes the encoder, returning it to its pool.
+func (writer *compressWriter) close() error {
+	if !writer.decided {
+		if writer.status == 0 {
+			return nil
+		}
+
+		if err := writer.decide(); err != nil {
+			return err
+		}
+	}
+
+	if writer.encoder == nil {
+		return nil
+	}
+
+	err := writer.encoder.Close()
+	writer.encoder.Reset(io.Discard)
+	compressPools[writer.encoding].Put(writer.encoder)
+	writer.encoder = nil
+
+	return err
+}
+
+// negotiateEncoding returns the supported content coding with the
+// highest quality in the given Accept-Encoding header values, using
+// the order of the supported codings to break ties. It returns an
+// empty string when the client accepts none of them.
+func negotiateEncoding(values []string, supported []string) string {
+	qualities := make(map[string]float64)
+
+	for _, value := range values {
+		for entry := range strings.SplitSeq(value, ",") {
+			coding, parameters, _ := strings.Cut(entry, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+
+			if coding == "" {
+				continue
+			}
+
+			quality := 1.0
+
+			if name, param, ok := strings.Cut(strings.TrimSpace(parameters), "="); ok && strings.TrimSpace(name) == "q" {
+				if q, err := strconv.ParseFloat(strings.TrimSpace(param), 64); err == nil {
+					quality = q
+				}
+			}
+
+			qualities[coding] = quality
+		}
+	}
+
+	best, bestQuality := "", 0.0
+
+	for _, coding := range supported {
+		quality, ok := qualities[coding]
+
+		if !ok {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+
+	return best
+}
+
+// mediaMatches reports whether the given media type matches the
+// pattern, which may be exact, "type/*" or "type/*+suffix".
+func mediaMatches(pattern string, media string) bool {
+	if pattern == media {
+		return true
+	}
+
+	prefix, suffix, ok := strings.Cut(pattern, "/*")
+
+	if !ok || !strings.HasPrefix(media, prefix+"/") {
+		return false
+	}
+
+	return suffix == "" || strings.HasSuffix(media, suffix)
+}
+
+// addVary adds the given header name to the Vary header unless
+// it's already listed.
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for listed := range strings.SplitSeq(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), name) {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", name)
+}
@@ -0,0 +1,254 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+var compressBody = strings.Repeat(`{"name":"cosmos"}`, 100)
+
+func compressHandler(body string) framework.Handler {
+	return middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Length", "1700")
+
+		return response.String(w, http.StatusOK, body)
+	})
+}
+
+func compressRequest(encoding string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", encoding)
+
+	return req
+}
+
+func TestCompressGzip(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(compressBody).Record(compressRequest("gzip"))
+	reader, err := gzip.NewReader(res.Body)
+
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(reader)
+
+	require.NoError(t, err)
+	require.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", res.Header.Get("Vary"))
+	require.Empty(t, res.Header.Get("Content-Length"))
+	require.Equal(t, compressBody, string(body))
+}
+
+func TestCompressDeflate(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(compressBody).Record(compressRequest("deflate"))
+	reader, err := zlib.NewReader(res.Body)
+
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(reader)
+
+	require.NoError(t, err)
+	require.Equal(t, "deflate", res.Header.Get("Content-Encoding"))
+	require.Equal(t, compressBody, string(body))
+}
+
+func TestCompressZstd(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(compressBody).Record(compressRequest("zstd"))
+	reader, err := zstd.NewReader(res.Body)
+
+	require.NoError(t, err)
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+
+	require.NoError(t, err)
+	require.Equal(t, "zstd", res.Header.Get("Content-Encoding"))
+	require.Equal(t, compressBody, string(body))
+}
+
+func TestCompressZstdBoundsTheWindowSize(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(strings.Repeat(compressBody, 1000)).Record(compressRequest("zstd"))
+	data, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+
+	var header zstd.Header
+
+	require.NoError(t, header.Decode(data))
+	require.LessOrEqual(t, header.WindowSize, uint64(1<<20))
+}
+
+func TestCompressNegotiatesQualityValues(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(compressBody).Record(compressRequest("gzip;q=0.5, deflate;q=0.8, zstd;q=0"))
+
+	require.Equal(t, "deflate", res.Header.Get("Content-Encoding"))
+}
+
+func TestCompressPrefersServerOrderOnTies(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(compressBody).Record(compressRequest("gzip, *"))
+
+	require.Equal(t, "zstd", res.Header.Get("Content-Encoding"))
+}
+
+func TestCompressSkipsUnacceptedEncodings(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler(compressBody).Record(compressRequest("br, gzip;q=0"))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", res.Header.Get("Vary"))
+	require.Equal(t, compressBody, string(body))
+}
+
+func TestCompressSkipsSmallResponses(t *testing.T) {
+	t.Parallel()
+
+	res := compressHandler("small").Record(compressRequest("gzip"))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+	require.Equal(t, "small", string(body))
+}
+
+func TestCompressSkipsIncompressibleContentTypes(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "image/png")
+
+		return response.Raw(w, http.StatusOK, bytes.Repeat([]byte{1}, 2048))
+	})
+
+	res := handler.Record(compressRequest("gzip"))
+
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+}
+
+func TestCompressWeakensETag(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("ETag", `"abc"`)
+
+		return response.String(w, http.StatusOK, compressBody)
+	})
+
+	res := handler.Record(compressRequest("gzip"))
+
+	require.Equal(t, `W/"abc"`, res.Header.Get("ETag"))
+}
+
+func TestCompressBeforeWriteHooksSeeUncompressedBytes(t *testing.T) {
+	t.Parallel()
+
+	var seen bytes.Buffer
+
+	handler := middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		request.Hooks(r).BeforeWrite(func(w http.ResponseWriter, content []byte) {
+			seen.Write(content)
+		})
+
+		return response.String(w, http.StatusOK, compressBody)
+	})
+
+	res := handler.Record(compressRequest("gzip"))
+
+	require.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+	require.Equal(t, compressBody, seen.String())
+}
+
+func TestCompressFlushesStreams(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		events := make(chan []byte, 2)
+		events <- []byte("data: first\n\n")
+		events <- []byte("data: second\n\n")
+		close(events)
+
+		return response.SSE(w, r, events)
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, compressRequest("gzip"))
+
+	reader, err := gzip.NewReader(rec.Body)
+
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(reader)
+
+	require.NoError(t, err)
+	require.True(t, rec.Flushed)
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	require.Equal(t, "data: first\n\ndata: second\n\n", string(body))
+}
+
+func TestCompressRendersErrorsUncompressed(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		return framework.ErrNotFound
+	})
+
+	res := handler.Record(compressRequest("gzip"))
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+}
+
+func TestCompressLetsRecoverRenderPanics(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Recover()(middleware.Compress()(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain")
+
+		panic("something broke")
+	}))
+
+	req := compressRequest("gzip")
+	req.Header.Set("Accept", "application/problem+json")
+
+	res := handler.Record(req)
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+	require.Contains(t, string(body), "Internal Server Error")
+}
+
+func TestCompressPanicsOnUnsupportedEncoding(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		middleware.CompressWith(middleware.CompressOptions{Encodings: []string{"br"}})
+	})
+}
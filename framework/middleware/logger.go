@@ -17,6 +17,7 @@ import (
 // The middleware logs the following information for failed
 // requests:
 //   - HTTP method (GET, POST, etc.)
+//   - Client IP address, as resolved by [TrustedProxies]
 //   - Request URL path (query string excluded to prevent
 //     accidental logging of tokens or secrets)
 //   - HTTP status code returned
@@ -79,6 +80,7 @@ func Logger(logger *slog.Logger) framework.Middleware {
 						r.Context(),
 						"request failed",
 						"method", r.Method,
+						"ip", request.ClientIP(r),
 						"path", r.URL.Path,
 						"status", status,
 						"err", err,
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"context"
-	"net"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/studiolambda/cosmos/contract/request"
 	"github.com/studiolambda/cosmos/framework"
 	"github.com/studiolambda/cosmos/problem"
 	"golang.org/x/time/rate"
@@ -32,7 +32,8 @@ type RateLimitOptions struct {
 	Burst int
 
 	// KeyFunc extracts the rate-limit key from a request.
-	// Defaults to the client's remote address.
+	// Defaults to [request.ClientIP], which resolves the real
+	// client address behind [TrustedProxies].
 	KeyFunc func(r *http.Request) string
 
 	// ErrorResponse is the problem returned when a request is
@@ -68,18 +69,11 @@ type RateLimitOptions struct {
 var DefaultRateLimitOptions = RateLimitOptions{
 	RequestsPerSecond: 15,
 	Burst:             30,
-	KeyFunc: func(r *http.Request) string {
-		host, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			return r.RemoteAddr
-		}
-
-		return host
-	},
-	ErrorResponse:   ErrRateLimited,
-	CleanupInterval: time.Minute,
-	MaxIdleTime:     5 * time.Minute,
-	MaxEntries:      10000,
+	KeyFunc:           request.ClientIP,
+	ErrorResponse:     ErrRateLimited,
+	CleanupInterval:   time.Minute,
+	MaxIdleTime:       5 * time.Minute,
+	MaxEntries:        10000,
 }
 
 // withDefaults returns a copy of the options with zero values
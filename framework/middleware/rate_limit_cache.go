@@ -0,0 +1,372 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// RateLimitAlgorithm is the algorithm a [RateLimitRule] counts
+// requests with.
+type RateLimitAlgorithm int
+
+const (
+	// FixedWindow counts requests in consecutive windows, resetting
+	// the count at the start of each one. It's the cheapest algorithm
+	// but allows bursts of up to twice the limit around window edges.
+	FixedWindow RateLimitAlgorithm = iota
+
+	// SlidingWindow estimates the requests of the last window by
+	// weighting the count of the previous window by its overlap,
+	// which smooths bursts around window edges at the cost of an
+	// extra cache read.
+	SlidingWindow
+)
+
+// RateLimitRule is a named limit applied by [RateLimitCacheWith].
+type RateLimitRule struct {
+	// Name identifies the rule in the cache keys, such as "ip"
+	// or "user". Rules of a route must have distinct names.
+	Name string
+
+	// Limit is the number of requests allowed per window.
+	Limit int64
+
+	// Window is the duration of the window.
+	Window time.Duration
+
+	// Algorithm is the algorithm requests are counted with.
+	// Defaults to [FixedWindow].
+	Algorithm RateLimitAlgorithm
+
+	// KeyFunc extracts the key requests are counted by. Requests
+	// with an empty key are not limited by the rule, so that, for
+	// example, a per-user rule can skip guests. Defaults to
+	// [request.ClientIP].
+	KeyFunc func(r *http.Request) string
+}
+
+// RateLimitCacheOptions configures the cache backed rate limiter
+// middleware.
+type RateLimitCacheOptions struct {
+	// Cache stores the counters. It must implement
+	// [contract.CacheCounter].
+	Cache contract.CacheDriver
+
+	// Rules lists the limits every request must satisfy.
+	Rules []RateLimitRule
+
+	// Prefix is prepended to the cache keys.
+	// Defaults to "ratelimit:".
+	Prefix string
+
+	// ErrorResponse is the problem returned when a request is
+	// rate-limited. Defaults to [ErrRateLimited].
+	ErrorResponse problem.Problem
+
+	// FailOpen lets requests through when the cache fails instead
+	// of failing them with the cache error.
+	FailOpen bool
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options RateLimitCacheOptions) withDefaults() RateLimitCacheOptions {
+	if options.Prefix == "" {
+		options.Prefix = "ratelimit:"
+	}
+
+	if options.ErrorResponse.Status == 0 {
+		options.ErrorResponse = ErrRateLimited
+	}
+
+	options.Rules = slices.Clone(options.Rules)
+
+	for i, rule := range options.Rules {
+		if rule.KeyFunc == nil {
+			options.Rules[i].KeyFunc = DefaultRateLimitOptions.KeyFunc
+		}
+	}
+
+	return options
+}
+
+// RateLimitByHeader returns a [RateLimitRule.KeyFunc] that counts
+// requests by the value of the given header, such as an API key.
+// The value is hashed so secrets don't end up in the cache keys, and
+// requests without the header aren't counted by the rule.
+func RateLimitByHeader(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		value := r.Header.Get(name)
+
+		if value == "" {
+			return ""
+		}
+
+		sum := sha256.Sum256([]byte(value))
+
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// RateLimitCache returns middleware that limits requests with the
+// given rules, storing the counters in the given cache.
+func RateLimitCache(cache contract.CacheDriver, rules ...RateLimitRule) framework.Middleware {
+	return RateLimitCacheWith(RateLimitCacheOptions{
+		Cache: cache,
+		Rules: rules,
+	})
+}
+
+// RateLimitCacheWith returns middleware that limits requests using
+// counters stored in a cache shared by every replica, unlike
+// [RateLimitWith] whose limits are per process. Each request must
+// satisfy all the rules, and requests rejected by any of them don't
+// count towards the others.
+//
+// Responses include the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers of the most restrictive rule, and rejected
+// requests include a Retry-After header as well.
+//
+// Counters are updated atomically, but setting their expiration is
+// not, so a few concurrent requests at the start of a window may go
+// uncounted. It panics if the cache does not implement
+// [contract.CacheCounter] or a rule is invalid, as they're
+// programming errors.
+func RateLimitCacheWith(options RateLimitCacheOptions) framework.Middleware {
+	options = options.withDefaults()
+
+	counter, ok := options.Cache.(contract.CacheCounter)
+
+	if !ok {
+		panic("ratelimit: cache driver does not implement contract.CacheCounter")
+	}
+
+	for _, rule := range options.Rules {
+		if rule.Name == "" || rule.Limit <= 0 || rule.Window <= 0 {
+			panic(fmt.Sprintf("ratelimit: invalid rule %q", rule.Name))
+		}
+	}
+
+	limiter := &cacheRateLimiter{
+		cache:   options.Cache,
+		counter: counter,
+		options: options,
+	}
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			state, err := limiter.check(r)
+
+			if err != nil {
+				if options.FailOpen {
+					return next(w, r)
+				}
+
+				return err
+			}
+
+			if state == nil {
+				return next(w, r)
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.FormatInt(state.limit, 10))
+			header.Set("RateLimit-Remaining", strconv.FormatInt(state.remaining, 10))
+			header.Set("RateLimit-Reset", strconv.FormatInt(seconds(state.reset), 10))
+
+			if !state.allowed {
+				header.Set("Retry-After", strconv.FormatInt(max(seconds(state.reset), 1), 10))
+
+				return options.ErrorResponse
+			}
+
+			return next(w, r)
+		}
+	}
+}
+
+// cacheRateLimiter counts requests in a cache.
+type cacheRateLimiter struct {
+	// cache stores the counters.
+	cache contract.CacheDriver
+
+	// counter increments the counters.
+	counter contract.CacheCounter
+
+	// options stores the middleware options.
+	options RateLimitCacheOptions
+}
+
+// rateLimitState is the state of a rule after counting a request.
+type rateLimitState struct {
+	// allowed reports whether the request is allowed.
+	allowed bool
+
+	// limit stores the limit of the rule.
+	limit int64
+
+	// remaining stores the requests left in the window.
+	remaining int64
+
+	// reset stores the time until the window resets.
+	reset time.Duration
+
+	// key stores the counter the request was counted in.
+	key string
+}
+
+// check counts the request in every rule and returns the state of
+// the most restrictive one, or nil when no rule applies. When a rule
+// rejects the request, it's uncounted from the others.
+func (limiter *cacheRateLimiter) check(r *http.Request) (*rateLimitState, error) {
+	ctx := r.Context()
+	now := time.Now()
+
+	var result *rateLimitState
+	var counted []string
+
+	for _, rule := range limiter.options.Rules {
+		key := rule.KeyFunc(r)
+
+		if key == "" {
+			continue
+		}
+
+		state, err := limiter.count(ctx, rule, key, now)
+
+		if err != nil {
+			limiter.uncount(ctx, counted)
+
+			return nil, err
+		}
+
+		counted = append(counted, state.key)
+
+		if !state.allowed {
+			limiter.uncount(ctx, counted)
+
+			return state, nil
+		}
+
+		if result == nil || state.remaining < result.remaining {
+			result = state
+		}
+	}
+
+	return result, nil
+}
+
+// count counts the request in the window of the given rule
+// and key and returns the resulting state.
+func (limiter *cacheRateLimiter) count(ctx context.Context, rule RateLimitRule, key string, now time.Time) (*rateLimitState, error) {
+	window := now.UnixNano() / int64(rule.Window)
+	start := time.Unix(0, window*int64(rule.Window))
+	elapsed := now.Sub(start)
+	prefix := limiter.options.Prefix + rule.Name + ":" + key + ":"
+	current := prefix + strconv.FormatInt(window, 10)
+
+	// Sliding windows need the previous count, so
+	// counters must outlive their own window.
+	ttl := rule.Window
+
+	if rule.Algorithm == SlidingWindow {
+		ttl *= 2
+	}
+
+	count, err := limiter.increment(ctx, current, ttl-elapsed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := &rateLimitState{
+		limit: rule.Limit,
+		reset: rule.Window - elapsed,
+		key:   current,
+	}
+
+	if rule.Algorithm == SlidingWindow {
+		previous, err := limiter.get(ctx, prefix+strconv.FormatInt(window-1, 10))
+
+		if err != nil {
+			return nil, err
+		}
+
+		weight := 1 - float64(elapsed)/float64(rule.Window)
+		estimated := int64(math.Ceil(float64(previous)*weight)) + count
+
+		state.allowed = estimated <= rule.Limit
+		state.remaining = max(rule.Limit-estimated, 0)
+
+		if previous > 0 && count < rule.Limit {
+			// The previous window weighs less over time, so a request is
+			// allowed again once its weighted count leaves enough room.
+			room := float64(rule.Limit-count) / float64(previous)
+			state.reset = max(time.Duration((1-room)*float64(rule.Window))-elapsed, 0)
+		}
+
+		return state, nil
+	}
+
+	state.allowed = count <= rule.Limit
+	state.remaining = max(rule.Limit-count, 0)
+
+	return state, nil
+}
+
+// increment increments the given counter, creating it with the
+// given expiration when it's new.
+func (limiter *cacheRateLimiter) increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	count, err := limiter.counter.Increment(ctx, key, 1)
+
+	// Some drivers create missing counters on increment, like Redis,
+	// but without expiration, while others fail, so the expiration
+	// is set on the first increment in both cases.
+	if errors.Is(err, contract.ErrCacheKeyNotFound) || (err == nil && count == 1) {
+		return 1, limiter.cache.Put(ctx, key, []byte("1"), ttl)
+	}
+
+	return count, err
+}
+
+// get returns the value of the given counter, or zero
+// when it does not exist.
+func (limiter *cacheRateLimiter) get(ctx context.Context, key string) (int64, error) {
+	raw, err := limiter.cache.Get(ctx, key)
+
+	if errors.Is(err, contract.ErrCacheKeyNotFound) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(raw), 10, 64)
+}
+
+// uncount decrements the given counters, as the request they
+// counted was rejected. Failures are ignored, as they only make
+// the limits stricter.
+func (limiter *cacheRateLimiter) uncount(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		_, _ = limiter.counter.Decrement(ctx, key, 1)
+	}
+}
+
+// seconds returns the given duration in whole seconds, rounded up.
+func seconds(duration time.Duration) int64 {
+	return int64(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,190 @@
+package middleware_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/framework/cache"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type counterCacheMock struct {
+	*mock.CacheDriverMock
+	*mock.CacheCounterMock
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) error {
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+func limitedRequest(remoteAddr string, apiKey string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	return req
+}
+
+func TestRateLimitCacheFixedWindow(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.RateLimitCache(cache.NewMemory(time.Hour, time.Hour), middleware.RateLimitRule{
+		Name:   "ip",
+		Limit:  2,
+		Window: time.Hour,
+	})(okHandler)
+
+	first := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+	second := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+	third := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+	other := handler.Record(limitedRequest("10.0.0.2:1234", ""))
+
+	require.Equal(t, http.StatusOK, first.StatusCode)
+	require.Equal(t, "2", first.Header.Get("RateLimit-Limit"))
+	require.Equal(t, "1", first.Header.Get("RateLimit-Remaining"))
+	require.Equal(t, http.StatusOK, second.StatusCode)
+	require.Equal(t, "0", second.Header.Get("RateLimit-Remaining"))
+	require.Equal(t, http.StatusTooManyRequests, third.StatusCode)
+	require.NotEmpty(t, third.Header.Get("Retry-After"))
+	require.Equal(t, http.StatusOK, other.StatusCode)
+
+	reset, err := strconv.Atoi(third.Header.Get("RateLimit-Reset"))
+
+	require.NoError(t, err)
+	require.LessOrEqual(t, reset, 3600)
+}
+
+func TestRateLimitCacheSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.RateLimitCache(cache.NewMemory(time.Hour, time.Hour), middleware.RateLimitRule{
+		Name:      "ip",
+		Limit:     1,
+		Window:    time.Hour,
+		Algorithm: middleware.SlidingWindow,
+	})(okHandler)
+
+	first := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+	second := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+
+	require.Equal(t, http.StatusOK, first.StatusCode)
+	require.Equal(t, http.StatusTooManyRequests, second.StatusCode)
+}
+
+func TestRateLimitCacheSlidingWindowWeighsPreviousWindow(t *testing.T) {
+	t.Parallel()
+
+	window := time.Hour
+	previous := time.Now().UnixNano()/int64(window) - 1
+	memory := cache.NewMemory(time.Hour, time.Hour)
+
+	require.NoError(t, memory.Put(t.Context(), "ratelimit:ip:10.0.0.1:"+strconv.FormatInt(previous, 10), []byte("1000000000"), time.Hour))
+
+	handler := middleware.RateLimitCache(memory, middleware.RateLimitRule{
+		Name:      "ip",
+		Limit:     100,
+		Window:    window,
+		Algorithm: middleware.SlidingWindow,
+	})(okHandler)
+
+	res := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+}
+
+func TestRateLimitCacheMultipleRules(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.RateLimitCache(
+		cache.NewMemory(time.Hour, time.Hour),
+		middleware.RateLimitRule{Name: "ip", Limit: 10, Window: time.Hour},
+		middleware.RateLimitRule{Name: "key", Limit: 1, Window: time.Hour, KeyFunc: middleware.RateLimitByHeader("X-API-Key")},
+	)(okHandler)
+
+	first := handler.Record(limitedRequest("10.0.0.1:1234", "secret"))
+	second := handler.Record(limitedRequest("10.0.0.1:1234", "secret"))
+	guest := handler.Record(limitedRequest("10.0.0.1:1234", ""))
+
+	require.Equal(t, http.StatusOK, first.StatusCode)
+	require.Equal(t, "1", first.Header.Get("RateLimit-Limit"))
+	require.Equal(t, http.StatusTooManyRequests, second.StatusCode)
+	require.Equal(t, http.StatusOK, guest.StatusCode)
+	require.Equal(t, "10", guest.Header.Get("RateLimit-Limit"))
+	require.Equal(t, "8", guest.Header.Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitByHeaderHashesValues(t *testing.T) {
+	t.Parallel()
+
+	key := middleware.RateLimitByHeader("X-API-Key")
+
+	sum := sha256.Sum256([]byte("secret"))
+
+	require.Equal(t, hex.EncodeToString(sum[:]), key(limitedRequest("10.0.0.1:1234", "secret")))
+	require.Empty(t, key(limitedRequest("10.0.0.1:1234", "")))
+}
+
+func TestRateLimitCacheSharesCountersBetweenInstances(t *testing.T) {
+	t.Parallel()
+
+	memory := cache.NewMemory(time.Hour, time.Hour)
+	rule := middleware.RateLimitRule{Name: "ip", Limit: 1, Window: time.Hour}
+	first := middleware.RateLimitCache(memory, rule)(okHandler)
+	second := middleware.RateLimitCache(memory, rule)(okHandler)
+
+	require.Equal(t, http.StatusOK, first.Record(limitedRequest("10.0.0.1:1234", "")).StatusCode)
+	require.Equal(t, http.StatusTooManyRequests, second.Record(limitedRequest("10.0.0.1:1234", "")).StatusCode)
+}
+
+func TestRateLimitCacheCreatesRedisStyleCountersWithExpiration(t *testing.T) {
+	t.Parallel()
+
+	driver := counterCacheMock{mock.NewCacheDriverMock(t), mock.NewCacheCounterMock(t)}
+	driver.CacheCounterMock.On("Increment", tmock.Anything, tmock.Anything, int64(1)).Return(int64(1), nil)
+	driver.CacheDriverMock.On("Put", tmock.Anything, tmock.Anything, []byte("1"), tmock.AnythingOfType("time.Duration")).Return(nil)
+
+	handler := middleware.RateLimitCache(driver, middleware.RateLimitRule{Name: "ip", Limit: 1, Window: time.Minute})(okHandler)
+
+	require.Equal(t, http.StatusOK, handler.Record(limitedRequest("10.0.0.1:1234", "")).StatusCode)
+}
+
+func TestRateLimitCacheReturnsCacheErrors(t *testing.T) {
+	t.Parallel()
+
+	driver := counterCacheMock{mock.NewCacheDriverMock(t), mock.NewCacheCounterMock(t)}
+	driver.CacheCounterMock.On("Increment", tmock.Anything, tmock.Anything, int64(1)).Return(int64(0), errors.New("connection refused"))
+
+	rule := middleware.RateLimitRule{Name: "ip", Limit: 1, Window: time.Minute}
+	closed := middleware.RateLimitCache(driver, rule)(okHandler)
+	open := middleware.RateLimitCacheWith(middleware.RateLimitCacheOptions{
+		Cache:    driver,
+		Rules:    []middleware.RateLimitRule{rule},
+		FailOpen: true,
+	})(okHandler)
+
+	require.Equal(t, http.StatusInternalServerError, closed.Record(limitedRequest("10.0.0.1:1234", "")).StatusCode)
+	require.Equal(t, http.StatusOK, open.Record(limitedRequest("10.0.0.1:1234", "")).StatusCode)
+}
+
+func TestRateLimitCachePanicsWithoutCounter(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		middleware.RateLimitCache(mock.NewCacheDriverMock(t), middleware.RateLimitRule{Name: "ip", Limit: 1, Window: time.Minute})
+	})
+}
@@ -0,0 +1,474 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// ErrTimeout is the default error returned when a handler does not
+// complete before its deadline. It uses HTTP 503 Service Unavailable.
+var ErrTimeout = problem.Problem{
+	Title:  "Service Unavailable",
+	Detail: "The request took too long to complete.",
+	Status: http.StatusServiceUnavailable,
+}
+
+// ErrGatewayTimeout is an alternative to [ErrTimeout] that uses HTTP
+// 504 Gateway Timeout, meant for handlers that mostly wait on an
+// upstream service.
+var ErrGatewayTimeout = problem.Problem{
+	Title:  "Gateway Timeout",
+	Detail: "The upstream service took too long to respond.",
+	Status: http.StatusGatewayTimeout,
+}
+
+// TimeoutOptions configures the timeout middleware.
+type TimeoutOptions struct {
+	// Duration is the time handlers have to complete, measured
+	// from the moment the outermost timeout middleware runs. A
+	// non-positive duration disables the timeout.
+	Duration time.Duration
+
+	// Problem is the error returned once the deadline is exceeded.
+	// Defaults to [ErrTimeout].
+	Problem problem.Problem
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options TimeoutOptions) withDefaults() TimeoutOptions {
+	if options.Problem.Status == 0 {
+		options.Problem = ErrTimeout
+	}
+
+	return options
+}
+
+// Timeout returns middleware that gives handlers the given duration
+// to complete, failing with [ErrTimeout] otherwise.
+func Timeout(duration time.Duration) framework.Middleware {
+	return TimeoutWith(TimeoutOptions{Duration: duration})
+}
+
+// TimeoutWith returns middleware that runs the handler with a request
+// context that expires after [TimeoutOptions.Duration]. When the
+// deadline is exceeded before the handler writes the response
+// headers, the middleware returns [TimeoutOptions.Problem] right away,
+// without waiting for the handler, and any later write of the handler
+// fails with [http.ErrHandlerTimeout]. Handlers that return the
+// [context.DeadlineExceeded] error of the expired context get the
+// same problem instead of the 499 status reserved for clients that
+// closed the request.
+//
+// Responses that already started, such as streams, can't be replaced:
+// they are cut at the deadline and the problem is only reported.
+//
+// Handlers that keep running after timing out get their own
+// [contract.Hooks] from then on, detached from the finished response,
+// whose AfterResponse callbacks run once the handler returns.
+//
+// When nested, the innermost middleware replaces the duration and
+// problem of the outer ones, still measured from the start of the
+// request, so that a route can get more time than the application
+// default, or none at all with a non-positive duration:
+//
+//	app.Use(middleware.Timeout(5 * time.Second))
+//	app.With(middleware.Timeout(time.Minute)).Post("/reports", report)
+//	app.With(middleware.Timeout(0)).Get("/events", events)
+func TimeoutWith(options TimeoutOptions) framework.Middleware {
+	options = options.withDefaults()
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if ctx, ok := r.Context().Value(timeoutKey{}).(*timeoutContext); ok {
+				ctx.reset(options)
+
+				return next(w, r)
+			}
+
+			if options.Duration <= 0 {
+				return next(w, r)
+			}
+
+			ctx := newTimeoutContext(r.Context(), options)
+			defer ctx.cancel()
+
+			writer := &timeoutWriter{
+				ResponseWriter: w,
+				header:         w.Header().Clone(),
+			}
+
+			done := make(chan timeoutResult, 1)
+
+			go func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						done <- timeoutResult{panicked: true, recovered: recovered}
+					}
+				}()
+
+				done <- timeoutResult{err: next(writer, r.WithContext(ctx))}
+			}()
+
+			select {
+			case result := <-done:
+				if result.panicked {
+					panic(result.recovered)
+				}
+
+				if ctx.expired() && errors.Is(result.err, context.DeadlineExceeded) {
+					return ctx.problem().WithError(result.err)
+				}
+
+				return result.err
+			case <-ctx.expiry:
+				writer.timeout()
+				hooks := ctx.detach()
+
+				go func() {
+					result := <-done
+
+					if result.panicked {
+						slog.ErrorContext(
+							r.Context(),
+							"handler panicked after timing out",
+							"method", r.Method,
+							"path", r.URL.Path,
+							"error", result.recovered,
+						)
+					}
+
+					for _, callback := range hooks.AfterResponseFuncs() {
+						callback(result.err)
+					}
+				}()
+
+				return ctx.problem().WithError(context.DeadlineExceeded)
+			}
+		}
+	}
+}
+
+// timeoutKey is the context key the [timeoutContext] of the
+// outermost timeout middleware is found with.
+type timeoutKey struct{}
+
+// timeoutResult is the outcome of a handler run by the
+// timeout middleware.
+type timeoutResult struct {
+	// err stores the error returned by the handler.
+	err error
+
+	// panicked reports whether the handler panicked.
+	panicked bool
+
+	// recovered stores the value the handler panicked with.
+	recovered any
+}
+
+// timeoutContext is a request context with a deadline that can be
+// reset, so that nested timeout middleware can both shorten and
+// extend it.
+type timeoutContext struct {
+	context.Context
+
+	// mutex guards the mutable fields below.
+	mutex sync.Mutex
+
+	// start stores when the outermost middleware ran.
+	start time.Time
+
+	// deadline stores the current deadline, or the zero
+	// time when the timeout is disabled.
+	deadline time.Time
+
+	// options stores the options of the innermost middleware.
+	options TimeoutOptions
+
+	// timer expires the context at the deadline.
+	timer *time.Timer
+
+	// stop detaches the context from its parent.
+	stop func() bool
+
+	// done is closed once the context is done.
+	done chan struct{}
+
+	// expiry is closed once the deadline is exceeded.
+	expiry chan struct{}
+
+	// err stores the error of the context once done.
+	err error
+
+	// hooks stores the hooks handlers get once the middleware
+	// returned after the deadline, nil until then.
+	hooks *contract.Hooks
+}
+
+// newTimeoutContext creates a [timeoutContext] with the given
+// parent that expires after the duration of the given options.
+func newTimeoutContext(parent context.Context, options TimeoutOptions) *timeoutContext {
+	ctx := &timeoutContext{
+		Context: parent,
+		start:   time.Now(),
+		options: options,
+		done:    make(chan struct{}),
+		expiry:  make(chan struct{}),
+	}
+
+	ctx.deadline = ctx.start.Add(options.Duration)
+	ctx.timer = time.AfterFunc(options.Duration, ctx.expire)
+	ctx.stop = context.AfterFunc(parent, func() {
+		ctx.finish(parent.Err())
+	})
+
+	return ctx
+}
+
+// Deadline returns the earliest of the current deadline
+// and the deadline of the parent context.
+func (ctx *timeoutContext) Deadline() (time.Time, bool) {
+	ctx.mutex.Lock()
+	deadline := ctx.deadline
+	ctx.mutex.Unlock()
+
+	parent, ok := ctx.Context.Deadline()
+
+	if deadline.IsZero() || (ok && parent.Before(deadline)) {
+		return parent, ok
+	}
+
+	return deadline, true
+}
+
+// Done returns a channel that's closed once the deadline is
+// exceeded or the parent context is done.
+func (ctx *timeoutContext) Done() <-chan struct{} {
+	return ctx.done
+}
+
+// Err returns [context.DeadlineExceeded] once the deadline is
+// exceeded, the error of the parent once it's done, or nil.
+func (ctx *timeoutContext) Err() error {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	return ctx.err
+}
+
+// Value returns the context itself for the timeout key, the
+// detached hooks for the hooks key once the handler timed out,
+// and delegates any other key to the parent.
+func (ctx *timeoutContext) Value(key any) any {
+	if key == (timeoutKey{}) {
+		return ctx
+	}
+
+	if key == contract.HooksKey {
+		ctx.mutex.Lock()
+		hooks := ctx.hooks
+		ctx.mutex.Unlock()
+
+		if hooks != nil {
+			return hooks
+		}
+	}
+
+	return ctx.Context.Value(key)
+}
+
+// reset replaces the options of the context, moving the deadline
+// to the new duration after the start. It does nothing once the
+// context is done.
+func (ctx *timeoutContext) reset(options TimeoutOptions) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if ctx.err != nil {
+		return
+	}
+
+	ctx.options = options
+
+	if options.Duration <= 0 {
+		ctx.deadline = time.Time{}
+		ctx.timer.Stop()
+
+		return
+	}
+
+	ctx.deadline = ctx.start.Add(options.Duration)
+	ctx.timer.Reset(time.Until(ctx.deadline))
+}
+
+// expire marks the context as expired unless its deadline
+// was moved or removed in the meantime.
+func (ctx *timeoutContext) expire() {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if ctx.err != nil || ctx.deadline.IsZero() || time.Now().Before(ctx.deadline) {
+		return
+	}
+
+	ctx.err = context.DeadlineExceeded
+	close(ctx.expiry)
+	close(ctx.done)
+}
+
+// finish marks the context as done with the given error.
+func (ctx *timeoutContext) finish(err error) {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if ctx.err != nil {
+		return
+	}
+
+	ctx.err = err
+	close(ctx.done)
+}
+
+// detach replaces the hooks handlers get with new ones, so that a
+// handler running after its response was finished can't register
+// callbacks on it, and returns them.
+func (ctx *timeoutContext) detach() *contract.Hooks {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	ctx.hooks = contract.NewHooks()
+
+	return ctx.hooks
+}
+
+// cancel releases the resources of the context.
+func (ctx *timeoutContext) cancel() {
+	ctx.timer.Stop()
+	ctx.stop()
+	ctx.finish(context.Canceled)
+}
+
+// expired reports whether the deadline was exceeded.
+func (ctx *timeoutContext) expired() bool {
+	select {
+	case <-ctx.expiry:
+		return true
+	default:
+		return false
+	}
+}
+
+// problem returns the problem of the innermost middleware.
+func (ctx *timeoutContext) problem() problem.Problem {
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	return ctx.options.Problem
+}
+
+// timeoutWriter guards the response writer of a handler run by the
+// timeout middleware, discarding its writes once timed out. The
+// handler gets its own header map, so that it can't race with the
+// error response written after the timeout.
+type timeoutWriter struct {
+	http.ResponseWriter
+
+	// mutex guards the fields below and the writes.
+	mutex sync.Mutex
+
+	// header stores the headers set by the handler.
+	header http.Header
+
+	// wroteHeader reports whether the headers were written.
+	wroteHeader bool
+
+	// timedOut reports whether the handler timed out.
+	timedOut bool
+}
+
+// Header returns the header map of the handler.
+func (writer *timeoutWriter) Header() http.Header {
+	return writer.header
+}
+
+// WriteHeader writes the headers of the handler and the
+// status code unless it timed out.
+func (writer *timeoutWriter) WriteHeader(status int) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if writer.timedOut || writer.wroteHeader {
+		return
+	}
+
+	writer.writeHeader(status)
+}
+
+// Write writes the content unless the handler timed out, in
+// which case it fails with [http.ErrHandlerTimeout].
+func (writer *timeoutWriter) Write(content []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if writer.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !writer.wroteHeader {
+		writer.writeHeader(http.StatusOK)
+	}
+
+	return writer.ResponseWriter.Write(content)
+}
+
+// Flush flushes the underlying writer unless the handler timed out.
+func (writer *timeoutWriter) Flush() {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if writer.timedOut {
+		return
+	}
+
+	if !writer.wroteHeader {
+		writer.writeHeader(http.StatusOK)
+	}
+
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying [http.ResponseWriter].
+func (writer *timeoutWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// writeHeader copies the headers of the handler to the
+// underlying writer and writes the status code.
+func (writer *timeoutWriter) writeHeader(status int) {
+	writer.wroteHeader = true
+	header := writer.ResponseWriter.Header()
+
+	clear(header)
+	maps.Copy(header, writer.header.Clone())
+
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+// timeout discards any further write of the handler.
+func (writer *timeoutWriter) timeout() {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	writer.timedOut = true
+}
@@ -0,0 +1,260 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sleeping(duration time.Duration) framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		select {
+		case <-time.After(duration):
+			return response.String(w, http.StatusOK, "done")
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+}
+
+func TestTimeoutPassesFastHandlers(t *testing.T) {
+	t.Parallel()
+
+	res := middleware.Timeout(time.Second)(sleeping(0)).Record(httptest.NewRequest(http.MethodGet, "/", nil))
+	body, err := io.ReadAll(res.Body)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "done", string(body))
+}
+
+func TestTimeoutSetsTheContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	var deadline time.Time
+	var ok bool
+
+	handler := middleware.Timeout(time.Minute)(func(w http.ResponseWriter, r *http.Request) error {
+		deadline, ok = r.Context().Deadline()
+
+		return nil
+	})
+
+	handler.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+func TestTimeoutRendersProblemForSlowHandlers(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	res := middleware.Timeout(10 * time.Millisecond)(sleeping(time.Second)).Record(req)
+
+	var body map[string]any
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.Equal(t, "Service Unavailable", body["title"])
+}
+
+func TestTimeoutDoesNotWaitForHandlersIgnoringTheContext(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	handler := middleware.Timeout(10 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) error {
+		<-release
+
+		return response.String(w, http.StatusOK, "late")
+	})
+
+	start := time.Now()
+	res := handler.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
+
+func TestTimeoutSuppressesLateWrites(t *testing.T) {
+	t.Parallel()
+
+	written := make(chan error, 1)
+	handler := middleware.Timeout(10 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("X-Late", "true")
+		_, err := w.Write([]byte("late"))
+		written <- err
+
+		return err
+	})
+
+	res := handler.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.ErrorIs(t, <-written, http.ErrHandlerTimeout)
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.Empty(t, res.Header.Get("X-Late"))
+}
+
+func TestTimeoutMapsExpiredContextErrors(t *testing.T) {
+	t.Parallel()
+
+	var returned error
+
+	handler := middleware.Timeout(10 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+
+		return r.Context().Err()
+	})
+
+	renderer := framework.ErrorHandlerWith(framework.ErrorHandlerOptions{
+		Reporter: func(r *http.Request, err error, status int) {
+			returned = err
+		},
+	})
+
+	res := renderer.Middleware()(handler).Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.ErrorIs(t, returned, context.DeadlineExceeded)
+}
+
+func TestTimeoutKeepsClientCancellations(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	res := middleware.Timeout(time.Second)(sleeping(time.Second)).Record(httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	require.Equal(t, framework.StatusClientClosedRequest, res.StatusCode)
+}
+
+func TestTimeoutUsesTheConfiguredProblem(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.TimeoutWith(middleware.TimeoutOptions{
+		Duration: 10 * time.Millisecond,
+		Problem:  middleware.ErrGatewayTimeout,
+	})(sleeping(time.Second))
+
+	res := handler.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusGatewayTimeout, res.StatusCode)
+}
+
+func TestTimeoutCutsStartedStreams(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Timeout(20 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) error {
+		if err := response.String(w, http.StatusOK, "partial"); err != nil {
+			return err
+		}
+
+		<-r.Context().Done()
+
+		return r.Context().Err()
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "partial", rec.Body.String())
+}
+
+func TestTimeoutInnermostDurationWins(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Use(middleware.Timeout(10 * time.Millisecond))
+	app.Get("/short", sleeping(50*time.Millisecond))
+	app.With(middleware.Timeout(time.Second)).Get("/long", sleeping(50*time.Millisecond))
+	app.With(middleware.Timeout(0)).Get("/none", sleeping(50*time.Millisecond))
+
+	for path, status := range map[string]int{
+		"/short": http.StatusServiceUnavailable,
+		"/long":  http.StatusOK,
+		"/none":  http.StatusOK,
+	} {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, status, rec.Code, path)
+	}
+}
+
+func TestTimeoutPropagatesPanics(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.Timeout(time.Second)(func(w http.ResponseWriter, r *http.Request) error {
+		panic(errors.New("boom"))
+	})
+
+	res := middleware.Recover()(handler).Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+}
+
+func TestTimeoutDetachesHooksOfTimedOutHandlers(t *testing.T) {
+	t.Parallel()
+
+	var original *contract.Hooks
+
+	returned := make(chan struct{})
+	late := make(chan error, 1)
+
+	handler := middleware.Timeout(10 * time.Millisecond)(func(w http.ResponseWriter, r *http.Request) error {
+		<-returned
+
+		hooks := request.Hooks(r)
+		hooks.BeforeWrite(func(w http.ResponseWriter, content []byte) {})
+		hooks.AfterResponse(func(err error) {
+			late <- err
+		})
+
+		return r.Context().Err()
+	})
+
+	outer := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		original = request.Hooks(r)
+
+		return handler(w, r)
+	})
+
+	res := outer.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+	afterResponse := len(original.AfterResponseFuncs())
+	beforeWrite := len(original.BeforeWriteFuncs())
+
+	close(returned)
+
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	select {
+	case err := <-late:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("expected the late AfterResponse hook to run")
+	}
+
+	require.Len(t, original.AfterResponseFuncs(), afterResponse)
+	require.Len(t, original.BeforeWriteFuncs(), beforeWrite)
+}
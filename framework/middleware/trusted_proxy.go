@@ -0,0 +1,373 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework"
+)
+
+// LoopbackProxies lists the loopback ranges, where proxies running
+// on the same host live.
+var LoopbackProxies = []string{
+	"127.0.0.0/8",
+	"::1/128",
+}
+
+// PrivateProxies lists the loopback and private network ranges,
+// where proxies and load balancers usually live. They're not trusted
+// by default since any host on the private network could then forge
+// the forwarding headers; pass them explicitly to opt in.
+var PrivateProxies = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// TrustedProxyOptions configures the trusted proxy middleware.
+type TrustedProxyOptions struct {
+	// Proxies lists the trusted proxies as IP addresses or CIDR
+	// ranges, such as "10.0.0.0/8". Forwarding headers are only
+	// honoured when the request comes from one of them.
+	Proxies []string
+
+	// Headers lists the forwarding headers that are honoured, in
+	// order of preference. Supported values are "Forwarded",
+	// "X-Forwarded-For", which is used along with X-Forwarded-Proto
+	// and X-Forwarded-Host, and "X-Real-IP". Defaults to all of them
+	// in that order.
+	Headers []string
+}
+
+// DefaultTrustedProxyOptions holds sensible defaults: proxies in
+// [LoopbackProxies] honouring every supported forwarding header.
+var DefaultTrustedProxyOptions = TrustedProxyOptions{
+	Proxies: LoopbackProxies,
+	Headers: []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"},
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultTrustedProxyOptions] fields.
+func (options TrustedProxyOptions) withDefaults() TrustedProxyOptions {
+	if len(options.Proxies) == 0 {
+		options.Proxies = DefaultTrustedProxyOptions.Proxies
+	}
+
+	if len(options.Headers) == 0 {
+		options.Headers = DefaultTrustedProxyOptions.Headers
+	}
+
+	return options
+}
+
+// TrustedProxies returns middleware that resolves the client of the
+// requests coming from the given proxies using the
+// [DefaultTrustedProxyOptions] otherwise.
+func TrustedProxies(proxies ...string) framework.Middleware {
+	options := DefaultTrustedProxyOptions
+	options.Proxies = proxies
+
+	return TrustedProxiesWith(options)
+}
+
+// TrustedProxiesWith returns middleware that resolves the IP address,
+// scheme and host the client used from the forwarding headers set by
+// trusted proxies, and stores them in the request context as a
+// [contract.Client]. Read them with [request.ClientIP],
+// [request.Scheme] and [request.Host].
+//
+// The chain of addresses in the forwarding headers is walked from the
+// nearest proxy backwards, and the first address that's not a trusted
+// proxy is the client, so that clients can't spoof their address by
+// sending the headers themselves. Requests that don't come from a
+// trusted proxy resolve to the connection values.
+//
+// It panics if a proxy is not a valid IP address or CIDR range, as
+// it's a programming error.
+func TrustedProxiesWith(options TrustedProxyOptions) framework.Middleware {
+	options = options.withDefaults()
+	resolver := &proxyResolver{headers: options.Headers}
+
+	for _, proxy := range options.Proxies {
+		resolver.proxies = append(resolver.proxies, parseProxy(proxy))
+	}
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			ctx := context.WithValue(r.Context(), contract.ClientKey, resolver.resolve(r))
+
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// proxyResolver resolves the client of requests.
+type proxyResolver struct {
+	// proxies stores the trusted proxy ranges.
+	proxies []netip.Prefix
+
+	// headers stores the honoured forwarding headers.
+	headers []string
+}
+
+// proxyHop is an entry in the chain of forwarding headers, describing
+// the request a proxy received.
+type proxyHop struct {
+	// ip stores the address the proxy received the request from.
+	ip netip.Addr
+
+	// scheme stores the scheme the proxy received the request with.
+	scheme string
+
+	// host stores the host the proxy received the request for.
+	host string
+}
+
+// resolve returns the client of the given request.
+func (resolver *proxyResolver) resolve(r *http.Request) contract.Client {
+	client := contract.Client{
+		IP:     r.RemoteAddr,
+		Scheme: "http",
+		Host:   r.Host,
+	}
+
+	if r.TLS != nil {
+		client.Scheme = "https"
+	}
+
+	remote, ok := parseProxyAddr(r.RemoteAddr)
+
+	if !ok {
+		return client
+	}
+
+	client.IP = remote.String()
+
+	if !resolver.trusted(remote) {
+		return client
+	}
+
+	hops := resolver.hops(r)
+
+	// Walk the chain from the nearest proxy backwards until an
+	// address that's not trusted is found, which is the client.
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hops[i]
+
+		if !hop.ip.IsValid() {
+			break
+		}
+
+		client.IP = hop.ip.String()
+
+		if hop.scheme == "http" || hop.scheme == "https" {
+			client.Scheme = hop.scheme
+		}
+
+		if validForwardedHost(hop.host) {
+			client.Host = hop.host
+		}
+
+		if !resolver.trusted(hop.ip) {
+			break
+		}
+	}
+
+	return client
+}
+
+// hops returns the chain of the first honoured forwarding
+// header present in the request.
+func (resolver *proxyResolver) hops(r *http.Request) []proxyHop {
+	for _, header := range resolver.headers {
+		switch http.CanonicalHeaderKey(header) {
+		case "Forwarded":
+			if values := r.Header.Values("Forwarded"); len(values) > 0 {
+				return forwardedHops(values)
+			}
+		case "X-Forwarded-For":
+			if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+				return xForwardedHops(
+					splitHeaderList(values),
+					splitHeaderList(r.Header.Values("X-Forwarded-Proto")),
+					splitHeaderList(r.Header.Values("X-Forwarded-Host")),
+				)
+			}
+		case "X-Real-Ip":
+			if value := r.Header.Get("X-Real-IP"); value != "" {
+				return xForwardedHops(
+					[]string{value},
+					splitHeaderList(r.Header.Values("X-Forwarded-Proto")),
+					splitHeaderList(r.Header.Values("X-Forwarded-Host")),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// trusted reports whether the given address is a trusted proxy.
+func (resolver *proxyResolver) trusted(ip netip.Addr) bool {
+	return slices.ContainsFunc(resolver.proxies, func(proxy netip.Prefix) bool {
+		return proxy.Contains(ip)
+	})
+}
+
+// forwardedHops returns the chain of the given Forwarded header
+// values as defined by RFC 7239.
+func forwardedHops(values []string) []proxyHop {
+	hops := make([]proxyHop, 0)
+
+	for _, element := range splitQuoted(strings.Join(values, ","), ',') {
+		hop := proxyHop{}
+
+		for _, pair := range splitQuoted(element, ';') {
+			name, value, _ := strings.Cut(pair, "=")
+			value = strings.TrimSpace(value)
+
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "for":
+				hop.ip, _ = parseProxyAddr(value)
+			case "proto":
+				hop.scheme = strings.ToLower(value)
+			case "host":
+				hop.host = value
+			}
+		}
+
+		hops = append(hops, hop)
+	}
+
+	return hops
+}
+
+// xForwardedHops returns the chain of the given X-Forwarded-For
+// addresses. Schemes and hosts are matched to the addresses by
+// position when every proxy appended one, or the last one is used
+// for all of them otherwise, as it was set by the nearest proxy.
+func xForwardedHops(addresses []string, schemes []string, hosts []string) []proxyHop {
+	hops := make([]proxyHop, len(addresses))
+
+	for i, address := range addresses {
+		hops[i].ip, _ = parseProxyAddr(address)
+		hops[i].scheme = strings.ToLower(alignedValue(schemes, i, len(addresses)))
+		hops[i].host = alignedValue(hosts, i, len(addresses))
+	}
+
+	return hops
+}
+
+// alignedValue returns the value at the given position when there's
+// a value per address, or the last value otherwise.
+func alignedValue(values []string, i int, addresses int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	if len(values) == addresses {
+		return values[i]
+	}
+
+	return values[len(values)-1]
+}
+
+// splitHeaderList splits the given comma-separated header
+// values into their trimmed, non-empty entries.
+func splitHeaderList(values []string) []string {
+	entries := make([]string, 0)
+
+	for _, value := range values {
+		for entry := range strings.SplitSeq(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries
+}
+
+// splitQuoted splits the given value on the separator,
+// ignoring separators within quoted strings.
+func splitQuoted(value string, separator byte) []string {
+	parts := make([]string, 0)
+	quoted, escaped, start := false, false, 0
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case value[i] == '\\' && quoted:
+			escaped = true
+		case value[i] == '"':
+			quoted = !quoted
+		case value[i] == separator && !quoted:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// parseProxyAddr parses an IP address that may include a port and
+// IPv6 brackets, as found in RemoteAddr and forwarding headers.
+func parseProxyAddr(value string) (netip.Addr, bool) {
+	value = strings.TrimSpace(value)
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+
+	ip, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"))
+
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ip.Unmap(), true
+}
+
+// parseProxy parses a trusted proxy IP address or CIDR range.
+func parseProxy(proxy string) netip.Prefix {
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+
+		if err != nil {
+			panic("proxy: invalid trusted proxy " + strconv.Quote(proxy))
+		}
+
+		return prefix.Masked()
+	}
+
+	ip, err := netip.ParseAddr(proxy)
+
+	if err != nil {
+		panic("proxy: invalid trusted proxy " + strconv.Quote(proxy))
+	}
+
+	ip = ip.Unmap()
+
+	return netip.PrefixFrom(ip, ip.BitLen())
+}
+
+// validForwardedHost reports whether the given forwarded host is
+// a plausible host, rejecting values that could alter URLs built
+// from it.
+func validForwardedHost(host string) bool {
+	return host != "" && !strings.ContainsAny(host, "/\\@?# \t\"")
+}
@@ -0,0 +1,181 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/middleware"
+
+	"github.com/stretchr/testify/require"
+)
+
+func resolveClient(proxies framework.Middleware, remoteAddr string, headers map[string]string) (string, string, string) {
+	var ip, scheme, host string
+
+	handler := proxies(func(w http.ResponseWriter, r *http.Request) error {
+		ip, scheme, host = request.ClientIP(r), request.Scheme(r), request.Host(r)
+
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://internal/", nil)
+	req.RemoteAddr = remoteAddr
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	handler.Record(req)
+
+	return ip, scheme, host
+}
+
+func TestTrustedProxiesResolvesXForwardedHeaders(t *testing.T) {
+	t.Parallel()
+
+	ip, scheme, host := resolveClient(middleware.TrustedProxies("10.0.0.0/8"), "10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For":   "203.0.113.7",
+		"X-Forwarded-Proto": "https",
+		"X-Forwarded-Host":  "example.com",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+	require.Equal(t, "https", scheme)
+	require.Equal(t, "example.com", host)
+}
+
+func TestTrustedProxiesResolvesForwardedHeader(t *testing.T) {
+	t.Parallel()
+
+	ip, scheme, host := resolveClient(middleware.TrustedProxies("10.0.0.0/8"), "10.0.0.1:1234", map[string]string{
+		"Forwarded": `for="[2001:db8:cafe::17]:4711";proto=https;host=example.com, for=10.0.0.2`,
+	})
+
+	require.Equal(t, "2001:db8:cafe::17", ip)
+	require.Equal(t, "https", scheme)
+	require.Equal(t, "example.com", host)
+}
+
+func TestTrustedProxiesResolvesXRealIP(t *testing.T) {
+	t.Parallel()
+
+	ip, _, _ := resolveClient(middleware.TrustedProxies("10.0.0.1"), "10.0.0.1:1234", map[string]string{
+		"X-Real-IP": "203.0.113.7",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+}
+
+func TestTrustedProxiesSkipsTrustedHops(t *testing.T) {
+	t.Parallel()
+
+	ip, _, _ := resolveClient(middleware.TrustedProxies("10.0.0.0/8"), "10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.5",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+}
+
+func TestTrustedProxiesIgnoresHeadersFromUntrustedClients(t *testing.T) {
+	t.Parallel()
+
+	ip, scheme, host := resolveClient(middleware.TrustedProxies("10.0.0.0/8"), "203.0.113.7:1234", map[string]string{
+		"X-Forwarded-For":   "198.51.100.1",
+		"X-Forwarded-Proto": "https",
+		"X-Forwarded-Host":  "evil.com",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+	require.Equal(t, "http", scheme)
+	require.Equal(t, "internal", host)
+}
+
+func TestTrustedProxiesRejectsInvalidForwardedValues(t *testing.T) {
+	t.Parallel()
+
+	ip, scheme, host := resolveClient(middleware.TrustedProxies("10.0.0.0/8"), "10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For":   "unknown",
+		"X-Forwarded-Proto": "javascript",
+		"X-Forwarded-Host":  "evil.com/path",
+	})
+
+	require.Equal(t, "10.0.0.1", ip)
+	require.Equal(t, "http", scheme)
+	require.Equal(t, "internal", host)
+}
+
+func TestTrustedProxiesHonoursConfiguredHeaders(t *testing.T) {
+	t.Parallel()
+
+	proxies := middleware.TrustedProxiesWith(middleware.TrustedProxyOptions{
+		Proxies: []string{"10.0.0.0/8"},
+		Headers: []string{"X-Real-IP"},
+	})
+
+	ip, _, _ := resolveClient(proxies, "10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For": "198.51.100.1",
+		"X-Real-IP":       "203.0.113.7",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+}
+
+func TestTrustedProxiesDefaultsToLoopback(t *testing.T) {
+	t.Parallel()
+
+	ip, _, _ := resolveClient(middleware.TrustedProxiesWith(middleware.TrustedProxyOptions{}), "127.0.0.1:1234", map[string]string{
+		"X-Forwarded-For": "203.0.113.7",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+}
+
+func TestTrustedProxiesDoesNotTrustPrivateRangesByDefault(t *testing.T) {
+	t.Parallel()
+
+	ip, _, _ := resolveClient(middleware.TrustedProxiesWith(middleware.TrustedProxyOptions{}), "192.168.1.1:1234", map[string]string{
+		"X-Forwarded-For": "203.0.113.7",
+	})
+
+	require.Equal(t, "192.168.1.1", ip)
+}
+
+func TestTrustedProxiesTrustsPrivateRangesOnOptIn(t *testing.T) {
+	t.Parallel()
+
+	ip, _, _ := resolveClient(middleware.TrustedProxies(middleware.PrivateProxies...), "192.168.1.1:1234", map[string]string{
+		"X-Forwarded-For": "203.0.113.7",
+	})
+
+	require.Equal(t, "203.0.113.7", ip)
+}
+
+func TestTrustedProxiesPanicsOnInvalidProxies(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		middleware.TrustedProxies("not-an-ip")
+	})
+}
+
+func TestTrustedProxiesFeedRateLimitKeys(t *testing.T) {
+	t.Parallel()
+
+	handler := middleware.TrustedProxies("10.0.0.0/8")(middleware.RateLimitWith(middleware.RateLimitOptions{
+		RequestsPerSecond: 1,
+		Burst:             1,
+	})(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+
+	for _, client := range []string{"203.0.113.7", "203.0.113.8"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", client)
+
+		require.Equal(t, http.StatusNoContent, handler.Record(req).StatusCode)
+	}
+}
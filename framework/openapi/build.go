@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+// Document generates the OpenAPI document of the given routes,
+// typically obtained with [router.Router.Routes]. Routes are
+// documented even when they were not described with
+// [Spec.Describe], in which case only their path parameters and
+// the default problem response are known. CONNECT routes and
+// hidden operations are skipped.
+func (spec *Spec) Document(routes []router.RouteInfo) Document {
+	generator := newSchemas()
+	document := Document{
+		OpenAPI: Version,
+		Info:    spec.info,
+		Servers: spec.servers,
+		Paths:   make(map[string]PathItem),
+	}
+
+	named := make(map[string]int)
+
+	for _, route := range routes {
+		if route.Name != "" {
+			named[route.Name]++
+		}
+	}
+
+	for _, route := range routes {
+		operation := spec.operations[route.String()]
+
+		if operation.Hidden || route.Method == http.MethodConnect {
+			continue
+		}
+
+		path, wildcards := documentPath(route.Pattern)
+		object := operationObject(generator, operation, wildcards)
+
+		if object.OperationID == "" && route.Name != "" {
+			object.OperationID = route.Name
+
+			// Routes registered for several methods, such as with
+			// [router.Router.Any], share their name, which would
+			// otherwise produce duplicated operation IDs.
+			if named[route.Name] > 1 {
+				object.OperationID += "." + strings.ToLower(route.Method)
+			}
+		}
+
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(PathItem)
+		}
+
+		document.Paths[path][strings.ToLower(route.Method)] = object
+	}
+
+	if len(generator.components) > 0 {
+		document.Components.Schemas = generator.components
+	}
+
+	return document
+}
+
+// operationObject converts the given operation metadata into an
+// [OperationObject] whose path parameters are the given wildcards.
+func operationObject(generator *schemas, operation Operation, wildcards []string) *OperationObject {
+	object := &OperationObject{
+		OperationID: operation.ID,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Tags:        operation.Tags,
+		Deprecated:  operation.Deprecated,
+		Responses:   make(map[string]ResponseObject),
+	}
+
+	for _, wildcard := range wildcards {
+		parameter := Path(wildcard, "")
+		index := slices.IndexFunc(operation.Parameters, func(candidate Parameter) bool {
+			return candidate.Name == wildcard && (candidate.In == "" || candidate.In == "path")
+		})
+
+		if index >= 0 {
+			parameter.Description = operation.Parameters[index].Description
+			parameter.Type = operation.Parameters[index].Type
+		}
+
+		object.Parameters = append(object.Parameters, parameterObject(generator, parameter))
+	}
+
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "" || parameter.In == "path" {
+			continue
+		}
+
+		object.Parameters = append(object.Parameters, parameterObject(generator, parameter))
+	}
+
+	if operation.Request != nil {
+		object.RequestBody = &RequestBodyObject{
+			Description: operation.Request.Description,
+			Content:     contentObject(generator, *operation.Request),
+			Required:    operation.Request.Required,
+		}
+	}
+
+	if _, ok := operation.Responses[0]; !ok {
+		object.Responses["default"] = responseObject(generator, Problem(""), 0)
+	}
+
+	for status, content := range operation.Responses {
+		key := strconv.Itoa(status)
+
+		if status == 0 {
+			key = "default"
+		}
+
+		object.Responses[key] = responseObject(generator, content, status)
+	}
+
+	return object
+}
+
+// parameterObject converts the given parameter into a [ParameterObject].
+func parameterObject(generator *schemas, parameter Parameter) ParameterObject {
+	schema := &Schema{Type: "string"}
+
+	if parameter.Type != nil {
+		schema = generator.of(parameter.Type)
+	}
+
+	return ParameterObject{
+		Name:        parameter.Name,
+		In:          parameter.In,
+		Description: parameter.Description,
+		Required:    parameter.Required || parameter.In == "path",
+		Schema:      schema,
+	}
+}
+
+// responseObject converts the given content into the [ResponseObject]
+// of the given status code.
+func responseObject(generator *schemas, content Content, status int) ResponseObject {
+	description := content.Description
+
+	if description == "" {
+		description = http.StatusText(status)
+	}
+
+	if description == "" {
+		description = "Unexpected error."
+	}
+
+	return ResponseObject{
+		Description: description,
+		Content:     contentObject(generator, content),
+	}
+}
+
+// contentObject returns the media type map that describes the given
+// content, or nil when the content has no type.
+func contentObject(generator *schemas, content Content) map[string]MediaTypeObject {
+	if content.Type == nil {
+		return nil
+	}
+
+	return map[string]MediaTypeObject{
+		content.MediaType: {Schema: generator.of(content.Type)},
+	}
+}
+
+// documentPath converts the given [http.ServeMux] pattern into an
+// OpenAPI path template and returns it along with the names of its
+// wildcards. Rest wildcards such as "{path...}" become regular
+// templates, as OpenAPI has no equivalent for them.
+func documentPath(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+	wildcards := make([]string, 0)
+
+	for i, segment := range segments {
+		if inner, ok := strings.CutPrefix(segment, "{"); ok {
+			name := strings.TrimSuffix(strings.TrimSuffix(inner, "}"), "...")
+			wildcards = append(wildcards, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), wildcards
+}
@@ -0,0 +1,202 @@
+package openapi_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/openapi"
+
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+func TestDocumentIncludesInfoAndServers(t *testing.T) {
+	t.Parallel()
+
+	info := openapi.Info{Title: "Test", Version: "1.0.0"}
+	server := openapi.Server{URL: "https://api.example.com"}
+	spec := openapi.New(info, server)
+
+	document := spec.Document(nil)
+
+	require.Equal(t, openapi.Version, document.OpenAPI)
+	require.Equal(t, info, document.Info)
+	require.Equal(t, []openapi.Server{server}, document.Servers)
+	require.Empty(t, document.Paths)
+}
+
+func TestDocumentDerivesPathParameters(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Get("/users/{id}/files/{path...}", noop)
+
+	operation := spec.Document(app.Routes()).Paths["/users/{id}/files/{path}"]["get"]
+
+	require.NotNil(t, operation)
+	require.Len(t, operation.Parameters, 2)
+	require.Equal(t, "id", operation.Parameters[0].Name)
+	require.Equal(t, "path", operation.Parameters[0].In)
+	require.True(t, operation.Parameters[0].Required)
+	require.Equal(t, "string", operation.Parameters[0].Schema.Type)
+	require.Equal(t, "path", operation.Parameters[1].Name)
+}
+
+func TestDocumentIncludesRootPath(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Get("/", noop)
+
+	require.Contains(t, spec.Document(app.Routes()).Paths, "/")
+}
+
+func TestDocumentUsesDescribedPathParameter(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	spec.Describe(app.Get("/users/{id}", noop), openapi.Operation{
+		Parameters: []openapi.Parameter{
+			{Name: "id", Description: "The user ID.", Type: reflect.TypeFor[int64]()},
+			openapi.Query[int]("page", "The page number."),
+		},
+	})
+
+	operation := spec.Document(app.Routes()).Paths["/users/{id}"]["get"]
+
+	require.Len(t, operation.Parameters, 2)
+	require.Equal(t, "The user ID.", operation.Parameters[0].Description)
+	require.Equal(t, "integer", operation.Parameters[0].Schema.Type)
+	require.Equal(t, "page", operation.Parameters[1].Name)
+	require.Equal(t, "query", operation.Parameters[1].In)
+	require.False(t, operation.Parameters[1].Required)
+}
+
+func TestDocumentDefaultsOperationIDToRouteName(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Named("users.").Get("/users", noop).Name("index")
+
+	operation := spec.Document(app.Routes()).Paths["/users"]["get"]
+
+	require.Equal(t, "users.index", operation.OperationID)
+}
+
+func TestDocumentSuffixesSharedOperationIDs(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Methods([]string{http.MethodGet, http.MethodPost}, "/webhook", noop).Name("webhook")
+
+	item := spec.Document(app.Routes()).Paths["/webhook"]
+
+	require.Equal(t, "webhook.get", item["get"].OperationID)
+	require.Equal(t, "webhook.post", item["post"].OperationID)
+}
+
+func TestDocumentSkipsConnectRoutes(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Connect("/tunnel", noop)
+
+	require.NotContains(t, spec.Document(app.Routes()).Paths, "/tunnel")
+}
+
+func TestDocumentAddsDefaultProblemResponse(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Get("/users", noop)
+
+	document := spec.Document(app.Routes())
+	response := document.Paths["/users"]["get"].Responses["default"]
+
+	require.Equal(t, "Unexpected error.", response.Description)
+	require.Equal(
+		t,
+		"#/components/schemas/Problem",
+		response.Content["application/problem+json"].Schema.Ref,
+	)
+	require.Contains(t, document.Components.Schemas, openapi.ProblemSchemaName)
+}
+
+func TestDocumentDescribesRequestAndResponses(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+	request := openapi.JSON[user]("The user to create.")
+
+	spec.Describe(app.Post("/users", noop), openapi.Operation{
+		Request: &request,
+		Responses: map[int]openapi.Content{
+			http.StatusCreated:             openapi.JSON[user](""),
+			http.StatusUnprocessableEntity: openapi.Problem("The user is invalid."),
+		},
+	})
+
+	document := spec.Document(app.Routes())
+	operation := document.Paths["/users"]["post"]
+
+	require.NotNil(t, operation.RequestBody)
+	require.True(t, operation.RequestBody.Required)
+	require.Equal(
+		t,
+		"#/components/schemas/user",
+		operation.RequestBody.Content["application/json"].Schema.Ref,
+	)
+	require.Equal(t, "Created", operation.Responses["201"].Description)
+	require.Equal(t, "The user is invalid.", operation.Responses["422"].Description)
+	require.Contains(t, operation.Responses, "default")
+
+	schema := document.Components.Schemas["user"]
+
+	require.Equal(t, "object", schema.Type)
+	require.Equal(t, []string{"id", "name"}, schema.Required)
+	require.Equal(t, "int64", schema.Properties["id"].Format)
+}
+
+func TestDocumentReplacesDefaultResponse(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	spec.Describe(app.Delete("/users/{id}", noop), openapi.Operation{
+		Responses: map[int]openapi.Content{
+			http.StatusNoContent: openapi.Empty(""),
+			0:                    openapi.Empty("Something went wrong."),
+		},
+	})
+
+	document := spec.Document(app.Routes())
+	operation := document.Paths["/users/{id}"]["delete"]
+
+	require.Equal(t, "No Content", operation.Responses["204"].Description)
+	require.Nil(t, operation.Responses["204"].Content)
+	require.Equal(t, "Something went wrong.", operation.Responses["default"].Description)
+	require.Empty(t, document.Components.Schemas)
+}
@@ -0,0 +1,135 @@
+package openapi
+
+// Version is the OpenAPI specification version of the
+// generated documents.
+const Version = "3.1.0"
+
+// Document is the root object of an OpenAPI 3.1 document.
+type Document struct {
+	// OpenAPI is the version of the OpenAPI specification
+	// the document follows. See [Version].
+	OpenAPI string `json:"openapi"`
+
+	// Info provides metadata about the API.
+	Info Info `json:"info"`
+
+	// Servers lists the servers that provide the API.
+	Servers []Server `json:"servers,omitempty"`
+
+	// Paths maps each path template to the operations it supports.
+	Paths map[string]PathItem `json:"paths"`
+
+	// Components holds the reusable schemas referenced by
+	// the operations.
+	Components Components `json:"components,omitzero"`
+}
+
+// Info provides metadata about the API described by a [Document].
+type Info struct {
+	// Title is the name of the API.
+	Title string `json:"title"`
+
+	// Version is the version of the API, not to be confused
+	// with the OpenAPI specification version.
+	Version string `json:"version"`
+
+	// Description is a longer, CommonMark formatted,
+	// description of the API.
+	Description string `json:"description,omitempty"`
+}
+
+// Server describes a server that provides the API.
+type Server struct {
+	// URL is the base URL of the server.
+	URL string `json:"url"`
+
+	// Description describes the server, e.g. "production".
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to the operation
+// that handles them on a given path.
+type PathItem = map[string]*OperationObject
+
+// OperationObject describes a single API operation on a path.
+type OperationObject struct {
+	// OperationID uniquely identifies the operation.
+	OperationID string `json:"operationId,omitempty"`
+
+	// Summary is a short summary of what the operation does.
+	Summary string `json:"summary,omitempty"`
+
+	// Description is a verbose explanation of the operation.
+	Description string `json:"description,omitempty"`
+
+	// Tags groups the operation in documentation tools.
+	Tags []string `json:"tags,omitempty"`
+
+	// Deprecated marks the operation as deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// Parameters lists the path, query, header and cookie
+	// parameters of the operation.
+	Parameters []ParameterObject `json:"parameters,omitempty"`
+
+	// RequestBody describes the request body of the operation.
+	RequestBody *RequestBodyObject `json:"requestBody,omitempty"`
+
+	// Responses maps status codes, or "default", to the
+	// possible responses of the operation.
+	Responses map[string]ResponseObject `json:"responses"`
+}
+
+// ParameterObject describes a single operation parameter.
+type ParameterObject struct {
+	// Name is the name of the parameter.
+	Name string `json:"name"`
+
+	// In is the location of the parameter: "path", "query",
+	// "header" or "cookie".
+	In string `json:"in"`
+
+	// Description explains the meaning of the parameter.
+	Description string `json:"description,omitempty"`
+
+	// Required reports whether the parameter is mandatory.
+	// Path parameters are always required.
+	Required bool `json:"required,omitempty"`
+
+	// Schema describes the type of the parameter.
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// RequestBodyObject describes the body of a request.
+type RequestBodyObject struct {
+	// Description explains the content of the body.
+	Description string `json:"description,omitempty"`
+
+	// Content maps media types to their payload description.
+	Content map[string]MediaTypeObject `json:"content"`
+
+	// Required reports whether the body is mandatory.
+	Required bool `json:"required,omitempty"`
+}
+
+// ResponseObject describes a single response of an operation.
+type ResponseObject struct {
+	// Description explains the response. It is required by
+	// the OpenAPI specification.
+	Description string `json:"description"`
+
+	// Content maps media types to their payload description.
+	Content map[string]MediaTypeObject `json:"content,omitempty"`
+}
+
+// MediaTypeObject describes the payload of a given media type.
+type MediaTypeObject struct {
+	// Schema describes the payload.
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the reusable objects of a [Document].
+type Components struct {
+	// Schemas maps component names to their schema.
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
@@ -0,0 +1,250 @@
+// Package openapi generates OpenAPI 3.1 documents from the routes
+// registered on a [framework.Router]. Paths and path parameters are
+// derived from the registered [http.ServeMux] patterns while the
+// operation metadata, such as summaries, tags and the request and
+// response payload types, is attached to the routes with
+// [Spec.Describe]. Payload schemas are generated from Go types
+// following the same rules as [encoding/json], and errors are
+// described using the RFC 9457 schema that [problem.Problem]
+// serializes to.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/router"
+)
+
+// Operation holds the metadata of a route that cannot be derived
+// from its pattern. It is attached to routes with [Spec.Describe].
+type Operation struct {
+	// ID uniquely identifies the operation. It defaults to the
+	// route name given with [router.Route.Name], if any.
+	ID string
+
+	// Summary is a short summary of what the operation does.
+	Summary string
+
+	// Description is a verbose explanation of the operation.
+	Description string
+
+	// Tags groups the operation in documentation tools.
+	Tags []string
+
+	// Deprecated marks the operation as deprecated.
+	Deprecated bool
+
+	// Hidden excludes the operation from the generated document.
+	// This is useful for internal routes such as the one serving
+	// the document itself.
+	Hidden bool
+
+	// Parameters describes the operation parameters. Path
+	// parameters are always derived from the route pattern, so
+	// they only need to be listed to add a description or a type.
+	Parameters []Parameter
+
+	// Request describes the request body, if any.
+	Request *Content
+
+	// Responses maps status codes to the response they produce.
+	// Every operation also documents a default [Problem] response,
+	// matching the way [framework.Handler] renders errors, unless
+	// a response with status code 0 is given to replace it.
+	Responses map[int]Content
+}
+
+// Parameter describes a path, query, header or cookie parameter.
+type Parameter struct {
+	// Name is the name of the parameter. For path parameters it
+	// must match the name of a wildcard in the route pattern.
+	Name string
+
+	// In is the location of the parameter: "path", "query",
+	// "header" or "cookie". It defaults to "path".
+	In string
+
+	// Description explains the meaning of the parameter.
+	Description string
+
+	// Required reports whether the parameter is mandatory.
+	// Path parameters are always required.
+	Required bool
+
+	// Type is the Go type of the parameter value. It defaults
+	// to string when nil.
+	Type reflect.Type
+}
+
+// Content describes a request or response payload.
+type Content struct {
+	// Description explains the payload. Response descriptions
+	// default to the status text of their status code.
+	Description string
+
+	// MediaType is the media type of the payload, such as
+	// "application/json". It's ignored when Type is nil.
+	MediaType string
+
+	// Type is the Go type of the payload. A nil type describes
+	// a response without content.
+	Type reflect.Type
+
+	// Required reports whether a request body is mandatory.
+	// It's ignored for responses.
+	Required bool
+}
+
+// Path describes the path parameter with the given name.
+func Path(name string, description string) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Type:        nil,
+	}
+}
+
+// Query describes the optional query parameter with the given name
+// whose value is parsed as T, e.g. with [request.QueryInt].
+func Query[T any](name string, description string) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Required:    false,
+		Type:        reflect.TypeFor[T](),
+	}
+}
+
+// Header describes the optional header parameter with the given name.
+func Header(name string, description string) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "header",
+		Description: description,
+		Required:    false,
+		Type:        nil,
+	}
+}
+
+// JSON describes a JSON payload of type T, such as the ones read
+// with [request.JSON] or written with [response.JSON].
+func JSON[T any](description string) Content {
+	return Content{
+		Description: description,
+		MediaType:   "application/json",
+		Type:        reflect.TypeFor[T](),
+		Required:    true,
+	}
+}
+
+// Problem describes an RFC 9457 problem details payload, as written
+// by [problem.Problem] when served.
+func Problem(description string) Content {
+	return Content{
+		Description: description,
+		MediaType:   "application/problem+json",
+		Type:        problemType,
+		Required:    true,
+	}
+}
+
+// Empty describes a response without content, such as a
+// [http.StatusNoContent] response.
+func Empty(description string) Content {
+	return Content{
+		Description: description,
+		MediaType:   "",
+		Type:        nil,
+		Required:    false,
+	}
+}
+
+// RouteSource is implemented by the routers whose routes can be
+// documented, such as [framework.Router].
+type RouteSource interface {
+	Routes() []router.RouteInfo
+}
+
+// Spec collects the operation metadata of the routes and generates
+// OpenAPI documents from them.
+//
+// A Spec is not safe for concurrent use while operations are being
+// described. All operations must be described before serving the
+// document, just like routes must be registered before serving them.
+type Spec struct {
+	// info stores the API metadata of the generated documents.
+	info Info
+
+	// servers stores the servers listed in the generated documents.
+	servers []Server
+
+	// operations stores the described operations keyed by
+	// their method and pattern, e.g. "GET /users/{id}".
+	operations map[string]Operation
+}
+
+// New creates an empty [Spec] that generates documents with the
+// given API metadata and servers.
+func New(info Info, servers ...Server) *Spec {
+	return &Spec{
+		info:       info,
+		servers:    servers,
+		operations: make(map[string]Operation),
+	}
+}
+
+// Describe attaches the given operation metadata to every method
+// the route was registered with. It returns the route so that it
+// can be further configured:
+//
+//	spec.Describe(app.Get("/users/{id}", show), openapi.Operation{
+//	    Summary:   "Show a user",
+//	    Tags:      []string{"users"},
+//	    Responses: map[int]openapi.Content{
+//	        http.StatusOK:       openapi.JSON[User]("The user."),
+//	        http.StatusNotFound: openapi.Problem("The user does not exist."),
+//	    },
+//	}).Name("users.show")
+func (spec *Spec) Describe(route *router.Route, operation Operation) *router.Route {
+	for _, method := range route.Methods() {
+		spec.operations[method+" "+route.Host()+route.Pattern()] = operation
+	}
+
+	return route
+}
+
+// Handler returns a [framework.Handler] that serves the JSON
+// document of the routes registered on the given source. The
+// document is generated on every request so that it always
+// reflects the current routes.
+//
+//	app.Get("/openapi.json", spec.Handler(app))
+func (spec *Spec) Handler(source RouteSource) framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return response.JSON(w, http.StatusOK, spec.Document(source.Routes()))
+	}
+}
+
+// YAMLHandler returns a [framework.Handler] that serves the YAML
+// document of the routes registered on the given source.
+//
+//	app.Get("/openapi.yaml", spec.YAMLHandler(app))
+func (spec *Spec) YAMLHandler(source RouteSource) framework.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		encoded, err := spec.Document(source.Routes()).YAML()
+
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+
+		return response.Raw(w, http.StatusOK, encoded)
+	}
+}
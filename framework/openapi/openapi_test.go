@@ -0,0 +1,94 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/openapi"
+
+	"github.com/stretchr/testify/require"
+)
+
+func noop(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+func TestHandlerServesJSONDocument(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Get("/users", noop)
+	app.Get("/openapi.json", spec.Handler(app))
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+
+	app.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+
+	var document map[string]any
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &document))
+	require.Equal(t, "3.1.0", document["openapi"])
+	require.Contains(t, document["paths"], "/users")
+}
+
+func TestHandlerSkipsHiddenRoutes(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	spec.Describe(app.Get("/openapi.json", spec.Handler(app)), openapi.Operation{
+		Hidden: true,
+	})
+
+	document := spec.Document(app.Routes())
+
+	require.NotContains(t, document.Paths, "/openapi.json")
+}
+
+func TestYAMLHandlerServesYAMLDocument(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	app.Get("/users/{id}", noop)
+	app.Get("/openapi.yaml", spec.YAMLHandler(app))
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil)
+	rec := httptest.NewRecorder()
+
+	app.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+	require.True(t, strings.HasPrefix(rec.Body.String(), "components:\n"))
+	require.Contains(t, rec.Body.String(), "openapi: \"3.1.0\"\n")
+	require.Contains(t, rec.Body.String(), "  \"/users/{id}\":\n")
+}
+
+func TestDescribeAppliesToEveryMethod(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	spec.Describe(app.Methods([]string{http.MethodPut, http.MethodPatch}, "/users/{id}", noop), openapi.Operation{
+		Summary: "Update a user",
+	})
+
+	document := spec.Document(app.Routes())
+
+	require.Equal(t, "Update a user", document.Paths["/users/{id}"]["put"].Summary)
+	require.Equal(t, "Update a user", document.Paths["/users/{id}"]["patch"].Summary)
+}
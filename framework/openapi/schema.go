@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// Schema is a JSON Schema (draft 2020-12) object as used by
+// OpenAPI 3.1 to describe request and response payloads.
+type Schema struct {
+	// Ref references a schema defined in the document components,
+	// e.g. "#/components/schemas/User".
+	Ref string `json:"$ref,omitempty"`
+
+	// Type is either a single JSON type name or a list of them,
+	// such as ["string", "null"] for nullable values.
+	Type any `json:"type,omitempty"`
+
+	// Format refines the type, e.g. "int64" or "date-time".
+	Format string `json:"format,omitempty"`
+
+	// Description explains the meaning of the value.
+	Description string `json:"description,omitempty"`
+
+	// ContentEncoding describes how string values are encoded,
+	// e.g. "base64" for byte slices.
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// Default is the value assumed when the value is absent.
+	Default any `json:"default,omitempty"`
+
+	// Minimum is the inclusive lower bound of numeric values.
+	Minimum *float64 `json:"minimum,omitempty"`
+
+	// Items describes the elements of array values.
+	Items *Schema `json:"items,omitempty"`
+
+	// Properties describes the members of object values.
+	Properties map[string]*Schema `json:"properties,omitempty"`
+
+	// Required lists the properties that must be present.
+	Required []string `json:"required,omitempty"`
+
+	// AdditionalProperties is either a boolean or a [Schema]
+	// describing the members not listed in Properties.
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+}
+
+// ProblemSchemaName is the component name under which the RFC 9457
+// problem details schema is registered in generated documents.
+const ProblemSchemaName = "Problem"
+
+// ProblemSchema returns the RFC 9457 problem details schema that
+// describes the JSON produced by [problem.Problem]. Additional
+// members are allowed, matching the extensions that can be added
+// with [problem.Problem.With].
+func ProblemSchema() *Schema {
+	return &Schema{
+		Type:        "object",
+		Description: "Problem details as defined by RFC 9457.",
+		Properties: map[string]*Schema{
+			"type": {
+				Type:        "string",
+				Format:      "uri-reference",
+				Description: "A URI reference that identifies the problem type.",
+				Default:     "about:blank",
+			},
+			"title": {
+				Type:        "string",
+				Description: "A short, human-readable summary of the problem type.",
+			},
+			"status": {
+				Type:        "integer",
+				Description: "The HTTP status code generated by the origin server.",
+			},
+			"detail": {
+				Type:        "string",
+				Description: "A human-readable explanation specific to this occurrence of the problem.",
+			},
+			"instance": {
+				Type:        "string",
+				Format:      "uri-reference",
+				Description: "A URI reference that identifies the specific occurrence of the problem.",
+			},
+		},
+		AdditionalProperties: true,
+	}
+}
+
+var (
+	// timeType is the reflected [time.Time] type, which is
+	// serialized as an RFC 3339 string.
+	timeType = reflect.TypeFor[time.Time]()
+
+	// problemType is the reflected [problem.Problem] type, which
+	// is described by [ProblemSchema].
+	problemType = reflect.TypeFor[problem.Problem]()
+
+	// jsonMarshalerType is the reflected [json.Marshaler] interface.
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+	// textMarshalerType is the reflected [encoding.TextMarshaler] interface.
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+	// importPathPattern matches the import paths that prefix
+	// the type arguments of generic type names.
+	importPathPattern = regexp.MustCompile(`[\w.\-]+/`)
+
+	// unsafeNamePattern matches the characters that are not allowed
+	// in OpenAPI component names.
+	unsafeNamePattern = regexp.MustCompile(`[^A-Za-z0-9._\-]+`)
+)
+
+// schemas generates [Schema] values from Go types, registering
+// named struct types as reusable components.
+type schemas struct {
+	// components stores the schemas of named types keyed by
+	// their component name.
+	components map[string]*Schema
+
+	// names stores the component name assigned to each
+	// named type, preventing collisions and infinite recursion.
+	names map[reflect.Type]string
+}
+
+// newSchemas creates an empty schema generator.
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema describing the JSON encoding of the given
+// type. Named struct types are registered as components and a
+// reference to them is returned instead.
+func (generator *schemas) of(typ reflect.Type) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == problemType {
+		generator.components[ProblemSchemaName] = ProblemSchema()
+
+		return &Schema{Ref: "#/components/schemas/" + ProblemSchemaName}
+	}
+
+	if typ == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	if typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+
+	if typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	if typ.Kind() == reflect.Struct && typ.Name() != "" {
+		return generator.component(typ)
+	}
+
+	return generator.inline(typ)
+}
+
+// component registers the given named struct type as a component
+// and returns a reference to it.
+func (generator *schemas) component(typ reflect.Type) *Schema {
+	if name, ok := generator.names[typ]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	base := componentName(typ)
+	name := base
+
+	// Types from different packages may share the same name,
+	// in which case a numeric suffix is added to the latter.
+	for suffix := 2; generator.components[name] != nil; suffix++ {
+		name = fmt.Sprintf("%s_%d", base, suffix)
+	}
+
+	// The name is assigned before generating the object schema
+	// so that recursive types resolve to a reference to it.
+	generator.names[typ] = name
+	generator.components[name] = &Schema{}
+	generator.components[name] = generator.object(typ)
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// inline returns the schema of the given type without registering
+// it as a component.
+func (generator *schemas) inline(typ reflect.Type) *Schema {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		minimum := 0.0
+
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+
+		return &Schema{Type: "array", Items: generator.of(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: generator.of(typ.Elem())}
+	case reflect.Struct:
+		return generator.object(typ)
+	default:
+		return &Schema{}
+	}
+}
+
+// object returns the object schema of the given struct type,
+// following the same field rules as [encoding/json].
+func (generator *schemas) object(typ reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	generator.fields(typ, schema)
+
+	return schema
+}
+
+// fields adds the exported fields of the given struct type to the
+// object schema. Embedded structs without a JSON name are flattened
+// into the parent object, as [encoding/json] does.
+func (generator *schemas) fields(typ reflect.Type, schema *Schema) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		embedded := field.Type
+
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			generator.fields(embedded, schema)
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = generator.of(field.Type)
+
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// componentName returns a name for the given type that is valid
+// as an OpenAPI component name. Type arguments of generic types
+// are kept without their import paths, e.g. "Page[main.User]"
+// becomes "Page_main.User".
+func componentName(typ reflect.Type) string {
+	name := importPathPattern.ReplaceAllString(typ.Name(), "")
+
+	return strings.Trim(unsafeNamePattern.ReplaceAllString(name, "_"), "_")
+}
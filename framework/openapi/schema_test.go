@@ -0,0 +1,113 @@
+package openapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/openapi"
+
+	"github.com/stretchr/testify/require"
+)
+
+type node struct {
+	Value    string  `json:"value"`
+	Children []*node `json:"children"`
+}
+
+type timestamps struct {
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitzero"`
+}
+
+type article struct {
+	timestamps
+
+	Title    string            `json:"title"`
+	Body     []byte            `json:"body"`
+	Views    uint              `json:"views"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Internal string            `json:"-"`
+	secret   string
+}
+
+func schemaOf[T any](t *testing.T) (*openapi.Schema, map[string]*openapi.Schema) {
+	t.Helper()
+
+	app := framework.New()
+	spec := openapi.New(openapi.Info{Title: "Test", Version: "1.0.0"})
+
+	spec.Describe(app.Get("/", noop), openapi.Operation{
+		Responses: map[int]openapi.Content{
+			200: openapi.JSON[T](""),
+			0:   openapi.Empty(""),
+		},
+	})
+
+	document := spec.Document(app.Routes())
+
+	return document.Paths["/"]["get"].Responses["200"].Content["application/json"].Schema, document.Components.Schemas
+}
+
+func TestSchemaOfScalars(t *testing.T) {
+	t.Parallel()
+
+	schema, _ := schemaOf[[]float32](t)
+
+	require.Equal(t, "array", schema.Type)
+	require.Equal(t, "number", schema.Items.Type)
+	require.Equal(t, "float", schema.Items.Format)
+}
+
+func TestSchemaOfStructFollowsJSONRules(t *testing.T) {
+	t.Parallel()
+
+	schema, components := schemaOf[article](t)
+
+	require.Equal(t, "#/components/schemas/article", schema.Ref)
+
+	object := components["article"]
+
+	require.ElementsMatch(
+		t,
+		[]string{"created_at", "deleted_at", "title", "body", "views", "meta"},
+		keys(object.Properties),
+	)
+	require.Equal(t, []string{"created_at", "title", "body", "views"}, object.Required)
+	require.Equal(t, "date-time", object.Properties["created_at"].Format)
+	require.Equal(t, "date-time", object.Properties["deleted_at"].Format)
+	require.Equal(t, "base64", object.Properties["body"].ContentEncoding)
+	require.Equal(t, 0.0, *object.Properties["views"].Minimum)
+	require.Equal(t, "string", object.Properties["meta"].AdditionalProperties.(*openapi.Schema).Type)
+}
+
+func TestSchemaOfRecursiveStruct(t *testing.T) {
+	t.Parallel()
+
+	schema, components := schemaOf[node](t)
+
+	require.Equal(t, "#/components/schemas/node", schema.Ref)
+	require.Equal(t, "#/components/schemas/node", components["node"].Properties["children"].Items.Ref)
+}
+
+func TestSchemaOfAnonymousStructIsInlined(t *testing.T) {
+	t.Parallel()
+
+	schema, components := schemaOf[struct {
+		Count int `json:"count"`
+	}](t)
+
+	require.Equal(t, "object", schema.Type)
+	require.Equal(t, "integer", schema.Properties["count"].Type)
+	require.Empty(t, components)
+}
+
+func keys[V any](values map[string]V) []string {
+	result := make([]string, 0, len(values))
+
+	for key := range values {
+		result = append(result, key)
+	}
+
+	return result
+}
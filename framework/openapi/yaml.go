@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// plainKeyPattern matches the mapping keys that can be written
+// without quotes while still being parsed as strings.
+var plainKeyPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$.\-]*$`)
+
+// reservedKeys lists the plain scalars that YAML 1.1 and 1.2
+// parsers resolve to booleans or null instead of strings.
+var reservedKeys = []string{"true", "false", "null", "yes", "no", "on", "off", "y", "n"}
+
+// YAML returns the YAML encoding of the document. The document is
+// first encoded as JSON so that the YAML output follows the exact
+// same field rules, then written as block style YAML with sorted
+// keys and double-quoted strings, which avoids any ambiguity in
+// how scalars are parsed back.
+func (document Document) YAML() ([]byte, error) {
+	encoded, err := json.Marshal(document)
+
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var value any
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if err := writeYAML(buffer, value, 0); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// writeYAML writes the given JSON decoded collection as a YAML
+// block at the given indentation.
+func writeYAML(buffer *bytes.Buffer, value any, indent int) error {
+	padding := strings.Repeat(" ", indent)
+
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			buffer.WriteString(padding + yamlKey(key) + ":")
+
+			if err := writeYAMLValue(buffer, value[key], indent+2); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case []any:
+		for _, item := range value {
+			buffer.WriteString(padding + "-")
+
+			if err := writeYAMLValue(buffer, item, indent+2); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("openapi: unexpected yaml collection %T", value)
+	}
+}
+
+// writeYAMLValue writes the given JSON decoded value after a mapping
+// key or a sequence dash. Scalars and empty collections are written
+// on the same line while other collections start a nested block.
+func writeYAMLValue(buffer *bytes.Buffer, value any, indent int) error {
+	switch value := value.(type) {
+	case map[string]any:
+		if len(value) == 0 {
+			buffer.WriteString(" {}\n")
+
+			return nil
+		}
+	case []any:
+		if len(value) == 0 {
+			buffer.WriteString(" []\n")
+
+			return nil
+		}
+	default:
+		scalar, err := yamlScalar(value)
+
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteString(" " + scalar + "\n")
+
+		return nil
+	}
+
+	buffer.WriteString("\n")
+
+	return writeYAML(buffer, value, indent)
+}
+
+// yamlScalar returns the YAML representation of the given JSON
+// decoded scalar. JSON strings are valid YAML double-quoted
+// scalars, so they are reused as is.
+func yamlScalar(value any) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "null", nil
+	case bool, json.Number:
+		return fmt.Sprint(value), nil
+	case string:
+		encoded, err := json.Marshal(value)
+
+		return string(encoded), err
+	default:
+		return "", fmt.Errorf("openapi: unexpected yaml scalar %T", value)
+	}
+}
+
+// yamlKey returns the given mapping key, quoted unless it can be
+// written as a plain scalar that is parsed back as a string.
+func yamlKey(key string) string {
+	if plainKeyPattern.MatchString(key) && !slices.Contains(reservedKeys, strings.ToLower(key)) {
+		return key
+	}
+
+	encoded, _ := json.Marshal(key)
+
+	return string(encoded)
+}
@@ -0,0 +1,69 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/openapi"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAMLEncodesDocument(t *testing.T) {
+	t.Parallel()
+
+	document := openapi.Document{
+		OpenAPI: openapi.Version,
+		Info:    openapi.Info{Title: "Test \"API\"", Version: "1.0.0"},
+		Servers: []openapi.Server{{URL: "https://api.example.com", Description: "production"}},
+		Paths: map[string]openapi.PathItem{
+			"/users": {
+				"get": {
+					Tags:      []string{"users"},
+					Responses: map[string]openapi.ResponseObject{"200": {Description: "OK"}},
+				},
+			},
+		},
+	}
+
+	encoded, err := document.YAML()
+
+	require.NoError(t, err)
+	require.Equal(t, `info:
+  title: "Test \"API\""
+  version: "1.0.0"
+openapi: "3.1.0"
+paths:
+  "/users":
+    get:
+      responses:
+        "200":
+          description: "OK"
+      tags:
+        - "users"
+servers:
+  -
+    description: "production"
+    url: "https://api.example.com"
+`, string(encoded))
+}
+
+func TestYAMLQuotesAmbiguousKeys(t *testing.T) {
+	t.Parallel()
+
+	document := openapi.Document{
+		OpenAPI: openapi.Version,
+		Info:    openapi.Info{Title: "Test", Version: "1.0.0"},
+		Paths:   map[string]openapi.PathItem{},
+		Components: openapi.Components{
+			Schemas: map[string]*openapi.Schema{
+				"on": {Type: "boolean", Default: false},
+			},
+		},
+	}
+
+	encoded, err := document.YAML()
+
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), "    \"on\":\n      default: false\n")
+	require.Contains(t, string(encoded), "paths: {}\n")
+}
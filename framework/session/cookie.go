@@ -0,0 +1,197 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// DefaultCookieMaxSize is the default maximum size of the cookie values
+// of a [CookieDriver], which leaves room for the cookie name and
+// attributes within the 4096 bytes browsers are required to store.
+const DefaultCookieMaxSize = 4000
+
+// ErrCookieTooLarge is returned when an encoded session does not fit
+// in a cookie. Sessions stored in cookies must be kept small.
+var ErrCookieTooLarge = errors.New("session too large to fit in a cookie")
+
+// ErrInvalidCookie is returned when a session cookie can't be
+// decrypted with any of the keys or is malformed.
+var ErrInvalidCookie = errors.New("invalid session cookie")
+
+// CookieEncoder is implemented by session drivers that store the whole
+// session in its cookie, such as [CookieDriver]. The session middleware
+// uses the encoded session as the cookie value instead of its ID, and
+// decodes cookies with [CookieEncoder.Decode] instead of loading them
+// with [contract.SessionDriver.Get].
+type CookieEncoder interface {
+	// Encode returns the cookie value of the given session.
+	Encode(ctx context.Context, session *contract.Session) (string, error)
+
+	// Decode returns the session of the given cookie value. Unlike
+	// drivers, it returns sessions marked as changed when they must
+	// be encoded again, such as after a key rotation.
+	Decode(ctx context.Context, value string) (*contract.Session, error)
+}
+
+// CookieDriverOptions configures a [CookieDriver].
+type CookieDriverOptions struct {
+	// Encrypter encrypts and authenticates the sessions,
+	// such as [crypto.AES] or [crypto.ChaCha20]. It's required.
+	Encrypter contract.Encrypter
+
+	// Previous lists the encrypters of previous keys, which only
+	// decrypt sessions. Sessions decrypted with them are encrypted
+	// again with the current one on the same response, so previous
+	// keys can be dropped once every session had time to be rotated.
+	Previous []contract.Encrypter
+
+	// MaxSize is the maximum size of cookie values, above which
+	// sessions are not saved. Defaults to [DefaultCookieMaxSize].
+	MaxSize int
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options CookieDriverOptions) withDefaults() CookieDriverOptions {
+	if options.MaxSize == 0 {
+		options.MaxSize = DefaultCookieMaxSize
+	}
+
+	return options
+}
+
+// CookieDriver implements [contract.SessionDriver] and [CookieEncoder]
+// by storing sessions encrypted in their cookie, so that no shared
+// storage is needed. Sessions are JSON-serialized, encrypted with a
+// [contract.Encrypter] and base64url encoded.
+//
+// WARNING: Sessions stored in cookies can't be revoked on the server.
+// Deleting them, such as when regenerating their ID, only stops the
+// response from sending them again, so a copy of a previous cookie
+// stays valid until its session expires or exceeds the maximum
+// lifetime of the middleware.
+type CookieDriver struct {
+	// options stores the driver options.
+	options CookieDriverOptions
+}
+
+// NewCookieDriver creates a [CookieDriver] that encrypts sessions with
+// the given encrypter, and also decrypts them with the given previous
+// ones to rotate keys.
+func NewCookieDriver(encrypter contract.Encrypter, previous ...contract.Encrypter) *CookieDriver {
+	return NewCookieDriverWith(CookieDriverOptions{
+		Encrypter: encrypter,
+		Previous:  previous,
+	})
+}
+
+// NewCookieDriverWith creates a [CookieDriver] with the given options.
+//
+// It panics if the encrypter is nil, as it's a programming error.
+func NewCookieDriverWith(options CookieDriverOptions) *CookieDriver {
+	if options.Encrypter == nil {
+		panic("session: cookie driver requires an encrypter")
+	}
+
+	return &CookieDriver{options: options.withDefaults()}
+}
+
+// Get decodes the session of the given cookie value, see
+// [CookieDriver.Decode].
+func (driver *CookieDriver) Get(ctx context.Context, value string) (*contract.Session, error) {
+	return driver.Decode(ctx, value)
+}
+
+// Save does nothing, as sessions are saved in their cookie
+// by the middleware with [CookieDriver.Encode].
+func (driver *CookieDriver) Save(ctx context.Context, session *contract.Session, ttl time.Duration) error {
+	return nil
+}
+
+// Delete does nothing, as sessions stored in cookies
+// can't be deleted on the server.
+func (driver *CookieDriver) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// Encode returns the session encrypted with the current key, or
+// [ErrCookieTooLarge] when it exceeds the maximum size.
+func (driver *CookieDriver) Encode(ctx context.Context, session *contract.Session) (string, error) {
+	raw, err := json.Marshal(sessionData{
+		ID:        session.SessionID(),
+		CreatedAt: session.CreatedAt(),
+		ExpiresAt: session.ExpiresAt(),
+		Storage:   session.All(),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := driver.options.Encrypter.Encrypt(raw)
+
+	if err != nil {
+		return "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(encrypted)
+
+	if len(value) > driver.options.MaxSize {
+		return "", ErrCookieTooLarge
+	}
+
+	return value, nil
+}
+
+// Decode decrypts the session of the given cookie value with the
+// current key or any previous one, in which case the session is marked
+// as changed to encrypt it again with the current key. It returns
+// [ErrInvalidCookie] when no key decrypts it.
+func (driver *CookieDriver) Decode(ctx context.Context, value string) (*contract.Session, error) {
+	if len(value) > driver.options.MaxSize {
+		return nil, ErrInvalidCookie
+	}
+
+	encrypted, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, ErrInvalidCookie
+	}
+
+	encrypters := append([]contract.Encrypter{driver.options.Encrypter}, driver.options.Previous...)
+
+	for i, encrypter := range encrypters {
+		raw, err := encrypter.Decrypt(encrypted)
+
+		if err != nil {
+			continue
+		}
+
+		var data sessionData
+
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, ErrInvalidCookie
+		}
+
+		if data.Storage == nil {
+			data.Storage = map[string]any{}
+		}
+
+		session := contract.NewSessionFrom(data.ID, data.CreatedAt, data.ExpiresAt, data.Storage)
+
+		// Extending the session to its own expiration time only
+		// marks it as changed, so it's encrypted with the current key.
+		if i > 0 {
+			session.Extend(data.ExpiresAt)
+		}
+
+		return session, nil
+	}
+
+	return nil, ErrInvalidCookie
+}
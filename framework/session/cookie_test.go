@@ -0,0 +1,281 @@
+package session_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/crypto"
+	"github.com/studiolambda/cosmos/framework/session"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newAES(t *testing.T, seed byte) *crypto.AES {
+	t.Helper()
+
+	encrypter, err := crypto.NewAES(bytes.Repeat([]byte{seed}, 32))
+
+	require.NoError(t, err)
+
+	return encrypter
+}
+
+func TestCookieDriverEncodesAndDecodesSessions(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCookieDriver(newAES(t, 1))
+
+	original, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{"user_id": "42"})
+
+	require.NoError(t, err)
+
+	value, err := driver.Encode(context.Background(), original)
+
+	require.NoError(t, err)
+	require.NotContains(t, value, "user_id")
+
+	decoded, err := driver.Decode(context.Background(), value)
+
+	require.NoError(t, err)
+	require.Equal(t, original.SessionID(), decoded.SessionID())
+	require.WithinDuration(t, original.CreatedAt(), decoded.CreatedAt(), 0)
+	require.WithinDuration(t, original.ExpiresAt(), decoded.ExpiresAt(), 0)
+	require.False(t, decoded.HasChanged())
+
+	userID, ok := decoded.Get("user_id")
+
+	require.True(t, ok)
+	require.Equal(t, "42", userID)
+}
+
+func TestCookieDriverRejectsTamperedCookies(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCookieDriver(newAES(t, 1))
+
+	original, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{})
+
+	require.NoError(t, err)
+
+	value, err := driver.Encode(context.Background(), original)
+
+	require.NoError(t, err)
+
+	tampered := []byte(value)
+	tampered[len(tampered)/2] ^= 'A' ^ 'B'
+
+	_, err = driver.Decode(context.Background(), string(tampered))
+
+	require.ErrorIs(t, err, session.ErrInvalidCookie)
+
+	_, err = driver.Decode(context.Background(), "not base64!")
+
+	require.ErrorIs(t, err, session.ErrInvalidCookie)
+}
+
+func TestCookieDriverDecodesWithPreviousKeys(t *testing.T) {
+	t.Parallel()
+
+	previous := session.NewCookieDriver(newAES(t, 1))
+	rotated := session.NewCookieDriver(newAES(t, 2), newAES(t, 1))
+	unrelated := session.NewCookieDriver(newAES(t, 3))
+
+	original, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{})
+
+	require.NoError(t, err)
+
+	value, err := previous.Encode(context.Background(), original)
+
+	require.NoError(t, err)
+
+	decoded, err := rotated.Decode(context.Background(), value)
+
+	require.NoError(t, err)
+	require.Equal(t, original.SessionID(), decoded.SessionID())
+	require.True(t, decoded.HasChanged())
+
+	_, err = unrelated.Decode(context.Background(), value)
+
+	require.ErrorIs(t, err, session.ErrInvalidCookie)
+}
+
+func TestCookieDriverRejectsSessionsTooLargeForCookies(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCookieDriver(newAES(t, 1))
+
+	original, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{
+		"payload": strings.Repeat("x", session.DefaultCookieMaxSize),
+	})
+
+	require.NoError(t, err)
+
+	_, err = driver.Encode(context.Background(), original)
+
+	require.ErrorIs(t, err, session.ErrCookieTooLarge)
+}
+
+func TestCookieDriverPanicsWithoutEncrypter(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		session.NewCookieDriverWith(session.CookieDriverOptions{})
+	})
+}
+
+func TestMiddlewareStoresSessionsInCookies(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCookieDriver(newAES(t, 1))
+
+	var id string
+	var userID any
+
+	handler := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		sess, _ := request.Session(r)
+
+		if r.URL.Path == "/login" {
+			sess.Put("user_id", "42")
+
+			if err := sess.Regenerate(); err != nil {
+				return err
+			}
+		}
+
+		id = sess.SessionID()
+		userID, _ = sess.Get("user_id")
+
+		return nil
+	})
+
+	handlerWithSessions := session.MiddlewareWith(driver, session.MiddlewareOptions{})(handler)
+
+	res := handlerWithSessions.Record(httptest.NewRequest(http.MethodGet, "/", nil))
+	cookie := res.Cookies()[0]
+	guest := id
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.AddCookie(cookie)
+
+	res = handlerWithSessions.Record(req)
+	cookie = res.Cookies()[0]
+
+	require.NotEqual(t, guest, id)
+	require.Greater(t, len(cookie.Value), 43)
+
+	authenticated := id
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+
+	res = handlerWithSessions.Record(req)
+
+	require.Equal(t, authenticated, id)
+	require.Equal(t, "42", userID)
+	require.Empty(t, res.Cookies())
+}
+
+func TestMiddlewareRotatesCookieSessionKeys(t *testing.T) {
+	t.Parallel()
+
+	previous := session.NewCookieDriver(newAES(t, 1))
+	rotated := session.NewCookieDriver(newAES(t, 2), newAES(t, 1))
+
+	original, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{"user_id": "42"})
+
+	require.NoError(t, err)
+
+	value, err := previous.Encode(context.Background(), original)
+
+	require.NoError(t, err)
+
+	handler := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: session.DefaultCookie, Value: value})
+
+	res := session.MiddlewareWith(rotated, session.MiddlewareOptions{})(handler).Record(req)
+	cookies := res.Cookies()
+
+	require.Len(t, cookies, 1)
+
+	_, err = previous.Decode(context.Background(), cookies[0].Value)
+
+	require.ErrorIs(t, err, session.ErrInvalidCookie)
+
+	decoded, err := session.NewCookieDriver(newAES(t, 2)).Decode(context.Background(), cookies[0].Value)
+
+	require.NoError(t, err)
+	require.Equal(t, original.SessionID(), decoded.SessionID())
+}
+
+func TestMiddlewareReplacesCookieSessionsPastMaxLifetime(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCookieDriver(newAES(t, 1))
+
+	original := contract.NewSessionFrom(
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		time.Now().Add(-2*time.Hour),
+		time.Now().Add(time.Hour),
+		map[string]any{"user_id": "42"},
+	)
+
+	value, err := driver.Encode(context.Background(), original)
+
+	require.NoError(t, err)
+
+	var found bool
+
+	handler := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		sess, _ := request.Session(r)
+		_, found = sess.Get("user_id")
+
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: session.DefaultCookie, Value: value})
+
+	options := session.MiddlewareOptions{MaxLifetime: time.Hour}
+	res := session.MiddlewareWith(driver, options)(handler).Record(req)
+
+	require.False(t, found)
+	require.Len(t, res.Cookies(), 1)
+}
+
+func TestMiddlewareReportsCookieSessionsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCookieDriverWith(session.CookieDriverOptions{
+		Encrypter: newAES(t, 1),
+		MaxSize:   64,
+	})
+
+	var reported error
+
+	handler := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	options := session.MiddlewareOptions{
+		ErrorHandler: func(err error) {
+			reported = err
+		},
+	}
+
+	res := session.MiddlewareWith(driver, options)(handler).Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.ErrorIs(t, reported, session.ErrCookieTooLarge)
+	require.Empty(t, res.Cookies())
+}
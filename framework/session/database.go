@@ -0,0 +1,506 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/request"
+)
+
+// Dialect is the SQL dialect of the database sessions are stored in,
+// which only matters for their schema, see [Schema].
+type Dialect string
+
+const (
+	// DialectSQLite is the dialect of SQLite databases.
+	DialectSQLite Dialect = "sqlite"
+
+	// DialectPostgres is the dialect of PostgreSQL databases.
+	DialectPostgres Dialect = "postgres"
+)
+
+const (
+	// DefaultTable is the default table sessions are stored in.
+	DefaultTable = "sessions"
+
+	// DefaultUserKey is the default session key holding the
+	// identifier of the user the session belongs to.
+	DefaultUserKey = "user_id"
+
+	// DefaultGCInterval is the default interval between the
+	// garbage collections of expired sessions.
+	DefaultGCInterval = 30 * time.Minute
+)
+
+// ErrUnsupportedDialect is returned when requesting the
+// schema of a dialect that is not supported.
+var ErrUnsupportedDialect = errors.New("unsupported sql dialect")
+
+// ErrUnsupportedUserID is returned when saving a session whose user
+// identifier is neither a string nor a whole number.
+var ErrUnsupportedUserID = errors.New("unsupported session user identifier")
+
+// validTablePattern matches table names, optionally schema-qualified,
+// as they are interpolated in queries.
+var validTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// DatabaseDriverOptions configures a [DatabaseDriver].
+type DatabaseDriverOptions struct {
+	// Table is the table sessions are stored in.
+	// Defaults to [DefaultTable].
+	Table string
+
+	// UserKey is the session key holding the identifier of the user
+	// the session belongs to, which sessions are listed and revoked
+	// by. It must be a string or a whole number, which is stored in
+	// base 10. Defaults to [DefaultUserKey].
+	UserKey string
+
+	// GCInterval is the interval between the garbage collections of
+	// expired sessions, which run in the background until the driver
+	// is closed. A negative value disables them.
+	// Defaults to [DefaultGCInterval].
+	GCInterval time.Duration
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by their defaults.
+func (options DatabaseDriverOptions) withDefaults() DatabaseDriverOptions {
+	if options.Table == "" {
+		options.Table = DefaultTable
+	}
+
+	if options.UserKey == "" {
+		options.UserKey = DefaultUserKey
+	}
+
+	if options.GCInterval == 0 {
+		options.GCInterval = DefaultGCInterval
+	}
+
+	return options
+}
+
+// Info describes an active session of a user, such as
+// to list the devices they are logged in from.
+type Info struct {
+	// ID is the session ID.
+	ID string `db:"id" json:"id"`
+
+	// UserID is the identifier of the user.
+	UserID string `db:"user_id" json:"user_id"`
+
+	// IP is the IP address of the client that last saved the session.
+	IP string `db:"ip" json:"ip"`
+
+	// UserAgent is the user agent of the client that last saved the session.
+	UserAgent string `db:"user_agent" json:"user_agent"`
+
+	// CreatedAt is the time the session was created.
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+
+	// LastActivityAt is the time the session was last saved, which
+	// happens when it changes or is extended by the middleware.
+	LastActivityAt time.Time `db:"last_activity_at" json:"last_activity_at"`
+
+	// ExpiresAt is the time the session expires.
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// databaseRow is the row of a session in the table.
+type databaseRow struct {
+	// ID is the session ID.
+	ID string `db:"id"`
+
+	// UserID is the identifier of the user, if any.
+	UserID sql.NullString `db:"user_id"`
+
+	// IP is the IP address of the client that last saved the session.
+	IP string `db:"ip"`
+
+	// UserAgent is the user agent of the client that last saved the session.
+	UserAgent string `db:"user_agent"`
+
+	// CreatedAt is the time the session was created.
+	CreatedAt time.Time `db:"created_at"`
+
+	// LastActivityAt is the time the session was last saved.
+	LastActivityAt time.Time `db:"last_activity_at"`
+
+	// ExpiresAt is the time the session expires.
+	ExpiresAt time.Time `db:"expires_at"`
+
+	// Payload is the JSON-serialized session storage.
+	Payload string `db:"payload"`
+}
+
+// DatabaseDriver implements [contract.SessionDriver] by storing
+// sessions in a table of a SQL database, along with the user they
+// belong to and the client that last used them, so that the sessions
+// of a user can be listed and revoked. Sessions are revoked as soon as
+// their row is deleted.
+//
+// The table is created with [DatabaseDriver.Migrate], or by a
+// migration tool with the statements of [Schema]. Queries use named
+// parameters, which the database driver binds for its own dialect.
+//
+// WARNING: Session data is stored without encryption, and session IDs
+// are stored as is, so anyone able to read the table can hijack them.
+type DatabaseDriver struct {
+	// database runs the queries.
+	database contract.DatabaseDriver
+
+	// options stores the driver options.
+	options DatabaseDriverOptions
+
+	// cancel stops the garbage collection loop.
+	cancel context.CancelFunc
+
+	// done is closed once the garbage collection loop stopped.
+	done chan struct{}
+}
+
+// NewDatabaseDriver creates a [DatabaseDriver] that stores sessions in
+// the "sessions" table of the given database, collecting expired
+// sessions every 30 minutes in the background until
+// [DatabaseDriver.Close] is called. Register the driver as a closer
+// of the application after the database, as closers run in reverse
+// order, so that it stops before the database is closed:
+//
+//	app.Closer("database", db)
+//	app.Closer("sessions", sessions)
+func NewDatabaseDriver(database contract.DatabaseDriver) *DatabaseDriver {
+	return NewDatabaseDriverWith(database, DatabaseDriverOptions{})
+}
+
+// NewDatabaseDriverWith creates a [DatabaseDriver] with the given
+// database and options. Unless disabled, the garbage collection of
+// expired sessions runs in the background until [DatabaseDriver.Close]
+// is called, see [NewDatabaseDriver].
+//
+// It panics if the table name is not a valid identifier, as it's
+// interpolated in queries and it's a programming error.
+func NewDatabaseDriverWith(database contract.DatabaseDriver, options DatabaseDriverOptions) *DatabaseDriver {
+	options = options.withDefaults()
+
+	if !validTablePattern.MatchString(options.Table) {
+		panic("session: invalid table name " + options.Table)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	driver := &DatabaseDriver{
+		database: database,
+		options:  options,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	if options.GCInterval <= 0 {
+		close(driver.done)
+
+		return driver
+	}
+
+	go driver.collect(ctx)
+
+	return driver
+}
+
+// Schema returns the statements that create the table of the given
+// name, along with its indexes, in the given dialect.
+func Schema(dialect Dialect, table string) ([]string, error) {
+	var timestamp, payload string
+
+	switch dialect {
+	case DialectSQLite:
+		timestamp, payload = "TIMESTAMP", "TEXT"
+	case DialectPostgres:
+		timestamp, payload = "TIMESTAMPTZ", "JSONB"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDialect, dialect)
+	}
+
+	index := strings.ReplaceAll(table, ".", "_")
+
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    id TEXT PRIMARY KEY,
+    user_id TEXT,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at %[2]s NOT NULL,
+    last_activity_at %[2]s NOT NULL,
+    expires_at %[2]s NOT NULL,
+    payload %[3]s NOT NULL
+)`, table, timestamp, payload),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_user_id_index ON %s (user_id)", index, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_expires_at_index ON %s (expires_at)", index, table),
+	}, nil
+}
+
+// Migrate creates the sessions table and its indexes in the given
+// dialect, unless they already exist.
+func (driver *DatabaseDriver) Migrate(ctx context.Context, dialect Dialect) error {
+	statements, err := Schema(dialect, driver.options.Table)
+
+	if err != nil {
+		return err
+	}
+
+	return driver.database.WithTransaction(ctx, func(tx contract.DatabaseDriver) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(ctx, statement); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Get retrieves a session from the table by its ID.
+func (driver *DatabaseDriver) Get(ctx context.Context, id string) (*contract.Session, error) {
+	var row databaseRow
+
+	query := fmt.Sprintf(
+		"SELECT id, user_id, ip, user_agent, created_at, last_activity_at, expires_at, payload FROM %s WHERE id = :id",
+		driver.options.Table,
+	)
+
+	if err := driver.database.FindNamed(ctx, query, &row, map[string]any{"id": id}); err != nil {
+		return nil, err
+	}
+
+	storage := map[string]any{}
+
+	if err := json.Unmarshal([]byte(row.Payload), &storage); err != nil {
+		return nil, err
+	}
+
+	return contract.NewSessionFrom(row.ID, row.CreatedAt, row.ExpiresAt, storage), nil
+}
+
+// Save inserts or updates a session in the table, along with the user
+// it belongs to and the client of the request saving it, when the
+// session middleware does. The session expiration time is used over
+// the given TTL, which the middleware derives from it.
+func (driver *DatabaseDriver) Save(ctx context.Context, session *contract.Session, ttl time.Duration) error {
+	payload, err := json.Marshal(session.All())
+
+	if err != nil {
+		return err
+	}
+
+	row := databaseRow{
+		ID:             session.SessionID(),
+		CreatedAt:      session.CreatedAt().UTC(),
+		LastActivityAt: time.Now().UTC(),
+		ExpiresAt:      session.ExpiresAt().UTC(),
+		Payload:        string(payload),
+	}
+
+	if user, ok := session.Get(driver.options.UserKey); ok && user != nil {
+		id, err := userIdentifier(user)
+
+		if err != nil {
+			return err
+		}
+
+		row.UserID = sql.NullString{String: id, Valid: true}
+	}
+
+	if r, ok := requestFrom(ctx); ok {
+		row.IP = request.ClientIP(r)
+		row.UserAgent = r.UserAgent()
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %[1]s (id, user_id, ip, user_agent, created_at, last_activity_at, expires_at, payload)
+VALUES (:id, :user_id, :ip, :user_agent, :created_at, :last_activity_at, :expires_at, :payload)
+ON CONFLICT (id) DO UPDATE SET
+    user_id = excluded.user_id,
+    ip = COALESCE(NULLIF(excluded.ip, ''), %[1]s.ip),
+    user_agent = COALESCE(NULLIF(excluded.user_agent, ''), %[1]s.user_agent),
+    last_activity_at = excluded.last_activity_at,
+    expires_at = excluded.expires_at,
+    payload = excluded.payload`, driver.options.Table)
+
+	_, err = driver.database.ExecNamed(ctx, query, row)
+
+	return err
+}
+
+// Delete removes a session from the table by its ID.
+func (driver *DatabaseDriver) Delete(ctx context.Context, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = :id", driver.options.Table)
+
+	_, err := driver.database.ExecNamed(ctx, query, map[string]any{"id": id})
+
+	return err
+}
+
+// Sessions returns the active sessions of the given user,
+// the most recently active first.
+//
+// The activity of sessions is only recorded when they are saved, which
+// the middleware does when they change or are extended, so the last
+// activity of a session can be behind by up to the TTL minus the
+// [MiddlewareOptions.ExpirationDelta] of the middleware.
+//
+// Example usage:
+//
+//	sessions, err := driver.Sessions(r.Context(), user.ID)
+func (driver *DatabaseDriver) Sessions(ctx context.Context, user string) ([]Info, error) {
+	sessions := []Info{}
+
+	query := fmt.Sprintf(
+		"SELECT id, user_id, ip, user_agent, created_at, last_activity_at, expires_at FROM %s WHERE user_id = :user_id AND expires_at > :now ORDER BY last_activity_at DESC",
+		driver.options.Table,
+	)
+
+	arg := map[string]any{
+		"user_id": user,
+		"now":     time.Now().UTC(),
+	}
+
+	if err := driver.database.SelectNamed(ctx, query, &sessions, arg); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Revoke deletes the session of the given ID when it belongs to the
+// given user, or returns [contract.ErrDatabaseNoRows] otherwise.
+func (driver *DatabaseDriver) Revoke(ctx context.Context, user string, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = :id AND user_id = :user_id", driver.options.Table)
+
+	deleted, err := driver.database.ExecNamed(ctx, query, map[string]any{
+		"id":      id,
+		"user_id": user,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return contract.ErrDatabaseNoRows
+	}
+
+	return nil
+}
+
+// RevokeAll deletes every session of the given user, logging them out
+// of every device, and returns how many were deleted.
+func (driver *DatabaseDriver) RevokeAll(ctx context.Context, user string) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = :user_id", driver.options.Table)
+
+	return driver.database.ExecNamed(ctx, query, map[string]any{"user_id": user})
+}
+
+// RevokeOthers deletes every session of the given user but the current
+// one, logging them out of every other device, and returns how many
+// were deleted.
+//
+// Example usage:
+//
+//	sess, _ := request.Session(r)
+//	_, err := driver.RevokeOthers(r.Context(), user.ID, sess.SessionID())
+func (driver *DatabaseDriver) RevokeOthers(ctx context.Context, user string, current string) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = :user_id AND id <> :id", driver.options.Table)
+
+	return driver.database.ExecNamed(ctx, query, map[string]any{
+		"user_id": user,
+		"id":      current,
+	})
+}
+
+// GC deletes the expired sessions and returns how many were deleted.
+// It runs periodically in the background, see
+// [DatabaseDriverOptions.GCInterval].
+func (driver *DatabaseDriver) GC(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= :now", driver.options.Table)
+
+	return driver.database.ExecNamed(ctx, query, map[string]any{"now": time.Now().UTC()})
+}
+
+// Close stops the garbage collection of expired sessions, waiting
+// for a collection in progress to be cancelled. It does not close
+// the database.
+func (driver *DatabaseDriver) Close() error {
+	driver.cancel()
+	<-driver.done
+
+	return nil
+}
+
+// collect deletes the expired sessions periodically,
+// until the given context is cancelled.
+func (driver *DatabaseDriver) collect(ctx context.Context) {
+	defer close(driver.done)
+
+	ticker := time.NewTicker(driver.options.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := driver.GC(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("failed to collect expired sessions", "table", driver.options.Table, "err", err)
+		}
+	}
+}
+
+// userIdentifier returns the given user identifier as stored in the
+// table. Numbers are formatted the same way whether they are integers,
+// as put in the session, or float64, as decoded from its JSON payload,
+// so that the sessions of a user keep matching once reloaded.
+func userIdentifier(user any) (string, error) {
+	switch user := user.(type) {
+	case string:
+		return user, nil
+	case int:
+		return strconv.FormatInt(int64(user), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(user), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(user), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(user), 10), nil
+	case int64:
+		return strconv.FormatInt(user, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(user), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(user), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(user), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(user), 10), nil
+	case uint64:
+		return strconv.FormatUint(user, 10), nil
+	case float64:
+		if user != math.Trunc(user) || math.IsInf(user, 0) {
+			return "", fmt.Errorf("%w: %v", ErrUnsupportedUserID, user)
+		}
+
+		return strconv.FormatFloat(user, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnsupportedUserID, user)
+	}
+}
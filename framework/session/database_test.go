@@ -0,0 +1,463 @@
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/database"
+	"github.com/studiolambda/cosmos/framework/session"
+
+	_ "github.com/mattn/go-sqlite3"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newDatabaseDriver(database contract.DatabaseDriver) *session.DatabaseDriver {
+	return session.NewDatabaseDriverWith(database, session.DatabaseDriverOptions{GCInterval: -1})
+}
+
+func TestSchemaSupportsSQLiteAndPostgres(t *testing.T) {
+	t.Parallel()
+
+	sqlite, err := session.Schema(session.DialectSQLite, "sessions")
+
+	require.NoError(t, err)
+	require.Len(t, sqlite, 3)
+	require.Contains(t, sqlite[0], "CREATE TABLE IF NOT EXISTS sessions")
+	require.Contains(t, sqlite[0], "payload TEXT NOT NULL")
+	require.Contains(t, sqlite[1], "sessions_user_id_index ON sessions (user_id)")
+
+	postgres, err := session.Schema(session.DialectPostgres, "auth.sessions")
+
+	require.NoError(t, err)
+	require.Contains(t, postgres[0], "CREATE TABLE IF NOT EXISTS auth.sessions")
+	require.Contains(t, postgres[0], "expires_at TIMESTAMPTZ NOT NULL")
+	require.Contains(t, postgres[0], "payload JSONB NOT NULL")
+	require.Contains(t, postgres[2], "auth_sessions_expires_at_index ON auth.sessions (expires_at)")
+
+	_, err = session.Schema("mysql", "sessions")
+
+	require.ErrorIs(t, err, session.ErrUnsupportedDialect)
+}
+
+func TestDatabaseDriverMigrateRunsSchemaInTransaction(t *testing.T) {
+	t.Parallel()
+
+	tx := mock.NewDatabaseDriverMock(t)
+	tx.On("Exec", tmock.Anything, tmock.Anything).Return(int64(0), nil).Times(3)
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("WithTransaction", tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			fn := args.Get(1).(func(contract.DatabaseDriver) error)
+			require.NoError(t, fn(tx))
+		}).
+		Return(nil).
+		Once()
+
+	require.NoError(t, newDatabaseDriver(database).Migrate(t.Context(), session.DialectSQLite))
+}
+
+func TestDatabaseDriverPanicsWithInvalidTable(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		session.NewDatabaseDriverWith(mock.NewDatabaseDriverMock(t), session.DatabaseDriverOptions{
+			Table: "sessions; DROP TABLE users",
+		})
+	})
+}
+
+func TestDatabaseDriverGetDecodesRows(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Now().Add(-time.Hour).UTC()
+	expiresAt := time.Now().Add(time.Hour).UTC()
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("FindNamed", tmock.Anything, tmock.Anything, tmock.Anything, map[string]any{"id": "abc"}).
+		Run(func(args tmock.Arguments) {
+			require.Contains(t, args.String(1), "FROM sessions WHERE id = :id")
+
+			row := args.Get(2)
+			raw, err := json.Marshal(map[string]any{
+				"ID":        "abc",
+				"CreatedAt": createdAt,
+				"ExpiresAt": expiresAt,
+				"Payload":   `{"user_id":"42"}`,
+			})
+
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, row))
+		}).
+		Return(nil).
+		Once()
+
+	sess, err := newDatabaseDriver(database).Get(t.Context(), "abc")
+
+	require.NoError(t, err)
+	require.Equal(t, "abc", sess.SessionID())
+	require.WithinDuration(t, createdAt, sess.CreatedAt(), 0)
+	require.WithinDuration(t, expiresAt, sess.ExpiresAt(), 0)
+
+	userID, ok := sess.Get("user_id")
+
+	require.True(t, ok)
+	require.Equal(t, "42", userID)
+}
+
+func TestDatabaseDriverGetReturnsMissingRows(t *testing.T) {
+	t.Parallel()
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("FindNamed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+		Return(contract.ErrDatabaseNoRows).
+		Once()
+
+	_, err := newDatabaseDriver(database).Get(t.Context(), "abc")
+
+	require.ErrorIs(t, err, contract.ErrDatabaseNoRows)
+}
+
+func TestDatabaseDriverSaveUpsertsRows(t *testing.T) {
+	t.Parallel()
+
+	var query string
+	var row map[string]any
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("ExecNamed", tmock.Anything, tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			query = args.String(1)
+
+			raw, err := json.Marshal(args.Get(2))
+
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, &row))
+		}).
+		Return(int64(1), nil).
+		Once()
+
+	sess, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{"user_id": 42})
+
+	require.NoError(t, err)
+	require.NoError(t, newDatabaseDriver(database).Save(t.Context(), sess, time.Hour))
+
+	require.Contains(t, query, "INSERT INTO sessions")
+	require.Contains(t, query, "ON CONFLICT (id) DO UPDATE SET")
+	require.Equal(t, sess.SessionID(), row["ID"])
+	require.Equal(t, map[string]any{"String": "42", "Valid": true}, row["UserID"])
+	require.JSONEq(t, `{"user_id":42}`, row["Payload"].(string))
+}
+
+func TestDatabaseDriverSavesGuestSessionsWithoutUser(t *testing.T) {
+	t.Parallel()
+
+	var row map[string]any
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("ExecNamed", tmock.Anything, tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			raw, err := json.Marshal(args.Get(2))
+
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, &row))
+		}).
+		Return(int64(1), nil).
+		Once()
+
+	sess, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{})
+
+	require.NoError(t, err)
+	require.NoError(t, newDatabaseDriver(database).Save(t.Context(), sess, time.Hour))
+	require.Equal(t, map[string]any{"String": "", "Valid": false}, row["UserID"])
+}
+
+func TestDatabaseDriverKeepsNumericUserIDsAcrossReloads(t *testing.T) {
+	t.Parallel()
+
+	var rows []map[string]any
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("ExecNamed", tmock.Anything, tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			var row map[string]any
+
+			raw, err := json.Marshal(args.Get(2))
+
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, &row))
+
+			rows = append(rows, row)
+		}).
+		Return(int64(1), nil).
+		Twice()
+	database.On("FindNamed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			raw, err := json.Marshal(rows[0])
+
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, args.Get(2)))
+		}).
+		Return(nil).
+		Once()
+
+	driver := newDatabaseDriver(database)
+
+	sess, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{})
+
+	require.NoError(t, err)
+
+	sess.Put("user_id", 12345678)
+
+	require.NoError(t, driver.Save(t.Context(), sess, time.Hour))
+
+	reloaded, err := driver.Get(t.Context(), sess.SessionID())
+
+	require.NoError(t, err)
+
+	userID, _ := reloaded.Get("user_id")
+
+	require.IsType(t, float64(0), userID)
+	require.NoError(t, driver.Save(t.Context(), reloaded, time.Hour))
+
+	require.Len(t, rows, 2)
+	require.Equal(t, map[string]any{"String": "12345678", "Valid": true}, rows[0]["UserID"])
+	require.Equal(t, map[string]any{"String": "12345678", "Valid": true}, rows[1]["UserID"])
+}
+
+func TestDatabaseDriverRejectsUnsupportedUserIDs(t *testing.T) {
+	t.Parallel()
+
+	driver := newDatabaseDriver(mock.NewDatabaseDriverMock(t))
+
+	sess, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{"user_id": 1.5})
+
+	require.NoError(t, err)
+	require.ErrorIs(t, driver.Save(t.Context(), sess, time.Hour), session.ErrUnsupportedUserID)
+}
+
+func TestMiddlewareRecordsClientOfDatabaseSessions(t *testing.T) {
+	t.Parallel()
+
+	var row map[string]any
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("ExecNamed", tmock.Anything, tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			raw, err := json.Marshal(args.Get(2))
+
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(raw, &row))
+		}).
+		Return(int64(1), nil).
+		Once()
+
+	handler := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		sess, _ := request.Session(r)
+		sess.Put("user_id", "42")
+
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	req.Header.Set("User-Agent", "Cosmos/1.0")
+
+	res := session.Middleware(newDatabaseDriver(database))(handler).Record(req)
+
+	require.Len(t, res.Cookies(), 1)
+	require.Equal(t, "203.0.113.7", row["IP"])
+	require.Equal(t, "Cosmos/1.0", row["UserAgent"])
+	require.Equal(t, res.Cookies()[0].Value, row["ID"])
+}
+
+func TestDatabaseDriverListsSessionsOfUsers(t *testing.T) {
+	t.Parallel()
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("SelectNamed", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			require.Contains(t, args.String(1), "WHERE user_id = :user_id AND expires_at > :now ORDER BY last_activity_at DESC")
+			require.Equal(t, "42", args.Get(3).(map[string]any)["user_id"])
+
+			sessions := args.Get(2).(*[]session.Info)
+			*sessions = append(*sessions, session.Info{ID: "abc", UserID: "42", IP: "203.0.113.7"})
+		}).
+		Return(nil).
+		Once()
+
+	sessions, err := newDatabaseDriver(database).Sessions(t.Context(), "42")
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "abc", sessions[0].ID)
+}
+
+func TestDatabaseDriverRevokesSessionsOfUsers(t *testing.T) {
+	t.Parallel()
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("ExecNamed", tmock.Anything, tmock.MatchedBy(func(query string) bool {
+		return strings.HasSuffix(query, "WHERE id = :id AND user_id = :user_id")
+	}), map[string]any{"id": "abc", "user_id": "42"}).Return(int64(1), nil).Once()
+	database.On("ExecNamed", tmock.Anything, tmock.MatchedBy(func(query string) bool {
+		return strings.HasSuffix(query, "WHERE id = :id AND user_id = :user_id")
+	}), map[string]any{"id": "xyz", "user_id": "42"}).Return(int64(0), nil).Once()
+	database.On("ExecNamed", tmock.Anything, tmock.MatchedBy(func(query string) bool {
+		return strings.HasSuffix(query, "WHERE user_id = :user_id AND id <> :id")
+	}), map[string]any{"id": "abc", "user_id": "42"}).Return(int64(2), nil).Once()
+	database.On("ExecNamed", tmock.Anything, tmock.MatchedBy(func(query string) bool {
+		return strings.HasSuffix(query, "WHERE user_id = :user_id")
+	}), map[string]any{"user_id": "42"}).Return(int64(3), nil).Once()
+
+	driver := newDatabaseDriver(database)
+
+	require.NoError(t, driver.Revoke(t.Context(), "42", "abc"))
+	require.ErrorIs(t, driver.Revoke(t.Context(), "42", "xyz"), contract.ErrDatabaseNoRows)
+
+	others, err := driver.RevokeOthers(t.Context(), "42", "abc")
+
+	require.NoError(t, err)
+	require.Equal(t, int64(2), others)
+
+	all, err := driver.RevokeAll(t.Context(), "42")
+
+	require.NoError(t, err)
+	require.Equal(t, int64(3), all)
+}
+
+func TestDatabaseDriverCollectsExpiredSessionsPeriodically(t *testing.T) {
+	t.Parallel()
+
+	collected := make(chan struct{}, 1)
+
+	database := mock.NewDatabaseDriverMock(t)
+	database.On("ExecNamed", tmock.Anything, "DELETE FROM sessions WHERE expires_at <= :now", tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			select {
+			case collected <- struct{}{}:
+			default:
+			}
+		}).
+		Return(int64(1), nil)
+
+	driver := session.NewDatabaseDriverWith(database, session.DatabaseDriverOptions{
+		GCInterval: 10 * time.Millisecond,
+	})
+
+	select {
+	case <-collected:
+	case <-time.After(time.Second):
+		t.Fatal("expired sessions were not collected")
+	}
+
+	require.NoError(t, driver.Close())
+	require.NoError(t, driver.Close())
+
+	deleted, err := driver.GC(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
+
+func TestDatabaseDriverStoresSessionsInSQLite(t *testing.T) {
+	t.Parallel()
+
+	db, err := database.NewSQL("sqlite3", filepath.Join(t.TempDir(), "sessions.db"))
+
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	driver := newDatabaseDriver(db)
+
+	require.NoError(t, driver.Migrate(t.Context(), session.DialectSQLite))
+	require.NoError(t, driver.Migrate(t.Context(), session.DialectSQLite))
+
+	current, err := contract.NewSession(time.Now().Add(time.Hour), map[string]any{"user_id": 42})
+
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	req.Header.Set("User-Agent", "Cosmos/1.0")
+
+	saved := httptest.NewRecorder()
+	handler := session.Middleware(driver)(func(w http.ResponseWriter, r *http.Request) error {
+		request.MustSession(r).Put("user_id", 42)
+
+		return nil
+	})
+
+	handler.ServeHTTP(saved, req)
+
+	cookie := saved.Result().Cookies()[0]
+	reloaded, err := driver.Get(t.Context(), cookie.Value)
+
+	require.NoError(t, err)
+
+	reloaded.Put("theme", "dark")
+
+	require.NoError(t, driver.Save(t.Context(), reloaded, time.Hour))
+	require.NoError(t, driver.Save(t.Context(), current, time.Hour))
+
+	stored, err := driver.Get(t.Context(), cookie.Value)
+
+	require.NoError(t, err)
+
+	theme, _ := stored.Get("theme")
+
+	require.Equal(t, "dark", theme)
+
+	sessions, err := driver.Sessions(t.Context(), "42")
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	for _, info := range sessions {
+		require.Equal(t, "42", info.UserID)
+
+		if info.ID == cookie.Value {
+			require.Equal(t, "203.0.113.7", info.IP)
+			require.Equal(t, "Cosmos/1.0", info.UserAgent)
+		}
+	}
+
+	expired := contract.NewSessionFrom(
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		time.Now().Add(-2*time.Hour),
+		time.Now().Add(-time.Hour),
+		map[string]any{"user_id": "42"},
+	)
+
+	require.NoError(t, driver.Save(t.Context(), expired, time.Hour))
+
+	deleted, err := driver.GC(t.Context())
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	others, err := driver.RevokeOthers(t.Context(), "42", cookie.Value)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), others)
+
+	sessions, err = driver.Sessions(t.Context(), "42")
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, cookie.Value, sessions[0].ID)
+}
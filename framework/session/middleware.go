@@ -77,6 +77,24 @@ var validSessionIDPattern = regexp.MustCompile(
 	`^[A-Za-z0-9_-]{43}$`,
 )
 
+// requestKey is the context key of the request whose session is
+// loaded or saved by a driver, see [requestFrom].
+type requestKey struct{}
+
+// withRequest returns a copy of the context that carries the given
+// request for the drivers that record its client.
+func withRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// requestFrom returns the request carried by the context
+// of drivers called by the middleware, if any.
+func requestFrom(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(requestKey{}).(*http.Request)
+
+	return r, ok
+}
+
 // validSessionID reports whether the given ID has the expected format.
 func validSessionID(id string) bool {
 	if len(id) != expectedSessionIDLength {
@@ -89,31 +107,72 @@ func validSessionID(id string) bool {
 // currentSession loads an existing session from the cookie-provided
 // ID or creates a fresh one when no valid session is found.
 func currentSession(r *http.Request, driver contract.SessionDriver, options MiddlewareOptions) (*contract.Session, error) {
-	id := request.CookieValue(r, options.Name)
-
-	if id != "" && validSessionID(id) {
-		if session, err := driver.Get(r.Context(), id); err == nil {
-			if session.HasExpired() {
-				_ = driver.Delete(r.Context(), id)
-
-				return contract.NewSession(time.Now().Add(options.TTL), map[string]any{})
-			}
+	ctx := withRequest(r.Context(), r)
+	value := request.CookieValue(r, options.Name)
 
-			if options.MaxLifetime > 0 && time.Since(session.CreatedAt()) >= options.MaxLifetime {
-				_ = driver.Delete(r.Context(), id)
+	if session, ok := loadSession(ctx, driver, value); ok {
+		if session.HasExpired() {
+			_ = driver.Delete(ctx, session.SessionID())
 
-				return contract.NewSession(time.Now().Add(options.TTL), map[string]any{})
-			}
+			return contract.NewSession(time.Now().Add(options.TTL), map[string]any{})
+		}
 
-			session.MarkAsUnchanged()
+		if options.MaxLifetime > 0 && time.Since(session.CreatedAt()) >= options.MaxLifetime {
+			_ = driver.Delete(ctx, session.SessionID())
 
-			return session, nil
+			return contract.NewSession(time.Now().Add(options.TTL), map[string]any{})
 		}
+
+		return session, nil
 	}
 
 	return contract.NewSession(time.Now().Add(options.TTL), map[string]any{})
 }
 
+// loadSession returns the session of the given cookie value, which is
+// decoded by drivers implementing [CookieEncoder] and is the session ID
+// otherwise.
+func loadSession(ctx context.Context, driver contract.SessionDriver, value string) (*contract.Session, bool) {
+	if value == "" {
+		return nil, false
+	}
+
+	if encoder, ok := driver.(CookieEncoder); ok {
+		session, err := encoder.Decode(ctx, value)
+
+		return session, err == nil
+	}
+
+	if !validSessionID(value) {
+		return nil, false
+	}
+
+	session, err := driver.Get(ctx, value)
+
+	if err != nil {
+		return nil, false
+	}
+
+	session.MarkAsUnchanged()
+
+	return session, true
+}
+
+// storeSession saves the session with the given driver and returns
+// the cookie value, which is the encoded session for drivers
+// implementing [CookieEncoder] and the session ID otherwise.
+func storeSession(ctx context.Context, driver contract.SessionDriver, session *contract.Session, ttl time.Duration) (string, error) {
+	if encoder, ok := driver.(CookieEncoder); ok {
+		return encoder.Encode(ctx, session)
+	}
+
+	if err := driver.Save(ctx, session, ttl); err != nil {
+		return "", err
+	}
+
+	return session.SessionID(), nil
+}
+
 // withDefaults returns a copy of the options with secure defaults
 // applied to any zero-valued fields.
 func (options MiddlewareOptions) withDefaults() MiddlewareOptions {
@@ -156,7 +215,10 @@ func reportError(options MiddlewareOptions, err error) {
 }
 
 // MiddlewareWith returns a session middleware configured with the
-// given driver and options.
+// given driver and options. Flashed values are aged out with
+// [contract.Session.AgeFlashData] before the session is saved.
+// Drivers implementing [CookieEncoder], such as [CookieDriver], store
+// the whole session in the cookie instead of its ID.
 func MiddlewareWith(driver contract.SessionDriver, options MiddlewareOptions) framework.Middleware {
 	options = options.withDefaults()
 
@@ -170,7 +232,7 @@ func MiddlewareWith(driver contract.SessionDriver, options MiddlewareOptions) fr
 
 			hooks := request.Hooks(r)
 			hooks.BeforeWriteHeader(func(w http.ResponseWriter, status int) {
-				saveCtx := context.WithoutCancel(r.Context())
+				saveCtx := withRequest(context.WithoutCancel(r.Context()), r)
 
 				if options.MaxLifetime > 0 {
 					age := time.Since(session.CreatedAt())
@@ -203,10 +265,14 @@ func MiddlewareWith(driver contract.SessionDriver, options MiddlewareOptions) fr
 					)
 				}
 
+				session.AgeFlashData()
+
 				if session.HasChanged() {
 					ttl := time.Until(session.ExpiresAt())
 
-					if err := driver.Save(saveCtx, session, ttl); err != nil {
+					value, err := storeSession(saveCtx, driver, session, ttl)
+
+					if err != nil {
 						reportError(options, err)
 
 						return
@@ -214,7 +280,7 @@ func MiddlewareWith(driver contract.SessionDriver, options MiddlewareOptions) fr
 
 					http.SetCookie(w, &http.Cookie{
 						Name:        options.Name,
-						Value:       session.SessionID(),
+						Value:       value,
 						Path:        options.Path,
 						Domain:      options.Domain,
 						Expires:     session.ExpiresAt(),
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/studiolambda/cosmos/contract/mock"
 	"github.com/studiolambda/cosmos/contract/request"
 	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/cache"
 	"github.com/studiolambda/cosmos/framework/session"
 
 	tmock "github.com/stretchr/testify/mock"
@@ -556,3 +558,52 @@ func TestMiddlewareWithSecureFalseIsRespected(t *testing.T) {
 	require.Len(t, cookies, 1)
 	require.False(t, cookies[0].Secure)
 }
+
+func TestMiddlewareAgesFlashedValuesAcrossRequests(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCacheDriver(cache.NewMemory(time.Minute, time.Minute))
+
+	var status any
+	var found bool
+	var old string
+
+	handler := framework.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		sess, _ := request.Session(r)
+
+		if r.URL.Path == "/store" {
+			sess.Flash("status", "saved")
+			sess.FlashInput(url.Values{"email": {"user@example.com"}, "password": {"secret"}}, "password")
+
+			return nil
+		}
+
+		status, found = sess.Get("status")
+		old = sess.OldInput("email")
+
+		return nil
+	})
+
+	handlerWithSessions := session.MiddlewareWith(driver, session.MiddlewareOptions{})(handler)
+
+	res := handlerWithSessions.Record(httptest.NewRequest(http.MethodPost, "/store", nil))
+	cookie := res.Cookies()[0]
+
+	next := func() {
+		req := httptest.NewRequest(http.MethodGet, "/show", nil)
+		req.AddCookie(cookie)
+
+		_ = handlerWithSessions.Record(req)
+	}
+
+	next()
+
+	require.True(t, found)
+	require.Equal(t, "saved", status)
+	require.Equal(t, "user@example.com", old)
+
+	next()
+
+	require.False(t, found)
+	require.Empty(t, old)
+}
@@ -1,6 +1,8 @@
 package session_test
 
 import (
+	"encoding/json"
+	"net/url"
 	"testing"
 	"time"
 
@@ -503,3 +505,105 @@ func TestSessionPreservesDataAfterRegenerate(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, 42, val)
 }
+
+func TestSessionFlashIsAvailableUntilTheNextRequestEnds(t *testing.T) {
+	t.Parallel()
+
+	sess := contract.NewSessionFrom("id", time.Now(), time.Now().Add(time.Hour), map[string]any{})
+	sess.Flash("status", "saved")
+
+	require.True(t, sess.HasChanged())
+
+	sess.AgeFlashData()
+
+	value, ok := sess.Get("status")
+	require.True(t, ok)
+	require.Equal(t, "saved", value)
+
+	sess.AgeFlashData()
+
+	_, ok = sess.Get("status")
+	require.False(t, ok)
+	require.Empty(t, sess.All())
+}
+
+func TestSessionFlashNowIsRemovedAtTheEndOfTheRequest(t *testing.T) {
+	t.Parallel()
+
+	sess := contract.NewSessionFrom("id", time.Now(), time.Now().Add(time.Hour), map[string]any{})
+	sess.FlashNow("error", "invalid")
+
+	value, ok := sess.Get("error")
+	require.True(t, ok)
+	require.Equal(t, "invalid", value)
+
+	sess.AgeFlashData()
+
+	_, ok = sess.Get("error")
+	require.False(t, ok)
+}
+
+func TestSessionReflashAndKeepExtendFlashedValues(t *testing.T) {
+	t.Parallel()
+
+	sess := contract.NewSessionFrom("id", time.Now(), time.Now().Add(time.Hour), map[string]any{})
+	sess.Flash("status", "saved")
+	sess.Flash("warning", "slow")
+	sess.AgeFlashData()
+
+	sess.Keep("status")
+	sess.AgeFlashData()
+
+	_, status := sess.Get("status")
+	_, warning := sess.Get("warning")
+	require.True(t, status)
+	require.False(t, warning)
+
+	sess.Reflash()
+	sess.AgeFlashData()
+
+	_, status = sess.Get("status")
+	require.True(t, status)
+
+	sess.AgeFlashData()
+
+	_, status = sess.Get("status")
+	require.False(t, status)
+}
+
+func TestSessionAgeFlashDataLeavesUnflashedSessionsUnchanged(t *testing.T) {
+	t.Parallel()
+
+	sess := contract.NewSessionFrom("id", time.Now(), time.Now().Add(time.Hour), map[string]any{"user_id": 1})
+	sess.AgeFlashData()
+
+	require.False(t, sess.HasChanged())
+}
+
+func TestSessionFlashSurvivesJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sess := contract.NewSessionFrom("id", time.Now(), time.Now().Add(time.Hour), map[string]any{})
+	sess.Flash("status", "saved")
+	sess.FlashInput(url.Values{"email": {"user@example.com"}, "password": {"secret"}}, "password")
+	sess.AgeFlashData()
+
+	data, err := json.Marshal(sess.All())
+	require.NoError(t, err)
+
+	var storage map[string]any
+	require.NoError(t, json.Unmarshal(data, &storage))
+	require.NotContains(t, string(data), "secret")
+
+	loaded := contract.NewSessionFrom("id", time.Now(), time.Now().Add(time.Hour), storage)
+
+	require.Equal(t, "user@example.com", loaded.OldInput("email"))
+	require.Empty(t, loaded.OldInput("password"))
+
+	loaded.Keep("status")
+	loaded.AgeFlashData()
+
+	_, ok := loaded.Get("status")
+	require.True(t, ok)
+	require.Empty(t, loaded.OldInput("email"))
+}
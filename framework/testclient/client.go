@@ -0,0 +1,296 @@
+// Package testclient provides a fluent client to write integration
+// tests against a [framework.Handler], a [framework.Router] or any
+// other [http.Handler] without starting a server.
+//
+// Requests are dispatched in memory and cookies set by the responses
+// are persisted across calls, so flows that span several requests,
+// such as logging in and then reading a session, can be tested end
+// to end:
+//
+//	client := testclient.New(t, app)
+//
+//	client.PostJSON("/login", credentials).AssertStatus(http.StatusNoContent)
+//	client.GetJSON("/me").AssertStatus(http.StatusOK).AssertJSON(expected)
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/framework/session"
+)
+
+// Client dispatches requests to an [http.Handler] and records their
+// responses. Headers and cookies configured on the client are sent
+// with every subsequent request, and the cookies set by responses
+// are stored the same way a browser would.
+//
+// A Client is not safe for concurrent use.
+type Client struct {
+	// t stores the test the client reports failures to.
+	t testing.TB
+
+	// handler stores the handler requests are dispatched to.
+	handler http.Handler
+
+	// header stores the headers sent with every request.
+	header http.Header
+
+	// cookies stores the cookies sent with every request
+	// keyed by their name.
+	cookies map[string]*http.Cookie
+
+	// sessionCookie stores the name of the cookie used by
+	// [Client.WithSession] and [Client.Session].
+	sessionCookie string
+}
+
+// New creates a [Client] that dispatches requests to the given handler
+// and reports failures to the given test.
+func New(t testing.TB, handler http.Handler) *Client {
+	return &Client{
+		t:             t,
+		handler:       handler,
+		header:        make(http.Header),
+		cookies:       make(map[string]*http.Cookie),
+		sessionCookie: session.DefaultCookie,
+	}
+}
+
+// WithHeader sets a header that is sent with every subsequent request.
+func (client *Client) WithHeader(key string, value string) *Client {
+	client.header.Set(key, value)
+
+	return client
+}
+
+// WithoutHeader removes a header previously set with [Client.WithHeader].
+func (client *Client) WithoutHeader(key string) *Client {
+	client.header.Del(key)
+
+	return client
+}
+
+// WithCookie stores a cookie that is sent with every subsequent
+// request, as if it had been set by a previous response.
+func (client *Client) WithCookie(cookie *http.Cookie) *Client {
+	client.cookies[cookie.Name] = cookie
+
+	return client
+}
+
+// WithoutCookie removes the cookie with the given name.
+func (client *Client) WithoutCookie(name string) *Client {
+	delete(client.cookies, name)
+
+	return client
+}
+
+// WithSessionCookie sets the name of the session cookie used by
+// [Client.WithSession] and [Client.Session]. Defaults to
+// [session.DefaultCookie].
+func (client *Client) WithSessionCookie(name string) *Client {
+	client.sessionCookie = name
+
+	return client
+}
+
+// WithSession creates a session with the given values, saves it
+// using the given driver and stores its cookie, so that subsequent
+// requests are made on behalf of it. The driver must be the same
+// one used by the session middleware of the handler. Drivers that
+// implement [session.CookieEncoder] store the encoded session in the
+// cookie instead of its ID, as the middleware does.
+func (client *Client) WithSession(driver contract.SessionDriver, values map[string]any) *Client {
+	client.t.Helper()
+
+	current, err := contract.NewSession(time.Now().Add(session.DefaultTTL), values)
+
+	if err != nil {
+		client.t.Fatalf("testclient: unable to create session: %v", err)
+	}
+
+	value := current.SessionID()
+
+	if encoder, ok := driver.(session.CookieEncoder); ok {
+		value, err = encoder.Encode(client.t.Context(), current)
+	} else {
+		err = driver.Save(client.t.Context(), current, session.DefaultTTL)
+	}
+
+	if err != nil {
+		client.t.Fatalf("testclient: unable to save session: %v", err)
+	}
+
+	return client.WithCookie(&http.Cookie{
+		Name:  client.sessionCookie,
+		Value: value,
+	})
+}
+
+// Session returns the session whose cookie is currently stored by
+// the client, loaded using the given driver. It fails the test if
+// there's no session cookie or if the session can't be loaded.
+func (client *Client) Session(driver contract.SessionDriver) *contract.Session {
+	client.t.Helper()
+
+	cookie, ok := client.cookies[client.sessionCookie]
+
+	if !ok {
+		client.t.Fatalf("testclient: no %q session cookie is stored", client.sessionCookie)
+	}
+
+	current, err := driver.Get(client.t.Context(), cookie.Value)
+
+	if err != nil {
+		client.t.Fatalf("testclient: unable to load session: %v", err)
+	}
+
+	return current
+}
+
+// Cookie returns the stored cookie with the given name, or nil if
+// there's none.
+func (client *Client) Cookie(name string) *http.Cookie {
+	return client.cookies[name]
+}
+
+// Get dispatches a GET request to the given target.
+func (client *Client) Get(target string) *Response {
+	return client.Request(http.MethodGet, target, nil)
+}
+
+// GetJSON dispatches a GET request to the given target that
+// accepts a JSON response.
+func (client *Client) GetJSON(target string) *Response {
+	return client.JSON(http.MethodGet, target, nil)
+}
+
+// Head dispatches a HEAD request to the given target.
+func (client *Client) Head(target string) *Response {
+	return client.Request(http.MethodHead, target, nil)
+}
+
+// Post dispatches a POST request with the given body to the target.
+func (client *Client) Post(target string, body io.Reader) *Response {
+	return client.Request(http.MethodPost, target, body)
+}
+
+// PostJSON dispatches a POST request with the given value encoded
+// as JSON to the target.
+func (client *Client) PostJSON(target string, value any) *Response {
+	return client.JSON(http.MethodPost, target, value)
+}
+
+// Put dispatches a PUT request with the given body to the target.
+func (client *Client) Put(target string, body io.Reader) *Response {
+	return client.Request(http.MethodPut, target, body)
+}
+
+// PutJSON dispatches a PUT request with the given value encoded
+// as JSON to the target.
+func (client *Client) PutJSON(target string, value any) *Response {
+	return client.JSON(http.MethodPut, target, value)
+}
+
+// Patch dispatches a PATCH request with the given body to the target.
+func (client *Client) Patch(target string, body io.Reader) *Response {
+	return client.Request(http.MethodPatch, target, body)
+}
+
+// PatchJSON dispatches a PATCH request with the given value encoded
+// as JSON to the target.
+func (client *Client) PatchJSON(target string, value any) *Response {
+	return client.JSON(http.MethodPatch, target, value)
+}
+
+// Delete dispatches a DELETE request to the given target.
+func (client *Client) Delete(target string) *Response {
+	return client.Request(http.MethodDelete, target, nil)
+}
+
+// DeleteJSON dispatches a DELETE request to the given target that
+// accepts a JSON response.
+func (client *Client) DeleteJSON(target string) *Response {
+	return client.JSON(http.MethodDelete, target, nil)
+}
+
+// JSON dispatches a request that accepts a JSON response. When the
+// given value is not nil, it's encoded as the JSON request body.
+func (client *Client) JSON(method string, target string, value any) *Response {
+	client.t.Helper()
+
+	var body io.Reader
+
+	if value != nil {
+		encoded, err := json.Marshal(value)
+
+		if err != nil {
+			client.t.Fatalf("testclient: unable to encode request body: %v", err)
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	request := httptest.NewRequestWithContext(client.t.Context(), method, target, body)
+	request.Header.Set("Accept", "application/json")
+
+	if value != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	return client.Do(request)
+}
+
+// Request dispatches a request with the given method, target and body.
+func (client *Client) Request(method string, target string, body io.Reader) *Response {
+	client.t.Helper()
+
+	return client.Do(httptest.NewRequestWithContext(client.t.Context(), method, target, body))
+}
+
+// Do dispatches the given request after adding the client headers
+// and cookies to it. Headers already set on the request take
+// precedence over the client ones. The cookies set by the response
+// are stored for subsequent requests.
+func (client *Client) Do(request *http.Request) *Response {
+	client.t.Helper()
+
+	for key, values := range client.header {
+		if _, ok := request.Header[key]; !ok {
+			request.Header[key] = values
+		}
+	}
+
+	for _, cookie := range client.cookies {
+		request.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	recorder := httptest.NewRecorder()
+	client.handler.ServeHTTP(recorder, request)
+	result := recorder.Result()
+
+	for _, cookie := range result.Cookies() {
+		client.store(cookie)
+	}
+
+	return newResponse(client.t, result)
+}
+
+// store stores the given cookie set by a response, or removes the
+// stored one when the response expires it.
+func (client *Client) store(cookie *http.Cookie) {
+	if cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && cookie.Expires.Before(time.Now())) {
+		delete(client.cookies, cookie.Name)
+
+		return
+	}
+
+	client.cookies[cookie.Name] = cookie
+}
@@ -0,0 +1,176 @@
+package testclient_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/cache"
+	"github.com/studiolambda/cosmos/framework/crypto"
+	"github.com/studiolambda/cosmos/framework/session"
+	"github.com/studiolambda/cosmos/framework/testclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSendsHeaders(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, request.Header(r, "X-Tenant"))
+	})
+
+	client := testclient.New(t, app).WithHeader("X-Tenant", "acme")
+
+	client.Get("/").AssertStatus(http.StatusOK)
+	require.Equal(t, "acme", client.Get("/").String())
+	require.Empty(t, client.WithoutHeader("X-Tenant").Get("/").String())
+}
+
+func TestClientSendsJSONBody(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	app := framework.New()
+	app.Post("/users", func(w http.ResponseWriter, r *http.Request) error {
+		user, err := request.JSON[payload](r)
+
+		if err != nil {
+			return err
+		}
+
+		return response.JSON(w, http.StatusCreated, user)
+	})
+
+	testclient.New(t, app).
+		PostJSON("/users", payload{Name: "Erik"}).
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Content-Type", "application/json").
+		AssertJSON(map[string]any{"name": "Erik"})
+}
+
+func TestClientSendsRawBody(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Put("/echo", func(w http.ResponseWriter, r *http.Request) error {
+		body, err := request.String(r)
+
+		if err != nil {
+			return err
+		}
+
+		return response.String(w, http.StatusOK, body)
+	})
+
+	res := testclient.New(t, app).Put("/echo", strings.NewReader("hello"))
+
+	require.Equal(t, "hello", res.String())
+}
+
+func TestClientPersistsCookies(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Post("/login", func(w http.ResponseWriter, r *http.Request) error {
+		http.SetCookie(w, &http.Cookie{Name: "token", Value: "secret"})
+
+		return nil
+	})
+	app.Post("/logout", func(w http.ResponseWriter, r *http.Request) error {
+		http.SetCookie(w, &http.Cookie{Name: "token", MaxAge: -1})
+
+		return nil
+	})
+	app.Get("/me", func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, request.CookieValue(r, "token"))
+	})
+
+	client := testclient.New(t, app)
+
+	client.Post("/login", nil).AssertCookie("token", "secret")
+	require.Equal(t, "secret", client.Get("/me").String())
+
+	client.Post("/logout", nil)
+	require.Empty(t, client.Get("/me").String())
+}
+
+func TestClientWithCookie(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, request.CookieValue(r, "theme"))
+	})
+
+	client := testclient.New(t, app).WithCookie(&http.Cookie{Name: "theme", Value: "dark"})
+
+	require.Equal(t, "dark", client.Get("/").String())
+	require.Equal(t, "dark", client.Cookie("theme").Value)
+	require.Empty(t, client.WithoutCookie("theme").Get("/").String())
+}
+
+func TestClientWithSession(t *testing.T) {
+	t.Parallel()
+
+	driver := session.NewCacheDriver(cache.NewMemory(time.Minute, time.Minute))
+
+	app := framework.New()
+	app.Use(session.Middleware(driver))
+	app.Get("/me", func(w http.ResponseWriter, r *http.Request) error {
+		current := request.MustSession(r)
+		user, _ := current.Get("user")
+
+		current.Put("visited", true)
+
+		return response.JSON(w, http.StatusOK, user)
+	})
+
+	client := testclient.New(t, app).WithSession(driver, map[string]any{"user": "erik"})
+
+	client.GetJSON("/me").AssertStatus(http.StatusOK).AssertJSON("erik")
+
+	visited, ok := client.Session(driver).Get("visited")
+
+	require.True(t, ok)
+	require.Equal(t, true, visited)
+}
+
+func TestClientWithCookieSession(t *testing.T) {
+	t.Parallel()
+
+	encrypter, err := crypto.NewAES(bytes.Repeat([]byte{1}, 32))
+
+	require.NoError(t, err)
+
+	driver := session.NewCookieDriver(encrypter)
+
+	app := framework.New()
+	app.Use(session.Middleware(driver))
+	app.Get("/me", func(w http.ResponseWriter, r *http.Request) error {
+		current := request.MustSession(r)
+		user, _ := current.Get("user")
+
+		current.Put("visited", true)
+
+		return response.JSON(w, http.StatusOK, user)
+	})
+
+	client := testclient.New(t, app).WithSession(driver, map[string]any{"user": "erik"})
+
+	client.GetJSON("/me").AssertStatus(http.StatusOK).AssertJSON("erik")
+
+	visited, ok := client.Session(driver).Get("visited")
+
+	require.True(t, ok)
+	require.Equal(t, true, visited)
+}
@@ -0,0 +1,188 @@
+package testclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/studiolambda/cosmos/problem"
+)
+
+// Response is the recorded response of a request dispatched by a
+// [Client]. It embeds the [http.Response], whose body can be read
+// as many times as needed, and provides fluent assertions that fail
+// the test when not met.
+type Response struct {
+	*http.Response
+
+	// t stores the test the response reports failures to.
+	t testing.TB
+
+	// body stores the whole response body.
+	body []byte
+}
+
+// newResponse reads the body of the given result and creates
+// the [Response] that wraps it.
+func newResponse(t testing.TB, result *http.Response) *Response {
+	t.Helper()
+
+	body, err := io.ReadAll(result.Body)
+
+	if err != nil {
+		t.Fatalf("testclient: unable to read response body: %v", err)
+	}
+
+	result.Body = io.NopCloser(bytes.NewReader(body))
+
+	return &Response{
+		Response: result,
+		t:        t,
+		body:     body,
+	}
+}
+
+// Bytes returns the response body.
+func (response *Response) Bytes() []byte {
+	return response.body
+}
+
+// String returns the response body as a string.
+func (response *Response) String() string {
+	return string(response.body)
+}
+
+// Decode decodes the JSON response body into the given target,
+// failing the test if the body is not valid JSON.
+func (response *Response) Decode(target any) *Response {
+	response.t.Helper()
+
+	if err := json.Unmarshal(response.body, target); err != nil {
+		response.t.Fatalf("testclient: unable to decode response body %q: %v", response.body, err)
+	}
+
+	return response
+}
+
+// Problem decodes the response body as a [problem.Problem],
+// including its extensions, which can be read with
+// [problem.Problem.Additional]. Requests must accept a JSON
+// response, e.g. with [Client.GetJSON], as problems are written
+// as plain text otherwise.
+func (response *Response) Problem() problem.Problem {
+	response.t.Helper()
+
+	var decoded problem.Problem
+
+	response.Decode(&decoded)
+
+	return decoded
+}
+
+// AssertStatus asserts that the response has the given status code.
+func (response *Response) AssertStatus(status int) *Response {
+	response.t.Helper()
+
+	if response.StatusCode != status {
+		response.t.Fatalf(
+			"testclient: expected status %d but got %d with body %q",
+			status, response.StatusCode, response.body,
+		)
+	}
+
+	return response
+}
+
+// AssertHeader asserts that the response has the given header value.
+func (response *Response) AssertHeader(key string, value string) *Response {
+	response.t.Helper()
+
+	if actual := response.Header.Get(key); actual != value {
+		response.t.Fatalf("testclient: expected header %s to be %q but got %q", key, value, actual)
+	}
+
+	return response
+}
+
+// AssertHeaderMissing asserts that the response does not have the
+// given header.
+func (response *Response) AssertHeaderMissing(key string) *Response {
+	response.t.Helper()
+
+	if values := response.Header.Values(key); len(values) > 0 {
+		response.t.Fatalf("testclient: expected header %s to be missing but got %q", key, values)
+	}
+
+	return response
+}
+
+// AssertCookie asserts that the response sets the cookie with the
+// given name and value.
+func (response *Response) AssertCookie(name string, value string) *Response {
+	response.t.Helper()
+
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == name && cookie.Value == value {
+			return response
+		}
+	}
+
+	response.t.Fatalf("testclient: expected cookie %s to be set to %q", name, value)
+
+	return response
+}
+
+// AssertJSON asserts that the response body is the JSON encoding
+// of the given value. Both are compared once decoded, so the key
+// order and the whitespace don't matter.
+func (response *Response) AssertJSON(expected any) *Response {
+	response.t.Helper()
+
+	encoded, err := json.Marshal(expected)
+
+	if err != nil {
+		response.t.Fatalf("testclient: unable to encode expected value: %v", err)
+	}
+
+	var want, got any
+
+	if err := json.Unmarshal(encoded, &want); err != nil {
+		response.t.Fatalf("testclient: unable to decode expected value: %v", err)
+	}
+
+	response.Decode(&got)
+
+	if !reflect.DeepEqual(want, got) {
+		response.t.Fatalf("testclient: expected JSON body %s but got %s", encoded, response.body)
+	}
+
+	return response
+}
+
+// AssertProblem asserts that the response is a problem with the
+// given type and status code, both in the response status and in
+// the decoded body. An empty type matches "about:blank", which is
+// the type of the problems that don't set one.
+func (response *Response) AssertProblem(typ string, status int) *Response {
+	response.t.Helper()
+	response.AssertStatus(status)
+
+	if typ == "" {
+		typ = "about:blank"
+	}
+
+	decoded := response.Problem()
+
+	if decoded.Type != typ {
+		response.t.Fatalf("testclient: expected problem type %q but got %q", typ, decoded.Type)
+	}
+
+	if decoded.Status != status {
+		response.t.Fatalf("testclient: expected problem status %d but got %d", status, decoded.Status)
+	}
+
+	return response
+}
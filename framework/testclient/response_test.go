@@ -0,0 +1,92 @@
+package testclient_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract/response"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/testclient"
+	"github.com/studiolambda/cosmos/problem"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseAssertProblem(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return problem.Problem{
+			Type:   "https://example.com/problems/out-of-credit",
+			Title:  "You do not have enough credit.",
+			Status: http.StatusForbidden,
+		}.With("balance", 30)
+	})
+
+	res := testclient.New(t, app).
+		GetJSON("/").
+		AssertProblem("https://example.com/problems/out-of-credit", http.StatusForbidden)
+
+	balance, ok := res.Problem().Additional("balance")
+
+	require.True(t, ok)
+	require.InDelta(t, 30, balance, 0)
+}
+
+func TestResponseAssertProblemDefaultsToBlankType(t *testing.T) {
+	t.Parallel()
+
+	testclient.New(t, framework.New()).
+		GetJSON("/missing").
+		AssertProblem("", http.StatusNotFound)
+}
+
+func TestResponseDecode(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return response.JSON(w, http.StatusOK, []int{1, 2, 3})
+	})
+
+	var numbers []int
+
+	testclient.New(t, app).Get("/").Decode(&numbers)
+
+	require.Equal(t, []int{1, 2, 3}, numbers)
+}
+
+func TestResponseBodyCanBeReadAgain(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return response.String(w, http.StatusOK, "hello")
+	})
+
+	res := testclient.New(t, app).Get("/")
+
+	require.Equal(t, "hello", res.String())
+	require.Equal(t, []byte("hello"), res.Bytes())
+
+	body := make([]byte, 5)
+	_, err := res.Body.Read(body)
+
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(body))
+}
+
+func TestResponseAssertHeaderMissing(t *testing.T) {
+	t.Parallel()
+
+	app := framework.New()
+	app.Get("/", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+
+	testclient.New(t, app).
+		Get("/").
+		AssertStatus(http.StatusNoContent).
+		AssertHeaderMissing("Content-Type")
+}
@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// DatabaseOptions configures the database tracing of [DatabaseWith].
+type DatabaseOptions struct {
+	// Name identifies the database in the "db.namespace" attribute.
+	Name string
+
+	// Comment appends the trace context to every query as an SQL
+	// comment, following the sqlcommenter format, so that database
+	// logs and query insights can be correlated with the trace.
+	// Queries differ on every call then, which defeats statement
+	// caches, so it's disabled by default.
+	Comment bool
+}
+
+// Database wraps the given database driver so that its queries are
+// traced with the given tracer, see [DatabaseWith].
+func Database(tracer *Tracer, name string, driver contract.DatabaseDriver) contract.DatabaseDriver {
+	return DatabaseWith(tracer, driver, DatabaseOptions{Name: name})
+}
+
+// DatabaseWith wraps the given database driver so that every query
+// gets a client span, named after its operation, one of "exec",
+// "select", "find" or "transaction", and describing the query. Failed
+// queries mark their span as failed, except finds that match no rows.
+//
+// Transactions get a span of their own, but the queries within them
+// are children of the span of the context they're given, as the
+// transaction function does not receive one.
+func DatabaseWith(tracer *Tracer, driver contract.DatabaseDriver, options DatabaseOptions) contract.DatabaseDriver {
+	return &databaseDriver{tracer: tracer, driver: driver, options: options}
+}
+
+// databaseDriver is a [contract.DatabaseDriver] that
+// traces the queries of another one.
+type databaseDriver struct {
+	// tracer starts the spans.
+	tracer *Tracer
+
+	// driver stores the traced driver.
+	driver contract.DatabaseDriver
+
+	// options stores the tracing options.
+	options DatabaseOptions
+}
+
+// Close closes the traced driver.
+func (database *databaseDriver) Close() error {
+	return database.driver.Close()
+}
+
+// Ping pings the traced driver.
+func (database *databaseDriver) Ping(ctx context.Context) error {
+	return database.driver.Ping(ctx)
+}
+
+// Exec executes the given query within an "exec" span.
+func (database *databaseDriver) Exec(ctx context.Context, query string, args ...any) (int64, error) {
+	ctx, span, query := database.start(ctx, "exec", query)
+	affected, err := database.driver.Exec(ctx, query, args...)
+	database.end(span, err)
+
+	return affected, err
+}
+
+// ExecNamed executes the given query within an "exec" span.
+func (database *databaseDriver) ExecNamed(ctx context.Context, query string, arg any) (int64, error) {
+	ctx, span, query := database.start(ctx, "exec", query)
+	affected, err := database.driver.ExecNamed(ctx, query, arg)
+	database.end(span, err)
+
+	return affected, err
+}
+
+// Select executes the given query within a "select" span.
+func (database *databaseDriver) Select(ctx context.Context, query string, dest any, args ...any) error {
+	ctx, span, query := database.start(ctx, "select", query)
+	err := database.driver.Select(ctx, query, dest, args...)
+	database.end(span, err)
+
+	return err
+}
+
+// SelectNamed executes the given query within a "select" span.
+func (database *databaseDriver) SelectNamed(ctx context.Context, query string, dest any, arg any) error {
+	ctx, span, query := database.start(ctx, "select", query)
+	err := database.driver.SelectNamed(ctx, query, dest, arg)
+	database.end(span, err)
+
+	return err
+}
+
+// Find executes the given query within a "find" span.
+func (database *databaseDriver) Find(ctx context.Context, query string, dest any, args ...any) error {
+	ctx, span, query := database.start(ctx, "find", query)
+	err := database.driver.Find(ctx, query, dest, args...)
+	database.end(span, err)
+
+	return err
+}
+
+// FindNamed executes the given query within a "find" span.
+func (database *databaseDriver) FindNamed(ctx context.Context, query string, dest any, arg any) error {
+	ctx, span, query := database.start(ctx, "find", query)
+	err := database.driver.FindNamed(ctx, query, dest, arg)
+	database.end(span, err)
+
+	return err
+}
+
+// WithTransaction runs fn in a transaction within a "transaction"
+// span, tracing the queries of fn as well.
+func (database *databaseDriver) WithTransaction(ctx context.Context, fn func(tx contract.DatabaseDriver) error) error {
+	ctx, span, _ := database.start(ctx, "transaction", "")
+
+	err := database.driver.WithTransaction(ctx, func(tx contract.DatabaseDriver) error {
+		return fn(&databaseDriver{
+			tracer:  database.tracer,
+			driver:  tx,
+			options: database.options,
+		})
+	})
+
+	database.end(span, err)
+
+	return err
+}
+
+// start starts the span of the given operation and query, returning
+// the query to execute, which carries the trace context when enabled.
+func (database *databaseDriver) start(ctx context.Context, operation string, query string) (context.Context, *Span, string) {
+	attrs := []slog.Attr{slog.String("db.operation.name", operation)}
+
+	if database.options.Name != "" {
+		attrs = append(attrs, slog.String("db.namespace", database.options.Name))
+	}
+
+	if query != "" {
+		attrs = append(attrs, slog.String("db.query.text", query))
+	}
+
+	ctx, span := database.tracer.Start(ctx, operation, SpanKindClient, attrs...)
+
+	if database.options.Comment && query != "" {
+		query = comment(query, span.Context())
+	}
+
+	return ctx, span, query
+}
+
+// end ends the given span, marking it as failed when
+// the query failed for a reason other than no rows.
+func (database *databaseDriver) end(span *Span, err error) {
+	if err != nil && !errors.Is(err, contract.ErrDatabaseNoRows) {
+		span.RecordError(err)
+		span.SetStatus(StatusError, err.Error())
+	}
+
+	span.End()
+}
+
+// comment appends the given span context to the given query as an
+// sqlcommenter comment, unless the query already ends with one.
+func comment(query string, spanContext SpanContext) string {
+	trimmed := strings.TrimRight(query, " \t\r\n;")
+
+	if strings.HasSuffix(trimmed, "*/") {
+		return query
+	}
+
+	pairs := "traceparent='" + url.QueryEscape(spanContext.Traceparent()) + "'"
+
+	if spanContext.TraceState != "" {
+		pairs += ",tracestate='" + url.QueryEscape(spanContext.TraceState) + "'"
+	}
+
+	return trimmed + " /*" + pairs + "*/"
+}
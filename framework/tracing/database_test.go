@@ -0,0 +1,101 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/studiolambda/cosmos/contract"
+	"github.com/studiolambda/cosmos/contract/mock"
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseTracesQueries(t *testing.T) {
+	t.Parallel()
+
+	driver := mock.NewDatabaseDriverMock(t)
+	driver.On("Exec", tmock.Anything, "DELETE FROM users").Return(int64(1), nil).Once()
+	driver.On("Find", tmock.Anything, "SELECT 1", tmock.Anything).Return(contract.ErrDatabaseNoRows).Once()
+	driver.On("Select", tmock.Anything, "SELECT *", tmock.Anything).Return(errors.New("down")).Once()
+
+	exporter := tracing.NewMemoryExporter()
+	db := tracing.Database(tracing.New(exporter), "main", driver)
+
+	var dest []int
+
+	_, err := db.Exec(t.Context(), "DELETE FROM users")
+	require.NoError(t, err)
+	require.ErrorIs(t, db.Find(t.Context(), "SELECT 1", &dest), contract.ErrDatabaseNoRows)
+	require.EqualError(t, db.Select(t.Context(), "SELECT *", &dest), "down")
+
+	spans := exporter.Spans()
+
+	require.Len(t, spans, 3)
+	require.Equal(t, "exec", spans[0].Name)
+	require.Equal(t, tracing.SpanKindClient, spans[0].Kind)
+	require.Contains(t, spans[0].Attributes, slog.String("db.namespace", "main"))
+	require.Contains(t, spans[0].Attributes, slog.String("db.query.text", "DELETE FROM users"))
+	require.Equal(t, tracing.StatusUnset, spans[1].Status)
+	require.Equal(t, tracing.StatusError, spans[2].Status)
+}
+
+func TestDatabaseTracesTransactions(t *testing.T) {
+	t.Parallel()
+
+	tx := mock.NewDatabaseDriverMock(t)
+	tx.On("Exec", tmock.Anything, "UPDATE users").Return(int64(1), nil).Once()
+
+	driver := mock.NewDatabaseDriverMock(t)
+	driver.On("WithTransaction", tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			fn := args.Get(1).(func(contract.DatabaseDriver) error)
+			require.NoError(t, fn(tx))
+		}).
+		Return(nil).
+		Once()
+
+	exporter := tracing.NewMemoryExporter()
+	db := tracing.Database(tracing.New(exporter), "main", driver)
+
+	require.NoError(t, db.WithTransaction(t.Context(), func(tx contract.DatabaseDriver) error {
+		_, err := tx.Exec(t.Context(), "UPDATE users")
+
+		return err
+	}))
+
+	spans := exporter.Spans()
+
+	require.Len(t, spans, 2)
+	require.Equal(t, "exec", spans[0].Name)
+	require.Equal(t, "transaction", spans[1].Name)
+}
+
+func TestDatabaseCommentsQueries(t *testing.T) {
+	t.Parallel()
+
+	var executed string
+
+	driver := mock.NewDatabaseDriverMock(t)
+	driver.On("Exec", tmock.Anything, tmock.Anything).
+		Run(func(args tmock.Arguments) {
+			executed = args.String(1)
+		}).
+		Return(int64(1), nil).
+		Once()
+
+	tracer := tracing.New(tracing.NewMemoryExporter())
+	db := tracing.DatabaseWith(tracer, driver, tracing.DatabaseOptions{Comment: true})
+	ctx, span := tracer.Start(context.Background(), "request", tracing.SpanKindServer)
+
+	_, err := db.Exec(ctx, "DELETE FROM users;")
+	span.End()
+
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(executed, "DELETE FROM users /*traceparent='00-"+span.Context().TraceID.String()+"-"), executed)
+	require.True(t, strings.HasSuffix(executed, "-01'*/"), executed)
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/studiolambda/cosmos/contract"
+)
+
+// Events wraps the given event driver so that publishing and
+// processing events is traced with the given tracer.
+//
+// Published events get a producer span, and their payload is wrapped
+// in an envelope carrying its trace context, which is unwrapped before
+// delivery, so both publishers and subscribers must use the wrapped
+// driver. Delivered events get a consumer span that continues the
+// trace of the publisher. Payloads without an envelope, such as those
+// of untraced publishers, are delivered as is, and payloads that are
+// not JSON, which can't be wrapped, are published as is.
+//
+// [contract.EventHandler] does not receive a context, so spans can't
+// be started as children of the consumer span.
+func Events(tracer *Tracer, driver contract.EventDriver) contract.EventDriver {
+	return &eventDriver{tracer: tracer, driver: driver}
+}
+
+// eventEnvelope wraps the payload of traced events.
+type eventEnvelope struct {
+	// Trace stores the trace context of the publisher.
+	Trace *eventTrace `json:"$trace"`
+
+	// Payload stores the original payload.
+	Payload json.RawMessage `json:"$payload"`
+}
+
+// eventTrace is the propagated trace context of an event.
+type eventTrace struct {
+	// Traceparent stores the traceparent of the producer span.
+	Traceparent string `json:"traceparent"`
+
+	// Tracestate stores the tracestate of the producer span.
+	Tracestate string `json:"tracestate,omitempty"`
+}
+
+// eventDriver is a [contract.EventDriver] that traces
+// the events of another one.
+type eventDriver struct {
+	// tracer starts the spans.
+	tracer *Tracer
+
+	// driver stores the traced driver.
+	driver contract.EventDriver
+}
+
+// Publish publishes the given event within a producer span,
+// wrapping its payload in an envelope.
+func (events *eventDriver) Publish(ctx context.Context, event string, payload []byte) error {
+	ctx, span := events.tracer.Start(
+		ctx,
+		"publish "+event,
+		SpanKindProducer,
+		slog.String("messaging.operation.type", "publish"),
+		slog.String("messaging.destination.name", event),
+	)
+
+	defer span.End()
+
+	if json.Valid(payload) {
+		spanContext := span.Context()
+		envelope, err := json.Marshal(eventEnvelope{
+			Trace: &eventTrace{
+				Traceparent: spanContext.Traceparent(),
+				Tracestate:  spanContext.TraceState,
+			},
+			Payload: payload,
+		})
+
+		if err == nil {
+			payload = envelope
+		}
+	}
+
+	if err := events.driver.Publish(ctx, event, payload); err != nil {
+		span.RecordError(err)
+		span.SetStatus(StatusError, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+// Subscribe subscribes the given handler to the given event,
+// unwrapping the payloads and calling it within a consumer span.
+func (events *eventDriver) Subscribe(ctx context.Context, event string, handler contract.EventHandler) (contract.EventUnsubscribeFunc, error) {
+	return events.driver.Subscribe(ctx, event, func(payload []byte) {
+		parent := context.Background()
+
+		var envelope eventEnvelope
+
+		if err := json.Unmarshal(payload, &envelope); err == nil && envelope.Trace != nil && envelope.Payload != nil {
+			payload = envelope.Payload
+
+			if spanContext, err := ParseTraceparent(envelope.Trace.Traceparent); err == nil {
+				spanContext.TraceState = envelope.Trace.Tracestate
+				parent = ContextWithRemoteSpanContext(parent, spanContext)
+			}
+		}
+
+		_, span := events.tracer.Start(
+			parent,
+			"process "+event,
+			SpanKindConsumer,
+			slog.String("messaging.operation.type", "process"),
+			slog.String("messaging.destination.name", event),
+		)
+
+		defer span.End()
+
+		handler(payload)
+	})
+}
+
+// Close closes the traced driver.
+func (events *eventDriver) Close() error {
+	return events.driver.Close()
+}
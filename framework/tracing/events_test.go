@@ -0,0 +1,96 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/event"
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receive returns the next payload of the given channel.
+func receive(t *testing.T, payloads chan []byte) []byte {
+	t.Helper()
+
+	select {
+	case payload := <-payloads:
+		return payload
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+
+		return nil
+	}
+}
+
+func TestEventsPropagateTraceContext(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+	broker := event.NewMemoryBroker()
+	events := tracing.Events(tracer, broker)
+
+	defer events.Close()
+
+	received := make(chan []byte, 1)
+
+	unsubscribe, err := events.Subscribe(t.Context(), "user.*", func(payload []byte) {
+		received <- payload
+	})
+
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	ctx, parent := tracer.Start(context.Background(), "request", tracing.SpanKindServer)
+
+	require.NoError(t, events.Publish(ctx, "user.created", []byte(`{"id":1}`)))
+	require.JSONEq(t, `{"id":1}`, string(receive(t, received)))
+
+	parent.End()
+
+	require.Eventually(t, func() bool {
+		return len(exporter.Spans()) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	spans := map[string]tracing.SpanData{}
+
+	for _, span := range exporter.Spans() {
+		spans[span.Name] = span
+	}
+
+	producer := spans["publish user.created"]
+	consumer := spans["process user.*"]
+
+	require.Equal(t, tracing.SpanKindProducer, producer.Kind)
+	require.Equal(t, parent.Context().SpanID, producer.Parent)
+	require.Equal(t, tracing.SpanKindConsumer, consumer.Kind)
+	require.Equal(t, producer.Context.TraceID, consumer.Context.TraceID)
+	require.Equal(t, producer.Context.SpanID, consumer.Parent)
+}
+
+func TestEventsDeliverPayloadsWithoutEnvelope(t *testing.T) {
+	t.Parallel()
+
+	broker := event.NewMemoryBroker()
+	events := tracing.Events(tracing.New(tracing.NewMemoryExporter()), broker)
+
+	defer events.Close()
+
+	received := make(chan []byte, 1)
+
+	unsubscribe, err := events.Subscribe(t.Context(), "user.created", func(payload []byte) {
+		received <- payload
+	})
+
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	require.NoError(t, broker.Publish(t.Context(), "user.created", []byte(`{"id":1}`)))
+	require.Equal(t, []byte(`{"id":1}`), receive(t, received))
+
+	require.NoError(t, events.Publish(t.Context(), "user.created", []byte("not json")))
+	require.Equal(t, []byte("not json"), receive(t, received))
+}
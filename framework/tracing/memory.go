@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryExporter is an [Exporter] that keeps the spans in memory,
+// meant for tests. It's safe for concurrent use.
+type MemoryExporter struct {
+	// mutex guards the spans.
+	mutex sync.Mutex
+
+	// spans stores the exported spans.
+	spans []SpanData
+}
+
+// NewMemoryExporter creates an empty [MemoryExporter].
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// Export stores the given spans.
+func (exporter *MemoryExporter) Export(ctx context.Context, spans []SpanData) error {
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	exporter.spans = append(exporter.spans, spans...)
+
+	return nil
+}
+
+// Shutdown does nothing, the spans are kept.
+func (exporter *MemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the exported spans in the order they ended.
+func (exporter *MemoryExporter) Spans() []SpanData {
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	return slices.Clone(exporter.spans)
+}
+
+// Reset removes the exported spans.
+func (exporter *MemoryExporter) Reset() {
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	exporter.spans = nil
+}
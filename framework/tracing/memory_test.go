@@ -0,0 +1,23 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExporterStoresAndResetsSpans(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+
+	require.NoError(t, exporter.Export(context.Background(), []tracing.SpanData{{Name: "a"}, {Name: "b"}}))
+	require.Len(t, exporter.Spans(), 2)
+
+	exporter.Reset()
+
+	require.Empty(t, exporter.Spans())
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	"github.com/studiolambda/cosmos/contract/request"
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/router"
+)
+
+// MiddlewareOptions configures the tracing middleware.
+type MiddlewareOptions struct {
+	// Tracer starts the server spans. It's required.
+	Tracer *Tracer
+
+	// IgnoreIncoming starts a new trace for every request instead
+	// of continuing the one of the incoming traceparent header,
+	// for services that don't trust their clients.
+	IgnoreIncoming bool
+
+	// SkipPaths lists request paths that are not traced,
+	// such as health check endpoints.
+	SkipPaths []string
+}
+
+// Middleware returns middleware that traces every request with
+// the given tracer, see [MiddlewareWith].
+func Middleware(tracer *Tracer) framework.Middleware {
+	return MiddlewareWith(MiddlewareOptions{Tracer: tracer})
+}
+
+// MiddlewareWith returns middleware that starts a server span for
+// every request, continuing the trace of the incoming traceparent and
+// tracestate headers. The span is named after the method and the
+// route, such as "GET /users/{id}" for both "/users/42" and
+// "/users/42/", or after the method alone for requests that match no
+// route, as [router.RoutePattern] reports it. The middleware is meant
+// to be registered with the router's Use, and it's stored in the
+// request context, so that spans started from it, and requests sent
+// with [Transport], belong to the same trace.
+//
+// The span ends once the response is complete, recording the status
+// code and the error returned by the handler, if any. Responses with
+// a 5xx status code mark the span as failed.
+//
+// It panics if the tracer is nil, as it's a programming error.
+func MiddlewareWith(options MiddlewareOptions) framework.Middleware {
+	if options.Tracer == nil {
+		panic("tracing: middleware requires a tracer")
+	}
+
+	return func(next framework.Handler) framework.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if slices.Contains(options.SkipPaths, r.URL.Path) {
+				return next(w, r)
+			}
+
+			ctx := r.Context()
+
+			if !options.IgnoreIncoming {
+				ctx = Extract(ctx, r.Header)
+			}
+
+			route := router.RoutePattern(r.Pattern)
+			name := r.Method
+
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx, span := options.Tracer.Start(
+				ctx,
+				name,
+				SpanKindServer,
+				slog.String("http.request.method", r.Method),
+				slog.String("url.path", r.URL.Path),
+				slog.String("url.scheme", request.Scheme(r)),
+				slog.String("server.address", request.Host(r)),
+				slog.String("client.address", request.ClientIP(r)),
+				slog.String("user_agent.original", r.UserAgent()),
+			)
+
+			if route != "" {
+				span.SetAttributes(slog.String("http.route", route))
+			}
+
+			var status atomic.Int64
+
+			hooks := request.Hooks(r)
+
+			hooks.BeforeWriteHeader(func(w http.ResponseWriter, code int) {
+				status.CompareAndSwap(0, int64(code))
+			})
+
+			hooks.AfterResponse(func(err error) {
+				code := int(status.Load())
+
+				span.SetAttributes(slog.Int("http.response.status_code", code))
+				span.RecordError(err)
+
+				if code >= http.StatusInternalServerError {
+					span.SetStatus(StatusError, http.StatusText(code))
+				}
+
+				span.End()
+			})
+
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tracing_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework"
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tracingApp(options tracing.MiddlewareOptions) (*framework.Router, *tracing.MemoryExporter) {
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+	options.Tracer = tracer
+
+	app := framework.New()
+	app.Use(tracing.MiddlewareWith(options))
+	app.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		_, span := tracer.Start(r.Context(), "load user", tracing.SpanKindInternal)
+		span.End()
+
+		return nil
+	})
+	app.Get("/failing", func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("boom")
+	})
+	app.Get("/missing", func(w http.ResponseWriter, r *http.Request) error {
+		return framework.ErrNotFound
+	})
+
+	return app, exporter
+}
+
+func TestMiddlewareStartsServerSpans(t *testing.T) {
+	t.Parallel()
+
+	app, exporter := tracingApp(tracing.MiddlewareOptions{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("Traceparent", traceparent)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+
+	require.Len(t, spans, 2)
+
+	child, server := spans[0], spans[1]
+
+	require.Equal(t, "GET /users/{id}", server.Name)
+	require.Equal(t, tracing.SpanKindServer, server.Kind)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.Context.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", server.Parent.String())
+	require.Contains(t, server.Attributes, slog.String("http.route", "/users/{id}"))
+	require.Contains(t, server.Attributes, slog.String("url.path", "/users/42"))
+	require.Contains(t, server.Attributes, slog.Int("http.response.status_code", http.StatusNoContent))
+	require.Equal(t, tracing.StatusUnset, server.Status)
+	require.Equal(t, server.Context.SpanID, child.Parent)
+}
+
+func TestMiddlewareNamesSpansAfterTheRegisteredRoute(t *testing.T) {
+	t.Parallel()
+
+	app, exporter := tracingApp(tracing.MiddlewareOptions{})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42/", nil))
+
+	var server tracing.SpanData
+
+	for _, span := range exporter.Spans() {
+		if span.Kind == tracing.SpanKindServer {
+			server = span
+		}
+	}
+
+	require.Equal(t, "GET /users/{id}", server.Name)
+	require.Contains(t, server.Attributes, slog.String("http.route", "/users/{id}"))
+}
+
+func TestMiddlewareRecordsServerErrors(t *testing.T) {
+	t.Parallel()
+
+	app, exporter := tracingApp(tracing.MiddlewareOptions{})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failing", nil))
+
+	span := exporter.Spans()[0]
+
+	require.Equal(t, tracing.StatusError, span.Status)
+	require.Contains(t, span.Attributes, slog.Int("http.response.status_code", http.StatusInternalServerError))
+	require.Equal(t, "exception", span.Events[0].Name)
+}
+
+func TestMiddlewareDoesNotFailClientErrors(t *testing.T) {
+	t.Parallel()
+
+	app, exporter := tracingApp(tracing.MiddlewareOptions{})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	span := exporter.Spans()[0]
+
+	require.Equal(t, tracing.StatusUnset, span.Status)
+	require.Contains(t, span.Attributes, slog.Int("http.response.status_code", http.StatusNotFound))
+}
+
+func TestMiddlewareIgnoresIncomingTraces(t *testing.T) {
+	t.Parallel()
+
+	app, exporter := tracingApp(tracing.MiddlewareOptions{IgnoreIncoming: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/failing", nil)
+	req.Header.Set("Traceparent", traceparent)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := exporter.Spans()[0]
+
+	require.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Context.TraceID.String())
+	require.False(t, span.Parent.IsValid())
+}
+
+func TestMiddlewareSkipsPaths(t *testing.T) {
+	t.Parallel()
+
+	app, exporter := tracingApp(tracing.MiddlewareOptions{SkipPaths: []string{"/failing"}})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/failing", nil))
+
+	require.Empty(t, exporter.Spans())
+}
+
+func TestMiddlewarePanicsWithoutTracer(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() {
+		tracing.MiddlewareWith(tracing.MiddlewareOptions{})
+	})
+}
@@ -0,0 +1,465 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrExporterShutdown is returned when spans are exported
+// to an [OTLPExporter] that was shut down.
+var ErrExporterShutdown = errors.New("exporter is shut down")
+
+// OTLPOptions configures an [OTLPExporter].
+type OTLPOptions struct {
+	// Endpoint is the URL spans are posted to. Defaults to the
+	// traces endpoint of a local collector,
+	// "http://localhost:4318/v1/traces".
+	Endpoint string
+
+	// Service is the name of the service the spans belong to.
+	// Defaults to "unknown_service".
+	Service string
+
+	// Attributes describe the resource the spans belong to,
+	// along with the service name.
+	Attributes []slog.Attr
+
+	// Headers are sent along with every request, such as the
+	// credentials of a hosted collector.
+	Headers map[string]string
+
+	// Client sends the requests. Defaults to a client
+	// with a ten seconds timeout.
+	Client *http.Client
+
+	// BatchSize is the maximum number of spans per request.
+	// Defaults to 512.
+	BatchSize int
+
+	// Interval is the time between the exports of pending
+	// spans. Defaults to five seconds.
+	Interval time.Duration
+
+	// QueueSize is the maximum number of pending spans, beyond
+	// which new spans are dropped. Defaults to 2048.
+	QueueSize int
+}
+
+// DefaultOTLPOptions holds sensible defaults for an [OTLPExporter].
+var DefaultOTLPOptions = OTLPOptions{
+	Endpoint:  "http://localhost:4318/v1/traces",
+	Service:   "unknown_service",
+	Client:    &http.Client{Timeout: 10 * time.Second},
+	BatchSize: 512,
+	Interval:  5 * time.Second,
+	QueueSize: 2048,
+}
+
+// withDefaults returns a copy of the options with zero values
+// replaced by the corresponding [DefaultOTLPOptions] fields.
+func (options OTLPOptions) withDefaults() OTLPOptions {
+	if options.Endpoint == "" {
+		options.Endpoint = DefaultOTLPOptions.Endpoint
+	}
+
+	if options.Service == "" {
+		options.Service = DefaultOTLPOptions.Service
+	}
+
+	if options.Client == nil {
+		options.Client = DefaultOTLPOptions.Client
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultOTLPOptions.BatchSize
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = DefaultOTLPOptions.Interval
+	}
+
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultOTLPOptions.QueueSize
+	}
+
+	return options
+}
+
+// OTLPExporter is an [Exporter] that posts spans in batches to an
+// OpenTelemetry collector using OTLP over HTTP with JSON encoding.
+// It's safe for concurrent use.
+type OTLPExporter struct {
+	// options stores the exporter options.
+	options OTLPOptions
+
+	// mutex guards the pending spans and the dropped count.
+	mutex sync.Mutex
+
+	// pending stores the spans waiting to be exported.
+	pending []SpanData
+
+	// dropped counts the spans dropped since the last export.
+	dropped int
+
+	// full is signalled once a batch is pending.
+	full chan struct{}
+
+	// stop is closed on shutdown.
+	stop chan struct{}
+
+	// done is closed once the export loop returns.
+	done chan struct{}
+
+	// once guards the shutdown.
+	once sync.Once
+}
+
+// NewOTLPExporter creates an [OTLPExporter] that posts the spans of
+// the given service to the given endpoint using [DefaultOTLPOptions].
+func NewOTLPExporter(endpoint string, service string) *OTLPExporter {
+	options := DefaultOTLPOptions
+	options.Endpoint = endpoint
+	options.Service = service
+
+	return NewOTLPExporterWith(options)
+}
+
+// NewOTLPExporterWith creates an [OTLPExporter] with the given options,
+// which exports pending spans every [OTLPOptions.Interval], or as soon
+// as a batch is complete, until [OTLPExporter.Shutdown] is called.
+// Failed exports are logged and their spans dropped.
+func NewOTLPExporterWith(options OTLPOptions) *OTLPExporter {
+	exporter := &OTLPExporter{
+		options: options.withDefaults(),
+		full:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go exporter.loop()
+
+	return exporter
+}
+
+// Export queues the given spans, dropping them when the queue is full.
+func (exporter *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	select {
+	case <-exporter.stop:
+		return ErrExporterShutdown
+	default:
+	}
+
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	room := max(exporter.options.QueueSize-len(exporter.pending), 0)
+
+	if len(spans) > room {
+		exporter.dropped += len(spans) - room
+		spans = spans[:room]
+	}
+
+	exporter.pending = append(exporter.pending, spans...)
+
+	if len(exporter.pending) >= exporter.options.BatchSize {
+		select {
+		case exporter.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush exports the pending spans right away.
+func (exporter *OTLPExporter) Flush(ctx context.Context) error {
+	exporter.mutex.Lock()
+	pending, dropped := exporter.pending, exporter.dropped
+	exporter.pending, exporter.dropped = nil, 0
+	exporter.mutex.Unlock()
+
+	if dropped > 0 {
+		slog.WarnContext(ctx, "dropped spans as the export queue was full", "dropped", dropped)
+	}
+
+	var errs []error
+
+	for batch := range slices.Chunk(pending, exporter.options.BatchSize) {
+		if err := exporter.send(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Shutdown stops the export loop and exports the pending spans.
+func (exporter *OTLPExporter) Shutdown(ctx context.Context) error {
+	exporter.once.Do(func() {
+		close(exporter.stop)
+	})
+
+	select {
+	case <-exporter.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return exporter.Flush(ctx)
+}
+
+// loop exports the pending spans periodically or once
+// a batch is complete, until the exporter shuts down.
+func (exporter *OTLPExporter) loop() {
+	defer close(exporter.done)
+
+	ticker := time.NewTicker(exporter.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exporter.stop:
+			return
+		case <-ticker.C:
+		case <-exporter.full:
+		}
+
+		if err := exporter.Flush(context.Background()); err != nil {
+			slog.Error("failed to export spans", "endpoint", exporter.options.Endpoint, "err", err)
+		}
+	}
+}
+
+// send posts the given spans to the endpoint.
+func (exporter *OTLPExporter) send(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(exporter.request(spans))
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, exporter.options.Endpoint, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for name, value := range exporter.options.Headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := exporter.options.Client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("collector responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// request returns the export request of the given spans.
+func (exporter *OTLPExporter) request(spans []SpanData) otlpRequest {
+	resource := append([]slog.Attr{slog.String("service.name", exporter.options.Service)}, exporter.options.Attributes...)
+	encoded := make([]otlpSpan, len(spans))
+
+	for i, span := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			TraceState:        span.Context.TraceState,
+			Name:              span.Name,
+			Kind:              int(span.Kind),
+			StartTimeUnixNano: unixNano(span.Start),
+			EndTimeUnixNano:   unixNano(span.End),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status: otlpStatus{
+				Code:    int(span.Status),
+				Message: span.StatusMessage,
+			},
+		}
+
+		if span.Parent.IsValid() {
+			encoded[i].ParentSpanID = span.Parent.String()
+		}
+
+		for _, event := range span.Events {
+			encoded[i].Events = append(encoded[i].Events, otlpEvent{
+				Name:         event.Name,
+				TimeUnixNano: unixNano(event.Time),
+				Attributes:   otlpAttributes(event.Attributes),
+			})
+		}
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: otlpAttributes(resource)},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/studiolambda/cosmos/framework/tracing"},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
+
+// otlpRequest is the body of an OTLP traces export request.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpResourceSpans groups the spans of a resource.
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+// otlpResource describes the entity that produced the spans.
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+// otlpScopeSpans groups the spans of an instrumentation scope.
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+// otlpScope describes the instrumentation that produced the spans.
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+// otlpSpan is an encoded span.
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	TraceState        string         `json:"traceState,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+// otlpEvent is an encoded span event.
+type otlpEvent struct {
+	Name         string         `json:"name"`
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// otlpStatus is an encoded span status.
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpKeyValue is an encoded attribute.
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue is an encoded attribute value, with a
+// single field set depending on its type.
+type otlpValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    string          `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlist     `json:"kvlistValue,omitempty"`
+}
+
+// otlpArrayValue is an encoded array attribute value.
+type otlpArrayValue struct {
+	Values []otlpValue `json:"values"`
+}
+
+// otlpKvlist is an encoded group attribute value.
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// otlpAttributes encodes the given attributes.
+func otlpAttributes(attrs []slog.Attr) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		encoded = append(encoded, otlpKeyValue{
+			Key:   attr.Key,
+			Value: otlpEncode(attr.Value),
+		})
+	}
+
+	return encoded
+}
+
+// otlpEncode encodes the given attribute value.
+func otlpEncode(value slog.Value) otlpValue {
+	value = value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindString:
+		text := value.String()
+
+		return otlpValue{StringValue: &text}
+	case slog.KindBool:
+		boolean := value.Bool()
+
+		return otlpValue{BoolValue: &boolean}
+	case slog.KindInt64:
+		return otlpValue{IntValue: strconv.FormatInt(value.Int64(), 10)}
+	case slog.KindUint64:
+		return otlpValue{IntValue: strconv.FormatUint(value.Uint64(), 10)}
+	case slog.KindFloat64:
+		double := value.Float64()
+
+		return otlpValue{DoubleValue: &double}
+	case slog.KindGroup:
+		return otlpValue{KvlistValue: &otlpKvlist{Values: otlpAttributes(value.Group())}}
+	}
+
+	if values, ok := value.Any().([]string); ok {
+		array := &otlpArrayValue{Values: make([]otlpValue, len(values))}
+
+		for i, item := range values {
+			array.Values[i] = otlpEncode(slog.StringValue(item))
+		}
+
+		return otlpValue{ArrayValue: array}
+	}
+
+	text := value.String()
+
+	return otlpValue{StringValue: &text}
+}
+
+// unixNano returns the given time as nanoseconds since
+// the epoch, encoded as a string as OTLP/JSON expects.
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
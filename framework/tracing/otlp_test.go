@@ -0,0 +1,130 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// collector starts a server that sends the bodies
+// of the export requests it receives to a channel.
+func collector(t *testing.T, status int) (*httptest.Server, chan map[string]any) {
+	t.Helper()
+
+	bodies := make(chan map[string]any, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+
+		if r.Method == http.MethodPost && r.Header.Get("Content-Type") == "application/json" && r.Header.Get("Authorization") == "Bearer token" {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, bodies
+}
+
+func TestOTLPExporterPostsSpansOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	server, bodies := collector(t, http.StatusOK)
+	exporter := tracing.NewOTLPExporterWith(tracing.OTLPOptions{
+		Endpoint:   server.URL,
+		Service:    "api",
+		Attributes: []slog.Attr{slog.String("deployment.environment.name", "test")},
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+		Interval:   time.Hour,
+	})
+
+	tracer := tracing.New(exporter)
+	ctx, parent := tracer.Start(context.Background(), "parent", tracing.SpanKindServer, slog.Int("http.response.status_code", 500))
+	_, child := tracer.Start(ctx, "child", tracing.SpanKindClient, slog.Bool("cached", true), slog.Float64("ratio", 0.5))
+
+	child.RecordError(errors.New("boom"))
+	child.SetStatus(tracing.StatusError, "boom")
+	child.End()
+	parent.End()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	body := <-bodies
+	resource := body["resourceSpans"].([]any)[0].(map[string]any)
+	attributes := resource["resource"].(map[string]any)["attributes"].([]any)
+	spans := resource["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	first := spans[0].(map[string]any)
+	second := spans[1].(map[string]any)
+
+	require.Equal(t, map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "api"}}, attributes[0])
+	require.Len(t, attributes, 2)
+	require.Len(t, spans, 2)
+	require.Equal(t, "child", first["name"])
+	require.Equal(t, float64(3), first["kind"])
+	require.Equal(t, parent.Context().TraceID.String(), first["traceId"])
+	require.Equal(t, parent.Context().SpanID.String(), first["parentSpanId"])
+	require.Equal(t, map[string]any{"code": float64(2), "message": "boom"}, first["status"])
+	require.Equal(t, map[string]any{"key": "cached", "value": map[string]any{"boolValue": true}}, first["attributes"].([]any)[0])
+	require.Equal(t, map[string]any{"key": "ratio", "value": map[string]any{"doubleValue": 0.5}}, first["attributes"].([]any)[1])
+	require.Equal(t, "exception", first["events"].([]any)[0].(map[string]any)["name"])
+	require.NotContains(t, second, "parentSpanId")
+	require.Equal(t, map[string]any{"key": "http.response.status_code", "value": map[string]any{"intValue": "500"}}, second["attributes"].([]any)[0])
+}
+
+func TestOTLPExporterPostsCompleteBatches(t *testing.T) {
+	t.Parallel()
+
+	server, bodies := collector(t, http.StatusOK)
+	exporter := tracing.NewOTLPExporterWith(tracing.OTLPOptions{
+		Endpoint:  server.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+		BatchSize: 2,
+		Interval:  time.Hour,
+	})
+
+	defer exporter.Shutdown(context.Background())
+
+	require.NoError(t, exporter.Export(context.Background(), []tracing.SpanData{{Name: "a"}, {Name: "b"}}))
+
+	select {
+	case body := <-bodies:
+		require.Contains(t, body, "resourceSpans")
+	case <-time.After(time.Second):
+		t.Fatal("batch was not exported")
+	}
+}
+
+func TestOTLPExporterReportsFailedExports(t *testing.T) {
+	t.Parallel()
+
+	server, _ := collector(t, http.StatusServiceUnavailable)
+	exporter := tracing.NewOTLPExporterWith(tracing.OTLPOptions{
+		Endpoint: server.URL,
+		Interval: time.Hour,
+	})
+
+	defer exporter.Shutdown(context.Background())
+
+	require.NoError(t, exporter.Export(context.Background(), []tracing.SpanData{{Name: "a"}}))
+	require.EqualError(t, exporter.Flush(context.Background()), "collector responded with status 503")
+}
+
+func TestOTLPExporterRejectsSpansAfterShutdown(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewOTLPExporter("http://127.0.0.1:0/v1/traces", "api")
+
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	require.ErrorIs(t, exporter.Export(context.Background(), []tracing.SpanData{{Name: "a"}}), tracing.ErrExporterShutdown)
+}
@@ -0,0 +1,277 @@
+package tracing
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SpanKind describes the relationship of a span with the
+// other spans of its trace. Values match the OTLP ones.
+type SpanKind int
+
+const (
+	// SpanKindInternal is the kind of spans of internal operations.
+	SpanKindInternal SpanKind = iota + 1
+
+	// SpanKindServer is the kind of spans of handled requests.
+	SpanKindServer
+
+	// SpanKindClient is the kind of spans of outgoing requests,
+	// such as HTTP calls and database queries.
+	SpanKindClient
+
+	// SpanKindProducer is the kind of spans of published messages.
+	SpanKindProducer
+
+	// SpanKindConsumer is the kind of spans of processed messages.
+	SpanKindConsumer
+)
+
+// StatusCode is the status of a span. Values match the OTLP ones.
+type StatusCode int
+
+const (
+	// StatusUnset is the default status of spans.
+	StatusUnset StatusCode = iota
+
+	// StatusOK marks a span as successful, overriding the
+	// status instrumentation would otherwise set.
+	StatusOK
+
+	// StatusError marks a span as failed.
+	StatusError
+)
+
+// Event is a timestamped annotation of a span.
+type Event struct {
+	// Name is the name of the event, such as "exception".
+	Name string
+
+	// Time is when the event happened.
+	Time time.Time
+
+	// Attributes describe the event.
+	Attributes []slog.Attr
+}
+
+// SpanData is the snapshot of an ended span given to an [Exporter].
+type SpanData struct {
+	// Name is the name of the span.
+	Name string
+
+	// Kind is the kind of the span.
+	Kind SpanKind
+
+	// Context is the span context of the span.
+	Context SpanContext
+
+	// Parent is the ID of the parent span, invalid for root spans.
+	Parent SpanID
+
+	// Start is when the span started.
+	Start time.Time
+
+	// End is when the span ended.
+	End time.Time
+
+	// Attributes describe the span.
+	Attributes []slog.Attr
+
+	// Events lists the events of the span.
+	Events []Event
+
+	// Status is the status of the span.
+	Status StatusCode
+
+	// StatusMessage describes an error status.
+	StatusMessage string
+}
+
+// Span is a timed operation of a trace, started with [Tracer.Start].
+// Spans that are not sampled are still propagated but don't record
+// anything. It's safe for concurrent use, and its methods do nothing
+// on nil spans.
+type Span struct {
+	// tracer stores the tracer that started the span.
+	tracer *Tracer
+
+	// context stores the span context.
+	context SpanContext
+
+	// parent stores the ID of the parent span.
+	parent SpanID
+
+	// kind stores the span kind.
+	kind SpanKind
+
+	// start stores when the span started.
+	start time.Time
+
+	// recording reports whether the span is sampled.
+	recording bool
+
+	// mutex guards the mutable fields below.
+	mutex sync.Mutex
+
+	// name stores the span name.
+	name string
+
+	// attrs stores the span attributes.
+	attrs []slog.Attr
+
+	// events stores the span events.
+	events []Event
+
+	// status stores the span status.
+	status StatusCode
+
+	// message stores the status message.
+	message string
+
+	// ended reports whether the span ended.
+	ended bool
+}
+
+// Context returns the span context of the span.
+func (span *Span) Context() SpanContext {
+	if span == nil {
+		return SpanContext{}
+	}
+
+	return span.context
+}
+
+// IsRecording reports whether the span records its data,
+// which is the case of sampled spans until they end.
+func (span *Span) IsRecording() bool {
+	if span == nil || !span.recording {
+		return false
+	}
+
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	return !span.ended
+}
+
+// SetName replaces the name of the span.
+func (span *Span) SetName(name string) {
+	span.update(func() {
+		span.name = name
+	})
+}
+
+// SetAttributes sets the given attributes, replacing
+// existing ones with the same key.
+func (span *Span) SetAttributes(attrs ...slog.Attr) {
+	span.update(func() {
+		for _, attr := range attrs {
+			i := slices.IndexFunc(span.attrs, func(existing slog.Attr) bool {
+				return existing.Key == attr.Key
+			})
+
+			if i >= 0 {
+				span.attrs[i] = attr
+			} else {
+				span.attrs = append(span.attrs, attr)
+			}
+		}
+	})
+}
+
+// AddEvent adds an event of the given name and attributes.
+func (span *Span) AddEvent(name string, attrs ...slog.Attr) {
+	span.update(func() {
+		span.events = append(span.events, Event{
+			Name:       name,
+			Time:       time.Now(),
+			Attributes: attrs,
+		})
+	})
+}
+
+// RecordError adds an "exception" event describing the given error.
+// It does not change the status of the span, see [Span.SetStatus].
+func (span *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	span.AddEvent(
+		"exception",
+		slog.String("exception.type", fmt.Sprintf("%T", err)),
+		slog.String("exception.message", err.Error()),
+	)
+}
+
+// SetStatus sets the status of the span. The message is only kept
+// for [StatusError], [StatusUnset] is ignored and [StatusOK] is final.
+func (span *Span) SetStatus(code StatusCode, message string) {
+	span.update(func() {
+		if code == StatusUnset || span.status == StatusOK {
+			return
+		}
+
+		span.status = code
+		span.message = ""
+
+		if code == StatusError {
+			span.message = message
+		}
+	})
+}
+
+// End ends the span and exports it when sampled.
+// Calls after the first one do nothing.
+func (span *Span) End() {
+	if span == nil {
+		return
+	}
+
+	span.mutex.Lock()
+
+	if span.ended {
+		span.mutex.Unlock()
+
+		return
+	}
+
+	span.ended = true
+
+	data := SpanData{
+		Name:          span.name,
+		Kind:          span.kind,
+		Context:       span.context,
+		Parent:        span.parent,
+		Start:         span.start,
+		End:           time.Now(),
+		Attributes:    span.attrs,
+		Events:        span.events,
+		Status:        span.status,
+		StatusMessage: span.message,
+	}
+
+	span.mutex.Unlock()
+
+	if span.recording {
+		span.tracer.export(data)
+	}
+}
+
+// update runs the given function with the span locked,
+// unless it's nil, not recording or ended.
+func (span *Span) update(fn func()) {
+	if span == nil || !span.recording {
+		return
+	}
+
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	if !span.ended {
+		fn()
+	}
+}
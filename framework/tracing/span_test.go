@@ -0,0 +1,82 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpanRecordsData(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	_, span := tracing.New(exporter).Start(context.Background(), "job", tracing.SpanKindInternal, slog.String("queue", "emails"))
+
+	span.SetName("renamed")
+	span.SetAttributes(slog.String("queue", "reports"), slog.Int("attempt", 2))
+	span.AddEvent("retry", slog.Int("attempt", 2))
+	span.RecordError(errors.New("boom"))
+	span.SetStatus(tracing.StatusError, "boom")
+	span.End()
+
+	data := exporter.Spans()[0]
+
+	require.Equal(t, "renamed", data.Name)
+	require.Equal(t, []slog.Attr{slog.String("queue", "reports"), slog.Int("attempt", 2)}, data.Attributes)
+	require.Len(t, data.Events, 2)
+	require.Equal(t, "retry", data.Events[0].Name)
+	require.Equal(t, "exception", data.Events[1].Name)
+	require.Contains(t, data.Events[1].Attributes, slog.String("exception.message", "boom"))
+	require.Equal(t, tracing.StatusError, data.Status)
+	require.Equal(t, "boom", data.StatusMessage)
+}
+
+func TestSpanStatusOKIsFinal(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	_, span := tracing.New(exporter).Start(context.Background(), "job", tracing.SpanKindInternal)
+
+	span.SetStatus(tracing.StatusError, "boom")
+	span.SetStatus(tracing.StatusOK, "ignored")
+	span.SetStatus(tracing.StatusError, "boom")
+	span.End()
+
+	require.Equal(t, tracing.StatusOK, exporter.Spans()[0].Status)
+	require.Empty(t, exporter.Spans()[0].StatusMessage)
+}
+
+func TestSpanEndsOnce(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	_, span := tracing.New(exporter).Start(context.Background(), "job", tracing.SpanKindInternal)
+
+	span.End()
+	span.SetName("ignored")
+	span.End()
+
+	require.Len(t, exporter.Spans(), 1)
+	require.Equal(t, "job", exporter.Spans()[0].Name)
+	require.False(t, span.IsRecording())
+}
+
+func TestNilSpanIsSafe(t *testing.T) {
+	t.Parallel()
+
+	span := tracing.SpanFromContext(context.Background())
+
+	require.Nil(t, span)
+	require.NotPanics(t, func() {
+		span.SetAttributes(slog.String("key", "value"))
+		span.RecordError(errors.New("boom"))
+		span.SetStatus(tracing.StatusError, "boom")
+		span.End()
+	})
+	require.False(t, span.Context().IsValid())
+}
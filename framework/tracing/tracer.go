@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Exporter receives the spans of a [Tracer] once they end, such as
+// the [MemoryExporter] and the [OTLPExporter].
+type Exporter interface {
+	// Export exports the given ended spans. It's called for every
+	// sampled span as it ends, so implementations that send spans
+	// over the network should batch them.
+	Export(ctx context.Context, spans []SpanData) error
+
+	// Shutdown exports any pending spans and releases the
+	// resources of the exporter.
+	Shutdown(ctx context.Context) error
+}
+
+// TracerOptions configures a [Tracer].
+type TracerOptions struct {
+	// Exporter receives the sampled spans once they end. Spans are
+	// still created and propagated without one, but not exported.
+	Exporter Exporter
+
+	// SampleRate is the fraction of new traces that are sampled,
+	// between 0 and 1, so that 0 samples no new traces. Spans with
+	// a parent follow its decision, so that traces are either
+	// complete or missing. Every new trace is sampled when nil.
+	SampleRate *float64
+}
+
+// DefaultTracerOptions holds sensible defaults for a [Tracer],
+// which samples every new trace.
+var DefaultTracerOptions = TracerOptions{}
+
+// Tracer starts spans and exports them once ended.
+// It's safe for concurrent use.
+type Tracer struct {
+	// options stores the tracer options.
+	options TracerOptions
+
+	// rate stores the fraction of new traces that are sampled.
+	rate float64
+}
+
+// New creates a [Tracer] that exports spans to the given exporter
+// using [DefaultTracerOptions].
+func New(exporter Exporter) *Tracer {
+	options := DefaultTracerOptions
+	options.Exporter = exporter
+
+	return NewWith(options)
+}
+
+// NewWith creates a [Tracer] with the given options.
+func NewWith(options TracerOptions) *Tracer {
+	tracer := &Tracer{options: options, rate: 1}
+
+	if options.SampleRate != nil {
+		tracer.rate = *options.SampleRate
+	}
+
+	return tracer
+}
+
+// Start starts a span of the given name and kind as a child of the
+// span found in the given context, either the current one or a remote
+// one, or as the root of a new trace otherwise. It returns a copy of
+// the context carrying the new span, which must be ended with
+// [Span.End].
+func (tracer *Tracer) Start(ctx context.Context, name string, kind SpanKind, attrs ...slog.Attr) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		tracer: tracer,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+		attrs:  slices.Clone(attrs),
+	}
+
+	if parent.IsValid() {
+		span.parent = parent.SpanID
+		span.context = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			Flags:      parent.Flags,
+			TraceState: parent.TraceState,
+		}
+	} else {
+		span.context = SpanContext{
+			TraceID: newTraceID(),
+			SpanID:  newSpanID(),
+		}
+
+		if tracer.rate >= 1 || rand.Float64() < tracer.rate {
+			span.context.Flags |= FlagSampled
+		}
+	}
+
+	span.recording = span.context.IsSampled()
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Shutdown shuts the exporter down, exporting any pending spans.
+func (tracer *Tracer) Shutdown(ctx context.Context) error {
+	if tracer.options.Exporter == nil {
+		return nil
+	}
+
+	return tracer.options.Exporter.Shutdown(ctx)
+}
+
+// export hands the given ended span to the exporter.
+func (tracer *Tracer) export(data SpanData) {
+	if tracer.options.Exporter == nil {
+		return
+	}
+
+	if err := tracer.options.Exporter.Export(context.Background(), []SpanData{data}); err != nil {
+		slog.Error("failed to export span", "span", data.Name, "err", err)
+	}
+}
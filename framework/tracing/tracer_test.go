@@ -0,0 +1,141 @@
+package tracing_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracerStartsRootSpans(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+
+	ctx, span := tracer.Start(context.Background(), "job", tracing.SpanKindInternal, slog.String("queue", "emails"))
+	span.End()
+
+	spans := exporter.Spans()
+
+	require.Same(t, span, tracing.SpanFromContext(ctx))
+	require.Len(t, spans, 1)
+	require.Equal(t, "job", spans[0].Name)
+	require.Equal(t, tracing.SpanKindInternal, spans[0].Kind)
+	require.True(t, spans[0].Context.IsValid())
+	require.True(t, spans[0].Context.IsSampled())
+	require.False(t, spans[0].Parent.IsValid())
+	require.Equal(t, []slog.Attr{slog.String("queue", "emails")}, spans[0].Attributes)
+	require.False(t, spans[0].End.Before(spans[0].Start))
+}
+
+func TestTracerStartsChildSpans(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "parent", tracing.SpanKindInternal)
+	_, child := tracer.Start(ctx, "child", tracing.SpanKindInternal)
+
+	child.End()
+	parent.End()
+
+	spans := exporter.Spans()
+
+	require.Len(t, spans, 2)
+	require.Equal(t, spans[1].Context.TraceID, spans[0].Context.TraceID)
+	require.Equal(t, spans[1].Context.SpanID, spans[0].Parent)
+}
+
+func TestTracerContinuesRemoteSpans(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+
+	remote, err := tracing.ParseTraceparent(traceparent)
+	require.NoError(t, err)
+
+	remote.TraceState = "vendor=value"
+
+	_, span := tracer.Start(tracing.ContextWithRemoteSpanContext(context.Background(), remote), "handle", tracing.SpanKindServer)
+	span.End()
+
+	spanContext := exporter.Spans()[0].Context
+
+	require.Equal(t, remote.TraceID, spanContext.TraceID)
+	require.Equal(t, remote.SpanID, exporter.Spans()[0].Parent)
+	require.Equal(t, "vendor=value", spanContext.TraceState)
+	require.False(t, spanContext.Remote)
+}
+
+func TestTracerFollowsParentSamplingDecision(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+
+	remote, err := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.NoError(t, err)
+
+	ctx, span := tracer.Start(tracing.ContextWithRemoteSpanContext(context.Background(), remote), "handle", tracing.SpanKindServer)
+	span.End()
+
+	require.False(t, span.IsRecording())
+	require.True(t, tracing.SpanContextFromContext(ctx).IsValid())
+	require.Empty(t, exporter.Spans())
+}
+
+func TestTracerSamplesNewTraces(t *testing.T) {
+	t.Parallel()
+
+	rate := 0.000001
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.NewWith(tracing.TracerOptions{
+		Exporter:   exporter,
+		SampleRate: &rate,
+	})
+
+	for range 100 {
+		_, span := tracer.Start(context.Background(), "job", tracing.SpanKindInternal)
+		span.End()
+	}
+
+	require.Less(t, len(exporter.Spans()), 100)
+}
+
+func TestTracerSamplesNoNewTraces(t *testing.T) {
+	t.Parallel()
+
+	rate := 0.0
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.NewWith(tracing.TracerOptions{
+		Exporter:   exporter,
+		SampleRate: &rate,
+	})
+
+	for range 10 {
+		_, span := tracer.Start(context.Background(), "job", tracing.SpanKindInternal)
+
+		require.False(t, span.Context().IsSampled())
+
+		span.End()
+	}
+
+	require.Empty(t, exporter.Spans())
+}
+
+func TestTracerWorksWithoutExporter(t *testing.T) {
+	t.Parallel()
+
+	tracer := tracing.NewWith(tracing.TracerOptions{})
+
+	_, span := tracer.Start(context.Background(), "job", tracing.SpanKindInternal)
+	span.End()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+}
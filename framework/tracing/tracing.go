@@ -0,0 +1,261 @@
+// Package tracing provides distributed tracing for Cosmos applications,
+// propagating trace contexts with the W3C Trace Context headers.
+//
+// A [Tracer] starts spans, which are sampled, timed and handed to an
+// [Exporter] once ended. The package starts server spans for requests
+// through [Middleware], client spans for outgoing requests through
+// [Transport], and spans for events and database queries through
+// [Events] and [Database], propagating the trace context across all
+// of them:
+//
+//	exporter := tracing.NewOTLPExporter("http://localhost:4318/v1/traces", "api")
+//	tracer := tracing.New(exporter)
+//
+//	app.Use(tracing.Middleware(tracer))
+//
+//	client := &http.Client{Transport: tracing.Transport(tracer, nil)}
+//	events := tracing.Events(tracer, broker)
+//	db := tracing.Database(tracer, "main", sql)
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidTraceparent is returned when a traceparent
+// header does not follow the W3C Trace Context format.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// maxTraceStateLength is the maximum length of the propagated
+// tracestate header, as defined by W3C Trace Context.
+const maxTraceStateLength = 512
+
+// TraceID identifies a trace, shared by all of its spans.
+type TraceID [16]byte
+
+// String returns the trace ID as lowercase hex.
+func (traceID TraceID) String() string {
+	return hex.EncodeToString(traceID[:])
+}
+
+// IsValid reports whether the trace ID is not all zeros.
+func (traceID TraceID) IsValid() bool {
+	return traceID != TraceID{}
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the span ID as lowercase hex.
+func (spanID SpanID) String() string {
+	return hex.EncodeToString(spanID[:])
+}
+
+// IsValid reports whether the span ID is not all zeros.
+func (spanID SpanID) IsValid() bool {
+	return spanID != SpanID{}
+}
+
+// FlagSampled is the trace flag of sampled spans.
+const FlagSampled byte = 0x01
+
+// SpanContext is the part of a span that's propagated
+// across process boundaries.
+type SpanContext struct {
+	// TraceID identifies the trace of the span.
+	TraceID TraceID
+
+	// SpanID identifies the span.
+	SpanID SpanID
+
+	// Flags stores the trace flags, such as [FlagSampled].
+	Flags byte
+
+	// TraceState stores the vendor-specific tracestate header,
+	// which is propagated as is.
+	TraceState string
+
+	// Remote reports whether the context was propagated
+	// from another process.
+	Remote bool
+}
+
+// IsValid reports whether both the trace and span IDs are valid.
+func (spanContext SpanContext) IsValid() bool {
+	return spanContext.TraceID.IsValid() && spanContext.SpanID.IsValid()
+}
+
+// IsSampled reports whether the span is sampled.
+func (spanContext SpanContext) IsSampled() bool {
+	return spanContext.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the span context as a version 00
+// traceparent header value.
+func (spanContext SpanContext) Traceparent() string {
+	return "00-" + spanContext.TraceID.String() + "-" + spanContext.SpanID.String() + "-" + hex.EncodeToString([]byte{spanContext.Flags})
+}
+
+// ParseTraceparent parses a traceparent header value as defined by
+// W3C Trace Context. Values of future versions are accepted as long
+// as their known fields are valid.
+func ParseTraceparent(value string) (SpanContext, error) {
+	value = strings.TrimSpace(value)
+
+	if len(value) < 55 || !isLowerHex(value[:2]) || value[:2] == "ff" {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	if value[:2] == "00" && len(value) != 55 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	if len(value) > 55 && value[55] != '-' {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	traceID, spanID, flags := value[3:35], value[36:52], value[53:55]
+
+	if !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(flags) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	spanContext := SpanContext{Remote: true}
+
+	hex.Decode(spanContext.TraceID[:], []byte(traceID))
+	hex.Decode(spanContext.SpanID[:], []byte(spanID))
+
+	var decoded [1]byte
+
+	hex.Decode(decoded[:], []byte(flags))
+	spanContext.Flags = decoded[0]
+
+	if !spanContext.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	return spanContext, nil
+}
+
+// Extract returns a copy of the given context carrying the span
+// context propagated by the traceparent and tracestate headers,
+// which becomes the parent of the next span started with it. The
+// context is returned as is when the headers are missing or invalid.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	spanContext, err := ParseTraceparent(header.Get("Traceparent"))
+
+	if err != nil {
+		return ctx
+	}
+
+	if state := strings.Join(header.Values("Tracestate"), ","); len(state) <= maxTraceStateLength {
+		spanContext.TraceState = state
+	}
+
+	return ContextWithRemoteSpanContext(ctx, spanContext)
+}
+
+// Inject sets the traceparent and tracestate headers of the span
+// context found in the given context, if any.
+func Inject(ctx context.Context, header http.Header) {
+	spanContext := SpanContextFromContext(ctx)
+
+	if !spanContext.IsValid() {
+		return
+	}
+
+	header.Set("Traceparent", spanContext.Traceparent())
+
+	if spanContext.TraceState != "" {
+		header.Set("Tracestate", spanContext.TraceState)
+	} else {
+		header.Del("Tracestate")
+	}
+}
+
+// spanKey is the context key of the current [Span].
+type spanKey struct{}
+
+// remoteKey is the context key of a remote [SpanContext].
+type remoteKey struct{}
+
+// ContextWithSpan returns a copy of the given context
+// carrying the given span as the current one.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span of the given context,
+// or nil when there's none. The methods of [Span] are safe to call
+// on nil spans, so the result can be used unchecked.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of the given context
+// carrying the given span context propagated from another process.
+func ContextWithRemoteSpanContext(ctx context.Context, spanContext SpanContext) context.Context {
+	spanContext.Remote = true
+
+	return context.WithValue(ctx, remoteKey{}, spanContext)
+}
+
+// SpanContextFromContext returns the span context of the current
+// span of the given context, or the remote one when there's no
+// current span, or the zero value otherwise.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context()
+	}
+
+	spanContext, _ := ctx.Value(remoteKey{}).(SpanContext)
+
+	return spanContext
+}
+
+// newTraceID returns a random valid trace ID.
+func newTraceID() TraceID {
+	var id TraceID
+
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+
+	return id
+}
+
+// newSpanID returns a random valid span ID.
+func newSpanID() SpanID {
+	var id SpanID
+
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+
+	return id
+}
+
+// isLowerHex reports whether the given value only
+// contains lowercase hex digits.
+func isLowerHex(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if !('0' <= value[i] && value[i] <= '9') && !('a' <= value[i] && value[i] <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparentParsesValidValues(t *testing.T) {
+	t.Parallel()
+
+	spanContext, err := tracing.ParseTraceparent(traceparent)
+
+	require.NoError(t, err)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID.String())
+	require.Equal(t, "00f067aa0ba902b7", spanContext.SpanID.String())
+	require.True(t, spanContext.IsSampled())
+	require.True(t, spanContext.Remote)
+	require.Equal(t, traceparent, spanContext.Traceparent())
+}
+
+func TestParseTraceparentAcceptsFutureVersions(t *testing.T) {
+	t.Parallel()
+
+	spanContext, err := tracing.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+
+	require.NoError(t, err)
+	require.False(t, spanContext.IsSampled())
+}
+
+func TestParseTraceparentRejectsInvalidValues(t *testing.T) {
+	t.Parallel()
+
+	values := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+
+	for _, value := range values {
+		_, err := tracing.ParseTraceparent(value)
+
+		require.ErrorIs(t, err, tracing.ErrInvalidTraceparent, value)
+	}
+}
+
+func TestExtractAndInjectRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	incoming := http.Header{}
+	incoming.Set("Traceparent", traceparent)
+	incoming.Set("Tracestate", "vendor=value")
+
+	ctx := tracing.Extract(context.Background(), incoming)
+	outgoing := http.Header{}
+
+	tracing.Inject(ctx, outgoing)
+
+	require.Equal(t, traceparent, outgoing.Get("Traceparent"))
+	require.Equal(t, "vendor=value", outgoing.Get("Tracestate"))
+}
+
+func TestExtractIgnoresInvalidHeaders(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("Traceparent", "invalid")
+
+	ctx := tracing.Extract(context.Background(), header)
+
+	require.False(t, tracing.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestInjectSkipsContextsWithoutSpan(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	tracing.Inject(context.Background(), header)
+
+	require.Empty(t, header)
+}
@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// Transport returns an [http.RoundTripper] that starts a client span
+// for every request sent through the given one, or through
+// [http.DefaultTransport] when nil, and propagates it with the
+// traceparent and tracestate headers, so that the services it calls
+// continue the trace of the request context.
+//
+// Responses with a 4xx or 5xx status code mark the span as failed.
+// The span ends once the response headers are received.
+//
+// Example usage:
+//
+//	client := &http.Client{Transport: tracing.Transport(tracer, nil)}
+//	req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, url, nil)
+//	res, err := client.Do(req)
+func Transport(tracer *Tracer, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &transport{tracer: tracer, base: base}
+}
+
+// transport is an [http.RoundTripper] that traces requests.
+type transport struct {
+	// tracer starts the client spans.
+	tracer *Tracer
+
+	// base sends the requests.
+	base http.RoundTripper
+}
+
+// RoundTrip sends the given request within a client span.
+func (transport *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := transport.tracer.Start(
+		r.Context(),
+		r.Method,
+		SpanKindClient,
+		slog.String("http.request.method", r.Method),
+		slog.String("url.full", redactURL(r.URL)),
+		slog.String("server.address", r.URL.Hostname()),
+	)
+
+	defer span.End()
+
+	// Round trippers must not modify the given request.
+	r = r.Clone(ctx)
+	Inject(ctx, r.Header)
+
+	res, err := transport.base.RoundTrip(r)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(StatusError, err.Error())
+
+		return nil, err
+	}
+
+	span.SetAttributes(slog.Int("http.response.status_code", res.StatusCode))
+
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(StatusError, http.StatusText(res.StatusCode))
+	}
+
+	return res, nil
+}
+
+// redactURL returns the given URL without its credentials and query,
+// which could contain secrets.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.ForceQuery = false
+	redacted.Fragment = ""
+
+	return redacted.String()
+}
@@ -0,0 +1,61 @@
+package tracing_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/framework/tracing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportPropagatesClientSpans(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	defer server.Close()
+
+	exporter := tracing.NewMemoryExporter()
+	tracer := tracing.New(exporter)
+	client := &http.Client{Transport: tracing.Transport(tracer, nil)}
+
+	ctx, parent := tracer.Start(context.Background(), "job", tracing.SpanKindInternal)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/users?token=secret", nil)
+	require.NoError(t, err)
+
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	parent.End()
+
+	span := exporter.Spans()[0]
+
+	require.Empty(t, req.Header.Get("Traceparent"))
+	require.Equal(t, span.Context.Traceparent(), <-received)
+	require.Equal(t, http.MethodGet, span.Name)
+	require.Equal(t, tracing.SpanKindClient, span.Kind)
+	require.Equal(t, parent.Context().SpanID, span.Parent)
+	require.Equal(t, tracing.StatusError, span.Status)
+	require.Contains(t, span.Attributes, slog.String("url.full", server.URL+"/users"))
+	require.Contains(t, span.Attributes, slog.Int("http.response.status_code", http.StatusNotFound))
+}
+
+func TestTransportRecordsFailures(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracing.NewMemoryExporter()
+	client := &http.Client{Transport: tracing.Transport(tracing.New(exporter), nil)}
+
+	_, err := client.Get("http://127.0.0.1:0")
+
+	require.Error(t, err)
+	require.Equal(t, tracing.StatusError, exporter.Spans()[0].Status)
+}
@@ -0,0 +1,305 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Constraint restricts the values a route wildcard accepts. Requests
+// whose wildcard values don't satisfy the constraints of the matched
+// route never reach its handler, see [Route.Where].
+type Constraint struct {
+	// name stores the description of the constraint, as reported
+	// by [Constraint.String] and [RouteInfo.Constraints].
+	name string
+
+	// match stores the function that reports whether a
+	// wildcard value satisfies the constraint.
+	match func(value string) bool
+}
+
+var (
+	// uuidPattern matches the canonical textual representation of
+	// a UUID, regardless of its version.
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// slugPattern matches lowercase alphanumeric words joined by
+	// single hyphens, e.g. "hello-world".
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+)
+
+// NewConstraint creates a custom [Constraint] with the given
+// description that accepts the values the match function reports
+// as valid.
+func NewConstraint(name string, match func(value string) bool) Constraint {
+	return Constraint{
+		name:  name,
+		match: match,
+	}
+}
+
+// Int returns a [Constraint] that accepts base 10 integers that fit
+// in an int64, such as the ones parsed by request.ParamInt.
+func Int() Constraint {
+	return NewConstraint("int", func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+
+		return err == nil
+	})
+}
+
+// UUID returns a [Constraint] that accepts UUIDs in their canonical
+// textual representation, e.g. "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+func UUID() Constraint {
+	return NewConstraint("uuid", uuidPattern.MatchString)
+}
+
+// Slug returns a [Constraint] that accepts lowercase alphanumeric
+// words joined by single hyphens, e.g. "hello-world".
+func Slug() Constraint {
+	return NewConstraint("slug", slugPattern.MatchString)
+}
+
+// Regex returns a [Constraint] that accepts the values fully matched
+// by the given regular expression, which doesn't need to be anchored.
+// It panics if the expression is invalid.
+func Regex(expression string) Constraint {
+	compiled := regexp.MustCompile("^(?:" + expression + ")$")
+
+	return NewConstraint("regex("+expression+")", compiled.MatchString)
+}
+
+// Enum returns a [Constraint] that accepts only the given values.
+func Enum(values ...string) Constraint {
+	values = slices.Clone(values)
+
+	return NewConstraint("enum("+strings.Join(values, "|")+")", func(value string) bool {
+		return slices.Contains(values, value)
+	})
+}
+
+// String returns the description of the constraint, e.g. "int" or
+// "enum(draft|published)".
+func (constraint Constraint) String() string {
+	return constraint.name
+}
+
+// Match reports whether the given wildcard value satisfies the constraint.
+func (constraint Constraint) Match(value string) bool {
+	return constraint.match(value)
+}
+
+// ErrConstraint is matched by every [ParameterError], reporting a
+// request whose wildcard value doesn't satisfy a route constraint.
+// Unlike [ErrInvalidParameter], it never comes from generating URLs.
+var ErrConstraint = errors.New("unsatisfied route constraint")
+
+// ParameterError describes a wildcard value that does not satisfy
+// the [Constraint] of the matched route. It is available to the
+// handler registered with [Router.InvalidParameter] through
+// [ParameterErrorFrom].
+type ParameterError struct {
+	// Parameter is the name of the wildcard.
+	Parameter string
+
+	// Value is the value the request gave to the wildcard.
+	Value string
+
+	// Constraint is the constraint the value does not satisfy.
+	Constraint Constraint
+}
+
+// Error returns a description of the invalid parameter.
+func (err *ParameterError) Error() string {
+	return fmt.Sprintf("%s: %q must be %s", ErrConstraint, err.Parameter, err.Constraint)
+}
+
+// Unwrap returns [ErrConstraint] so that the error can be
+// matched using [errors.Is].
+func (err *ParameterError) Unwrap() error {
+	return ErrConstraint
+}
+
+// parameterErrorKey is the context key under which the
+// [ParameterError] of a request is stored.
+type parameterErrorKey struct{}
+
+// ParameterErrorFrom returns the [ParameterError] that caused the
+// handler registered with [Router.InvalidParameter] to be called.
+func ParameterErrorFrom(request *http.Request) (*ParameterError, bool) {
+	err, ok := request.Context().Value(parameterErrorKey{}).(*ParameterError)
+
+	return err, ok
+}
+
+// Where constrains the values the given wildcard of the route
+// accepts. Requests whose value doesn't satisfy the constraint are
+// treated as not found, or dispatched to the handler registered with
+// [Router.InvalidParameter] if any, before the route handler runs.
+//
+//	router.Get("/users/{id}", show).Where("id", Int())
+//
+// The wildcard may come from the prefix of a [Router.Group]. Use
+// [Router.Where] to constrain a wildcard on every route registered
+// through a router.
+//
+// It panics if the route pattern has no wildcard with that name, as
+// it's a programming error.
+func (route *Route) Where(name string, constraint Constraint) *Route {
+	if !slices.Contains(patternWildcards(route.pattern), name) {
+		panic(fmt.Sprintf("router: route %q has no %q wildcard", route.pattern, name))
+	}
+
+	for _, record := range route.records {
+		record.Constraints = maps.Clone(record.Constraints)
+
+		if record.Constraints == nil {
+			record.Constraints = make(map[string]string)
+		}
+
+		record.Constraints[name] = constraint.String()
+
+		for _, key := range constraintKeys(record) {
+			if route.constraints[key] == nil {
+				route.constraints[key] = make(map[string]Constraint)
+			}
+
+			route.constraints[key][name] = constraint
+		}
+	}
+
+	return route
+}
+
+// Where creates a new sub-router that constrains the given wildcard
+// on every route registered through it whose pattern contains it,
+// in addition to any inherited constraint. This is useful for the
+// wildcards of a [Router.Group] prefix:
+//
+//	router.Group("/teams/{team}", func(team *Router[H]) {
+//	    team = team.Where("team", Int())
+//	    team.Get("/members", members)
+//	})
+//
+// Like [Router.With], it creates a new sub-router instead of
+// modifying the current router.
+func (router *Router[H]) Where(name string, constraint Constraint) *Router[H] {
+	subrouter := router.Clone()
+	subrouter.constraints = maps.Clone(router.constraints)
+
+	if subrouter.constraints == nil {
+		subrouter.constraints = make(map[string]Constraint)
+	}
+
+	subrouter.constraints[name] = constraint
+
+	return subrouter
+}
+
+// InvalidParameter registers the handler that is called when a
+// request matches a route but one of its wildcard values doesn't
+// satisfy the route constraints. The [ParameterError] is available
+// to the handler through [ParameterErrorFrom]. The handler runs
+// through the middleware of the router it was registered on, just
+// like [Router.NotFound].
+//
+// When no handler is registered, such requests are treated as not
+// found instead.
+func (router *Router[H]) InvalidParameter(handler H) {
+	router.root().invalidParameter = &fallback[H]{
+		router:  router,
+		handler: handler,
+	}
+}
+
+// constrain applies the constraints inherited from [Router.Where]
+// to the given route, for the wildcards its pattern contains.
+func (router *Router[H]) constrain(route *Route) *Route {
+	wildcards := patternWildcards(route.pattern)
+
+	for _, name := range slices.Sorted(maps.Keys(router.constraints)) {
+		if slices.Contains(wildcards, name) {
+			route.Where(name, router.constraints[name])
+		}
+	}
+
+	return route
+}
+
+// constraintKeys returns the keys under which the constraints of the
+// given record are stored, one for each pattern registered in the
+// [http.ServeMux] for it.
+func constraintKeys(record *RouteInfo) []string {
+	keys := []string{constraintKey(record.Host, record.Method+" "+record.Pattern)}
+
+	if record.Trailing != "" {
+		keys = append(keys, constraintKey(record.Host, record.Method+" "+record.Trailing))
+	}
+
+	return keys
+}
+
+// constraintKey returns the key under which the constraints of a
+// pattern registered in the [http.ServeMux] of the given host are
+// stored.
+func constraintKey(host string, pattern string) string {
+	return host + "|" + pattern
+}
+
+// checkConstraints validates the wildcard values of the request
+// against the constraints of the pattern it matched, returning the
+// [ParameterError] of the first value that doesn't satisfy them.
+// The wildcard values must already be set on the request.
+func (router *Router[H]) checkConstraints(host string, pattern string, request *http.Request) *ParameterError {
+	constraints, ok := router.root().routeConstraints[constraintKey(host, pattern)]
+
+	if !ok {
+		return nil
+	}
+
+	for _, name := range patternWildcards(pattern) {
+		constraint, ok := constraints[name]
+
+		if !ok {
+			continue
+		}
+
+		if value := request.PathValue(name); !constraint.Match(value) {
+			return &ParameterError{
+				Parameter:  name,
+				Value:      value,
+				Constraint: constraint,
+			}
+		}
+	}
+
+	return nil
+}
+
+// serveInvalidParameter dispatches the request whose wildcard values
+// don't satisfy the route constraints to the handler registered with
+// [Router.InvalidParameter], or treats it as not found otherwise.
+func (router *Router[H]) serveInvalidParameter(w http.ResponseWriter, r *http.Request, err *ParameterError) {
+	root := router.root()
+
+	if root.invalidParameter != nil {
+		root.invalidParameter.serve(w, r.WithContext(context.WithValue(r.Context(), parameterErrorKey{}, err)))
+
+		return
+	}
+
+	if root.notFound != nil {
+		root.notFound.serve(w, r)
+
+		return
+	}
+
+	http.NotFound(w, r)
+}
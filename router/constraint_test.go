@@ -0,0 +1,226 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+func TestConstraintIntRejectsNonIntegers(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{id}", status(http.StatusOK)).Where("id", router.Int())
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/users/42", nil)); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/users/abc", nil)); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestConstraintBuiltins(t *testing.T) {
+	t.Parallel()
+
+	if !router.UUID().Match("f47ac10b-58cc-4372-a567-0e02b2c3d479") || router.UUID().Match("f47ac10b") {
+		t.Fatalf("unexpected uuid constraint result")
+	}
+
+	if !router.Slug().Match("hello-world") || router.Slug().Match("Hello--world") {
+		t.Fatalf("unexpected slug constraint result")
+	}
+
+	if !router.Regex(`[a-z]{2}`).Match("ab") || router.Regex(`[a-z]{2}`).Match("abc") {
+		t.Fatalf("unexpected regex constraint result")
+	}
+
+	if !router.Enum("draft", "published").Match("draft") || router.Enum("draft", "published").Match("archived") {
+		t.Fatalf("unexpected enum constraint result")
+	}
+}
+
+func TestConstraintUsesNotFoundHandler(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.NotFound(status(http.StatusTeapot))
+	rt.Get("/users/{id}", status(http.StatusOK)).Where("id", router.Int())
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/users/abc", nil)); res.StatusCode != http.StatusTeapot {
+		t.Fatalf("expected status %d but got %d", http.StatusTeapot, res.StatusCode)
+	}
+}
+
+func TestConstraintLetsServeMuxRedirectToCleanPaths(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{id}", status(http.StatusOK)).Where("id", router.Int())
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/users//42", nil))
+
+	if res.Header.Get("Location") != "/users/42" {
+		t.Fatalf("expected a redirect to %q but got status %d", "/users/42", res.StatusCode)
+	}
+}
+
+func TestConstraintInvalidParameterHandler(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	var received *router.ParameterError
+
+	rt.InvalidParameter(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = router.ParameterErrorFrom(r)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	rt.Get("/posts/{status}", status(http.StatusOK)).Where("status", router.Enum("draft", "published"))
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/posts/archived", nil)); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d but got %d", http.StatusBadRequest, res.StatusCode)
+	}
+
+	if received == nil || received.Parameter != "status" || received.Value != "archived" {
+		t.Fatalf("unexpected parameter error %+v", received)
+	}
+
+	if !errors.Is(received, router.ErrConstraint) {
+		t.Fatalf("expected parameter error to match ErrConstraint")
+	}
+
+	if errors.Is(received, router.ErrInvalidParameter) {
+		t.Fatalf("expected parameter error not to match ErrInvalidParameter")
+	}
+}
+
+func TestConstraintUnescapesValues(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/tags/{tag}", status(http.StatusOK)).Where("tag", router.Enum("a b"))
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/tags/a%20b", nil)); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestConstraintAppliesToRemainingWildcard(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/files/{path...}", status(http.StatusOK)).Where("path", router.Regex(`[a-z/]+`))
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/files/docs/readme", nil)); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/files/docs/v2", nil)); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestConstraintAppliesToGroupPrefixWildcard(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Group("/teams/{team}", func(team *router.Router[http.HandlerFunc]) {
+		team.Get("/members", status(http.StatusOK)).Where("team", router.Int())
+	})
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/teams/7/members", nil)); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/teams/x/members", nil)); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestRouterWhereConstrainsGroupRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Group("/teams/{team}", func(team *router.Router[http.HandlerFunc]) {
+		team = team.Where("team", router.Int())
+		team.Get("/members", status(http.StatusOK))
+		team.Get("/projects/{project}", status(http.StatusOK))
+	})
+
+	rt.Get("/teams/{team}/about", status(http.StatusOK))
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/teams/x/projects/1", nil)); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/teams/x/about", nil)); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestConstraintAppliesToTrailingSlashPattern(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{id}", status(http.StatusOK)).Where("id", router.Int())
+
+	if res := rt.Record(httptest.NewRequest(http.MethodGet, "/users/abc/", nil)); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestConstraintAppliesToHostRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("{tenant}.example.com", func(tenant *router.Router[http.HandlerFunc]) {
+		tenant.Get("/users/{id}", status(http.StatusOK)).Where("id", router.Int())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://acme.example.com/users/abc", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "http://acme.example.com/users/1", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestConstraintsAreVisibleInRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/posts/{post}", status(http.StatusOK)).Where("post", router.Int())
+
+	routes := rt.Routes()
+
+	if len(routes) != 1 || routes[0].Constraints["post"] != "int" {
+		t.Fatalf("unexpected routes %+v", routes)
+	}
+}
+
+func TestConstraintPanicsOnUnknownWildcard(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected Where to panic")
+		}
+	}()
+
+	rt.Get("/posts/{post}", status(http.StatusOK)).Where("id", router.Int())
+}
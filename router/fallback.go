@@ -0,0 +1,137 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fallback stores a handler registered with [Router.NotFound] or
+// [Router.MethodNotAllowed] along with the router it was registered
+// on, so that it can be wrapped with that router's middleware.
+type fallback[H http.Handler] struct {
+	// router stores the [Router] the handler was registered on.
+	router *Router[H]
+
+	// handler stores the unwrapped handler.
+	handler H
+
+	// once guards the lazy wrapping of the handler.
+	once sync.Once
+
+	// wrapped stores the handler wrapped with the router's
+	// middleware, computed on the first request.
+	wrapped H
+}
+
+// serve dispatches the request to the wrapped handler. The handler
+// is wrapped on the first request rather than on registration so
+// that middleware added with [Router.Use] after the fallback was
+// registered, such as CORS or logging middleware added after
+// [framework.New] sets its defaults, still applies to it.
+func (fallback *fallback[H]) serve(w http.ResponseWriter, r *http.Request) {
+	fallback.once.Do(func() {
+		fallback.wrapped = fallback.router.wrap(fallback.handler)
+	})
+
+	fallback.wrapped.ServeHTTP(w, r)
+}
+
+// NotFound registers the handler that is called when no route
+// matches the request path. The handler runs through the middleware
+// of the router it was registered on, so responses still get the
+// same CORS, logging or security headers as any other route.
+//
+// The handler applies to the whole router tree, registering it on
+// a sub-router replaces the one registered on the root. When no
+// handler is registered, the plain text response of [http.ServeMux]
+// is used instead.
+func (router *Router[H]) NotFound(handler H) {
+	router.root().notFound = &fallback[H]{
+		router:  router,
+		handler: handler,
+	}
+}
+
+// MethodNotAllowed registers the handler that is called when a
+// route matches the request path but not its method. The handler
+// runs through the middleware of the router it was registered on,
+// just like [Router.NotFound].
+//
+// Before calling the handler, the Allow header is set to the
+// methods registered for the request path, as required by
+// RFC 9110. When no handler is registered, the plain text
+// response of [http.ServeMux] is used instead.
+func (router *Router[H]) MethodNotAllowed(handler H) {
+	router.root().methodNotAllowed = &fallback[H]{
+		router:  router,
+		handler: handler,
+	}
+}
+
+// Allowed returns the methods that have a route matching the path
+// of the given request, sorted alphabetically. HEAD is included
+// whenever GET is, as [http.ServeMux] serves HEAD requests with
+// GET handlers. It returns an empty slice when no route matches
+// the request path.
+func (router *Router[H]) Allowed(request *http.Request) []string {
+	root := router.root()
+	candidates := make([]string, 0, len(allMethods))
+
+	for _, route := range root.routes {
+		if !slices.Contains(candidates, route.Method) {
+			candidates = append(candidates, route.Method)
+		}
+
+		if route.Method == http.MethodGet && !slices.Contains(candidates, http.MethodHead) {
+			candidates = append(candidates, http.MethodHead)
+		}
+	}
+
+	allowed := make([]string, 0, len(candidates))
+	probe := request.WithContext(request.Context())
+
+	for _, method := range candidates {
+		probe.Method = method
+
+		if _, pattern := root.resolve(probe).Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+
+	slices.Sort(allowed)
+
+	return allowed
+}
+
+// serveFallback dispatches the given request, which matches no
+// route, to the handlers registered with [Router.NotFound] or
+// [Router.MethodNotAllowed]. It reports whether the request was
+// handled, otherwise it must be served by the [http.ServeMux].
+func (router *Router[H]) serveFallback(w http.ResponseWriter, r *http.Request) bool {
+	root := router.root()
+
+	if root.notFound == nil && root.methodNotAllowed == nil {
+		return false
+	}
+
+	if allowed := router.Allowed(r); len(allowed) > 0 {
+		if root.methodNotAllowed == nil {
+			return false
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		root.methodNotAllowed.serve(w, r)
+
+		return true
+	}
+
+	if root.notFound == nil {
+		return false
+	}
+
+	root.notFound.serve(w, r)
+
+	return true
+}
@@ -0,0 +1,170 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+func TestNotFoundDefaultsToServeMux(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop)
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestNotFoundCallsHandler(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop)
+	rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if res.StatusCode != http.StatusTeapot {
+		t.Fatalf("expected status %d but got %d", http.StatusTeapot, res.StatusCode)
+	}
+}
+
+func TestNotFoundRunsThroughMiddleware(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	// Middleware registered after the handler still applies to it.
+	rt.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "applied")
+			next(w, r)
+		}
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if res.Header.Get("X-Middleware") != "applied" {
+		t.Fatal("expected middleware to run for the not found handler")
+	}
+}
+
+func TestNotFoundDoesNotAffectMatchedRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/users/", nil))
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestNotFoundLetsServeMuxRedirectToCleanPaths(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop)
+	rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/missing//", nil))
+
+	if res.Header.Get("Location") != "/missing/" {
+		t.Fatalf("expected a redirect to %q but got status %d", "/missing/", res.StatusCode)
+	}
+}
+
+func TestMethodNotAllowedCallsHandlerWithAllowHeader(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{id}", noop)
+	rt.Delete("/users/{id}", noop)
+	rt.Post("/users", noop)
+	rt.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodPut, "/users/1", nil))
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %d but got %d", http.StatusMethodNotAllowed, res.StatusCode)
+	}
+
+	if expected := "DELETE, GET, HEAD"; res.Header.Get("Allow") != expected {
+		t.Fatalf("expected Allow header %q but got %q", expected, res.Header.Get("Allow"))
+	}
+}
+
+func TestMethodNotAllowedIsNotCalledForUnknownPaths(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop)
+	rt.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestMethodNotAllowedRegisteredOnSubRouter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Post("/users", noop)
+
+	rt.Group("/api", func(api *router.Router[http.HandlerFunc]) {
+		api.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if res.StatusCode != http.StatusTeapot {
+		t.Fatalf("expected status %d but got %d", http.StatusTeapot, res.StatusCode)
+	}
+}
+
+func TestAllowedReturnsMethodsForPath(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Post("/users", noop)
+	rt.Put("/users/{id}", noop)
+
+	allowed := rt.Allowed(httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if len(allowed) != 1 || allowed[0] != http.MethodPost {
+		t.Fatalf("expected only POST to be allowed but got %v", allowed)
+	}
+
+	if allowed := rt.Allowed(httptest.NewRequest(http.MethodGet, "/missing", nil)); len(allowed) != 0 {
+		t.Fatalf("expected no methods to be allowed but got %v", allowed)
+	}
+}
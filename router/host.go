@@ -0,0 +1,207 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// host stores a host group registered with [Router.Host]. Each
+// host group has its own [http.ServeMux] because [http.ServeMux]
+// only supports literal hosts, while host groups may capture
+// labels such as the subdomain in "{tenant}.example.com".
+type host struct {
+	// pattern stores the host pattern as given to [Router.Host].
+	pattern string
+
+	// labels stores the dot separated labels of the pattern.
+	// Wildcard labels are stored with their braces.
+	labels []string
+
+	// literals stores the number of labels that are not
+	// wildcards, used to prefer the most specific host.
+	literals int
+
+	// native stores the [http.ServeMux] where the routes
+	// of the host group are registered.
+	native *http.ServeMux
+}
+
+// newHost parses the given host pattern. It panics if the pattern
+// is not a valid host pattern, as it's a programming error.
+func newHost(pattern string) *host {
+	if pattern == "" || strings.ContainsAny(pattern, "/:") {
+		panic(fmt.Sprintf("router: invalid host pattern %q", pattern))
+	}
+
+	labels := strings.Split(strings.ToLower(pattern), ".")
+	literals := 0
+
+	for _, label := range labels {
+		if name, ok := hostWildcard(label); ok {
+			if name == "" || strings.ContainsAny(name, "{}") {
+				panic(fmt.Sprintf("router: invalid host pattern %q", pattern))
+			}
+
+			continue
+		}
+
+		if label == "" || strings.ContainsAny(label, "{}") {
+			panic(fmt.Sprintf("router: invalid host pattern %q", pattern))
+		}
+
+		literals++
+	}
+
+	return &host{
+		pattern:  pattern,
+		labels:   labels,
+		literals: literals,
+		native:   http.NewServeMux(),
+	}
+}
+
+// hostWildcard returns the name of the wildcard in the given
+// host label and reports whether the label is a wildcard.
+func hostWildcard(label string) (string, bool) {
+	if !strings.HasPrefix(label, "{") || !strings.HasSuffix(label, "}") {
+		return "", false
+	}
+
+	return label[1 : len(label)-1], true
+}
+
+// match reports whether the given hostname, already lowercased and
+// without port, matches the host pattern, along with the values of
+// its wildcard labels.
+func (host *host) match(hostname string) (map[string]string, bool) {
+	labels := strings.Split(hostname, ".")
+
+	if len(labels) != len(host.labels) {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+
+	for i, label := range host.labels {
+		if name, ok := hostWildcard(label); ok && labels[i] != "" {
+			values[name] = labels[i]
+
+			continue
+		}
+
+		if label != labels[i] {
+			return nil, false
+		}
+	}
+
+	return values, true
+}
+
+// hostname returns the lowercased hostname of the given request,
+// without the port and the trailing dot of fully qualified names.
+func hostname(request *http.Request) string {
+	hostname := request.Host
+
+	if index := strings.LastIndexByte(hostname, ':'); index > strings.LastIndexByte(hostname, ']') {
+		hostname = hostname[:index]
+	}
+
+	return strings.TrimSuffix(strings.ToLower(hostname), ".")
+}
+
+// Host creates a sub-router whose routes only match requests
+// made to the given host, which is useful to serve several
+// domains or per-tenant subdomains from the same binary.
+//
+// Host labels wrapped in braces are wildcards that match any
+// single label, and their value can be read like any other path
+// parameter, e.g. with [http.Request.PathValue]:
+//
+//	router.Host("{tenant}.example.com", func(tenant *Router[H]) {
+//	    tenant.Get("/dashboard", dashboard) // r.PathValue("tenant")
+//	})
+//
+// The sub-router inherits the pattern, middlewares and name prefix
+// of the current router, and composes with [Router.Group],
+// [Router.With] and any other sub-router. When several host groups
+// match a request, the one with the most literal labels wins. When
+// the matching host group has no route for the request, the routes
+// registered without a host are used instead, just like
+// [http.ServeMux] does for host-qualified patterns.
+//
+// The port of the request host is ignored and [Router.URL] only
+// generates the path of host routes. It panics if the pattern is
+// not a valid host pattern or if host groups are nested.
+func (router *Router[H]) Host(pattern string, subrouter func(*Router[H])) {
+	if router.host != "" {
+		panic("router: host groups must not be nested")
+	}
+
+	root := router.root()
+	entry := newHost(pattern)
+
+	// Host groups with the same pattern share the same mux, as
+	// otherwise only the first one would ever match a request.
+	if index := slices.IndexFunc(root.hosts, func(existing *host) bool {
+		return slices.Equal(existing.labels, entry.labels)
+	}); index >= 0 {
+		entry = root.hosts[index]
+	} else {
+		root.hosts = append(root.hosts, entry)
+	}
+
+	subrouter(&Router[H]{
+		native:      entry.native,
+		pattern:     router.pattern,
+		parent:      router,
+		middlewares: slices.Clone(router.middlewares),
+		name:        router.name,
+		host:        entry.pattern,
+		constraints: router.constraints,
+	})
+}
+
+// matchHost returns the host group that matches the given request,
+// along with the values of its wildcard labels, or nil if no host
+// group matches it.
+func (router *Router[H]) matchHost(request *http.Request) (*host, map[string]string) {
+	hosts := router.root().hosts
+
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	hostname := hostname(request)
+
+	var (
+		matched *host
+		values  map[string]string
+	)
+
+	for _, candidate := range hosts {
+		if matched != nil && candidate.literals <= matched.literals {
+			continue
+		}
+
+		if candidateValues, ok := candidate.match(hostname); ok {
+			matched, values = candidate, candidateValues
+		}
+	}
+
+	return matched, values
+}
+
+// resolve returns the [http.ServeMux] that serves the given request.
+// It's the mux of the matching host group when it has a route for
+// the request, or the root mux otherwise.
+func (router *Router[H]) resolve(request *http.Request) *http.ServeMux {
+	if matched, _ := router.matchHost(request); matched != nil {
+		if _, pattern := matched.native.Handler(request); pattern != "" {
+			return matched.native
+		}
+	}
+
+	return router.root().native
+}
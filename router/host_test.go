@@ -0,0 +1,303 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+func TestHostMatchesLiteralHost(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("api.example.com", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/users", status(http.StatusOK))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/users", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "http://www.example.com/users", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestHostIgnoresPortAndCase(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("api.example.com", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/users", status(http.StatusOK))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://API.Example.com:8080/users", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestHostCapturesWildcardLabel(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	tenant := ""
+	id := ""
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			tenant = r.PathValue("tenant")
+			id = r.PathValue("id")
+		})
+	})
+
+	rt.Record(httptest.NewRequest(http.MethodGet, "http://acme.example.com/users/1", nil))
+
+	if tenant != "acme" {
+		t.Fatalf("expected tenant %q but got %q", "acme", tenant)
+	}
+
+	if id != "1" {
+		t.Fatalf("expected id %q but got %q", "1", id)
+	}
+}
+
+func TestHostWildcardsDoNotLeakIntoRoutesWithoutHost(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	tenant := "unset"
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/dashboard", status(http.StatusOK))
+	})
+
+	rt.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.PathValue("tenant")
+	})
+
+	rt.Record(httptest.NewRequest(http.MethodGet, "http://acme.example.com/users", nil))
+
+	if tenant != "" {
+		t.Fatalf("expected no tenant but got %q", tenant)
+	}
+}
+
+func TestHostWildcardRequiresSameLabelCount(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/", status(http.StatusOK))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestHostPrefersMostSpecificHost(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/", status(http.StatusAccepted))
+	})
+
+	rt.Host("api.example.com", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/", status(http.StatusOK))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestHostFallsBackToRoutesWithoutHost(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/health", status(http.StatusOK))
+	rt.Get("/users", status(http.StatusAccepted))
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/users", status(http.StatusCreated))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://acme.example.com/health", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "http://acme.example.com/users", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/users", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d but got %d", http.StatusAccepted, res.StatusCode)
+	}
+}
+
+func TestHostComposesWithGroupAndMiddleware(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Root", "applied")
+			next(w, r)
+		}
+	})
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Group("/admin", func(admin *router.Router[http.HandlerFunc]) {
+			admin.With(func(next http.HandlerFunc) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Admin", "applied")
+					next(w, r)
+				}
+			}).Get("/users", status(http.StatusOK))
+		})
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "http://acme.example.com/admin/users/", nil))
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	if res.Header.Get("X-Root") != "applied" || res.Header.Get("X-Admin") != "applied" {
+		t.Fatal("expected both middlewares to run")
+	}
+}
+
+func TestHostRoutesAreRecorded(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/users", noop).Name("tenant.users")
+	})
+
+	route := rt.Routes()[0]
+
+	if expected := "GET {tenant}.example.com/users"; route.String() != expected {
+		t.Fatalf("expected route %q but got %q", expected, route.String())
+	}
+
+	if url, err := rt.URL("tenant.users"); err != nil || url != "/users" {
+		t.Fatalf("expected url %q but got %q (%v)", "/users", url, err)
+	}
+}
+
+func TestHostSamePatternSharesRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("api.example.com", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/users", status(http.StatusOK))
+	})
+
+	rt.Host("api.example.com", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/posts", status(http.StatusOK))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com/posts", nil)
+
+	if res := rt.Record(req); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+}
+
+func TestHostMethodNotAllowedUsesHostRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.MethodNotAllowed(status(http.StatusMethodNotAllowed))
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Post("/users", noop)
+	})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "http://acme.example.com/users", nil))
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %d but got %d", http.StatusMethodNotAllowed, res.StatusCode)
+	}
+
+	if res.Header.Get("Allow") != http.MethodPost {
+		t.Fatalf("expected Allow header %q but got %q", http.MethodPost, res.Header.Get("Allow"))
+	}
+}
+
+func TestHostHasMatchesHostRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Host("{tenant}.example.com", func(tenants *router.Router[http.HandlerFunc]) {
+		tenants.Get("/users", noop)
+	})
+
+	if !rt.Has(http.MethodGet, "http://acme.example.com/users") {
+		t.Fatal("expected host route to be found")
+	}
+
+	if rt.Has(http.MethodGet, "/users") {
+		t.Fatal("expected host route not to be found without host")
+	}
+}
+
+func TestHostPanicsOnInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for invalid host pattern")
+		}
+	}()
+
+	router.New[http.HandlerFunc]().Host("example.com/users", func(*router.Router[http.HandlerFunc]) {})
+}
+
+func TestHostPanicsWhenNested(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for nested host groups")
+		}
+	}()
+
+	router.New[http.HandlerFunc]().Host("example.com", func(parent *router.Router[http.HandlerFunc]) {
+		parent.Host("api.example.com", func(*router.Router[http.HandlerFunc]) {})
+	})
+}
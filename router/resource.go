@@ -0,0 +1,298 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Action identifies one of the routes registered by [Router.Resource].
+type Action string
+
+const (
+	// ActionIndex lists the resources: GET /posts.
+	ActionIndex Action = "index"
+
+	// ActionCreate shows the form to create a resource: GET /posts/create.
+	ActionCreate Action = "create"
+
+	// ActionStore creates a resource: POST /posts.
+	ActionStore Action = "store"
+
+	// ActionShow shows a resource: GET /posts/{post}.
+	ActionShow Action = "show"
+
+	// ActionEdit shows the form to edit a resource: GET /posts/{post}/edit.
+	ActionEdit Action = "edit"
+
+	// ActionUpdate updates a resource: PUT and PATCH /posts/{post}.
+	ActionUpdate Action = "update"
+
+	// ActionDestroy deletes a resource: DELETE /posts/{post}.
+	ActionDestroy Action = "destroy"
+)
+
+// Indexer is implemented by resource controllers that handle [ActionIndex].
+type Indexer[H http.Handler] interface {
+	Index() H
+}
+
+// Creator is implemented by resource controllers that handle [ActionCreate].
+type Creator[H http.Handler] interface {
+	Create() H
+}
+
+// Storer is implemented by resource controllers that handle [ActionStore].
+type Storer[H http.Handler] interface {
+	Store() H
+}
+
+// Shower is implemented by resource controllers that handle [ActionShow].
+type Shower[H http.Handler] interface {
+	Show() H
+}
+
+// Editor is implemented by resource controllers that handle [ActionEdit].
+type Editor[H http.Handler] interface {
+	Edit() H
+}
+
+// Updater is implemented by resource controllers that handle [ActionUpdate].
+type Updater[H http.Handler] interface {
+	Update() H
+}
+
+// Destroyer is implemented by resource controllers that handle [ActionDestroy].
+type Destroyer[H http.Handler] interface {
+	Destroy() H
+}
+
+// ResourceOptions configures the routes registered by [Router.ResourceWith].
+type ResourceOptions struct {
+	// Only restricts the registered routes to the given actions.
+	// All the actions implemented by the controller are registered
+	// when empty.
+	Only []Action
+
+	// Except excludes the given actions from the registered routes.
+	Except []Action
+
+	// Parameter is the name of the wildcard that identifies a single
+	// resource. Defaults to the singular form of the last pattern
+	// segment, e.g. "post" for "/posts".
+	Parameter string
+
+	// Name is the name prefix of the registered routes, which are
+	// named after it followed by a dot and the action, e.g.
+	// "posts.index". Defaults to the literal segments of the
+	// pattern joined by dots, e.g. "posts.comments" for
+	// "/posts/{post}/comments".
+	Name string
+}
+
+// resourceActions stores the resource actions in registration order.
+var resourceActions = []Action{
+	ActionIndex,
+	ActionCreate,
+	ActionStore,
+	ActionShow,
+	ActionEdit,
+	ActionUpdate,
+	ActionDestroy,
+}
+
+// identifierPattern matches the characters that are not allowed in
+// [http.ServeMux] wildcard names.
+var identifierPattern = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Resource registers the conventional CRUD routes of a resource for
+// every action the controller implements, see [Indexer], [Creator],
+// [Storer], [Shower], [Editor], [Updater] and [Destroyer]:
+//
+//	GET    /posts              index
+//	GET    /posts/create       create
+//	POST   /posts              store
+//	GET    /posts/{post}       show
+//	GET    /posts/{post}/edit  edit
+//	PUT    /posts/{post}       update
+//	PATCH  /posts/{post}       update
+//	DELETE /posts/{post}       destroy
+//
+// Nested resources are registered by including the parent wildcard
+// in the pattern, e.g. "/posts/{post}/comments". The routes are named
+// after the resource and the action, e.g. "posts.show", and the name
+// prefix of the router applies, see [Router.Named].
+//
+// It returns the registered routes by action. Use [Router.ResourceWith]
+// to customize the registered routes.
+func (router *Router[H]) Resource(pattern string, controller any) map[Action]*Route {
+	return router.ResourceWith(pattern, controller, ResourceOptions{})
+}
+
+// ResourceWith registers the routes of a resource like [Router.Resource]
+// using the given options.
+//
+// It panics if the controller implements none of the resource
+// interfaces, if an action listed in [ResourceOptions.Only] is not
+// implemented by the controller, or if the name or parameter can't
+// be derived from the pattern and were not given.
+func (router *Router[H]) ResourceWith(pattern string, controller any, options ResourceOptions) map[Action]*Route {
+	handlers := resourceHandlers[H](controller)
+
+	if len(handlers) == 0 {
+		panic(fmt.Sprintf("router: resource controller %T implements no action", controller))
+	}
+
+	for _, action := range options.Only {
+		if _, ok := handlers[action]; !ok {
+			panic(fmt.Sprintf("router: resource controller %T does not implement %s", controller, action))
+		}
+	}
+
+	name := options.Name
+
+	if name == "" {
+		name = resourceName(pattern)
+	}
+
+	if name == "" {
+		panic(fmt.Sprintf("router: resource pattern %q needs an explicit name", pattern))
+	}
+
+	parameter := options.Parameter
+
+	if parameter == "" {
+		parameter = resourceParameter(pattern)
+	}
+
+	member := path.Join(pattern, "{"+parameter+"}")
+	routes := make(map[Action]*Route)
+
+	for _, action := range resourceActions {
+		handler, ok := handlers[action]
+
+		if !ok || slices.Contains(options.Except, action) {
+			continue
+		}
+
+		if len(options.Only) > 0 && !slices.Contains(options.Only, action) {
+			continue
+		}
+
+		var route *Route
+
+		switch action {
+		case ActionIndex:
+			route = router.Get(pattern, handler)
+		case ActionCreate:
+			route = router.Get(path.Join(pattern, "create"), handler)
+		case ActionStore:
+			route = router.Post(pattern, handler)
+		case ActionShow:
+			route = router.Get(member, handler)
+		case ActionEdit:
+			route = router.Get(path.Join(member, "edit"), handler)
+		case ActionUpdate:
+			route = router.Methods([]string{http.MethodPut, http.MethodPatch}, member, handler)
+		case ActionDestroy:
+			route = router.Delete(member, handler)
+		}
+
+		routes[action] = route.Name(name + "." + string(action))
+	}
+
+	return routes
+}
+
+// resourceHandlers returns the handlers of the actions implemented
+// by the given controller.
+func resourceHandlers[H http.Handler](controller any) map[Action]H {
+	handlers := make(map[Action]H)
+
+	if indexer, ok := controller.(Indexer[H]); ok {
+		handlers[ActionIndex] = indexer.Index()
+	}
+
+	if creator, ok := controller.(Creator[H]); ok {
+		handlers[ActionCreate] = creator.Create()
+	}
+
+	if storer, ok := controller.(Storer[H]); ok {
+		handlers[ActionStore] = storer.Store()
+	}
+
+	if shower, ok := controller.(Shower[H]); ok {
+		handlers[ActionShow] = shower.Show()
+	}
+
+	if editor, ok := controller.(Editor[H]); ok {
+		handlers[ActionEdit] = editor.Edit()
+	}
+
+	if updater, ok := controller.(Updater[H]); ok {
+		handlers[ActionUpdate] = updater.Update()
+	}
+
+	if destroyer, ok := controller.(Destroyer[H]); ok {
+		handlers[ActionDestroy] = destroyer.Destroy()
+	}
+
+	return handlers
+}
+
+// resourceName returns the default route name prefix of the resource
+// with the given pattern, made of its literal segments joined by dots.
+func resourceName(pattern string) string {
+	names := make([]string, 0)
+
+	for segment := range strings.SplitSeq(pattern, "/") {
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			continue
+		}
+
+		names = append(names, segment)
+	}
+
+	return strings.Join(names, ".")
+}
+
+// resourceParameter returns the default wildcard name of the resource
+// with the given pattern, which is the singular form of its last
+// segment made a valid wildcard name.
+func resourceParameter(pattern string) string {
+	parameter := identifierPattern.ReplaceAllString(singular(path.Base(pattern)), "_")
+	parameter = strings.Trim(parameter, "_")
+
+	if parameter == "" {
+		panic(fmt.Sprintf("router: resource pattern %q needs an explicit parameter", pattern))
+	}
+
+	return parameter
+}
+
+// singular returns a best effort singular form of the given
+// english plural noun. It covers the regular forms, irregular
+// nouns require an explicit [ResourceOptions.Parameter].
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "uses") && len(word) > 4 && !strings.ContainsRune("aeiou", rune(word[len(word)-5])):
+		// Nouns ending in "us", such as "statuses" or "buses", as
+		// opposed to nouns ending in "use", such as "houses".
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
@@ -0,0 +1,262 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+type posts struct{}
+
+func (posts) Index() http.HandlerFunc   { return status(http.StatusOK) }
+func (posts) Create() http.HandlerFunc  { return status(http.StatusOK) }
+func (posts) Store() http.HandlerFunc   { return status(http.StatusCreated) }
+func (posts) Show() http.HandlerFunc    { return status(http.StatusOK) }
+func (posts) Edit() http.HandlerFunc    { return status(http.StatusOK) }
+func (posts) Update() http.HandlerFunc  { return status(http.StatusAccepted) }
+func (posts) Destroy() http.HandlerFunc { return status(http.StatusNoContent) }
+
+type comments struct{}
+
+func (comments) Index() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Post", r.PathValue("post"))
+	}
+}
+
+func (comments) Show() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Post", r.PathValue("post"))
+		w.Header().Set("X-Comment", r.PathValue("comment"))
+	}
+}
+
+func routeStrings(rt *router.Router[http.HandlerFunc]) map[string]string {
+	result := make(map[string]string)
+
+	for _, route := range rt.Routes() {
+		result[route.String()] = route.Name
+	}
+
+	return result
+}
+
+func TestResourceRegistersEveryAction(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Resource("/posts", posts{})
+
+	expected := map[string]string{
+		"GET /posts":             "posts.index",
+		"GET /posts/create":      "posts.create",
+		"POST /posts":            "posts.store",
+		"GET /posts/{post}":      "posts.show",
+		"GET /posts/{post}/edit": "posts.edit",
+		"PUT /posts/{post}":      "posts.update",
+		"PATCH /posts/{post}":    "posts.update",
+		"DELETE /posts/{post}":   "posts.destroy",
+	}
+
+	routes := routeStrings(rt)
+
+	if len(routes) != len(expected) {
+		t.Fatalf("expected %d routes but got %d", len(expected), len(routes))
+	}
+
+	for route, name := range expected {
+		if routes[route] != name {
+			t.Fatalf("expected route %q to be named %q but got %q", route, name, routes[route])
+		}
+	}
+}
+
+func TestResourceDispatchesToController(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Resource("/posts", posts{})
+
+	if res := rt.Record(httptest.NewRequest(http.MethodPost, "/posts", nil)); res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d but got %d", http.StatusCreated, res.StatusCode)
+	}
+
+	if res := rt.Record(httptest.NewRequest(http.MethodPatch, "/posts/1", nil)); res.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d but got %d", http.StatusAccepted, res.StatusCode)
+	}
+
+	if res := rt.Record(httptest.NewRequest(http.MethodDelete, "/posts/1/", nil)); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d but got %d", http.StatusNoContent, res.StatusCode)
+	}
+}
+
+func TestResourceRegistersImplementedActionsOnly(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Resource("/comments", comments{})
+
+	routes := routeStrings(rt)
+
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes but got %d", len(routes))
+	}
+
+	if routes["GET /comments/{comment}"] != "comments.show" {
+		t.Fatalf("expected show route to be registered but got %v", routes)
+	}
+}
+
+func TestResourceSupportsNestedResources(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Resource("/posts/{post}/comments", comments{})
+
+	res := rt.Record(httptest.NewRequest(http.MethodGet, "/posts/1/comments/2", nil))
+
+	if res.Header.Get("X-Post") != "1" || res.Header.Get("X-Comment") != "2" {
+		t.Fatalf("expected post 1 and comment 2 but got %q and %q", res.Header.Get("X-Post"), res.Header.Get("X-Comment"))
+	}
+
+	if url, err := rt.URL("posts.comments.show", "1", "2"); err != nil || url != "/posts/1/comments/2" {
+		t.Fatalf("expected url %q but got %q (%v)", "/posts/1/comments/2", url, err)
+	}
+}
+
+func TestResourceWithOnly(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	routes := rt.ResourceWith("/posts", posts{}, router.ResourceOptions{
+		Only: []router.Action{router.ActionIndex, router.ActionShow},
+	})
+
+	if len(routes) != 2 || routes[router.ActionIndex] == nil || routes[router.ActionShow] == nil {
+		t.Fatalf("expected index and show routes but got %v", routes)
+	}
+}
+
+func TestResourceWithExcept(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	routes := rt.ResourceWith("/posts", posts{}, router.ResourceOptions{
+		Except: []router.Action{router.ActionCreate, router.ActionEdit},
+	})
+
+	if len(routes) != 5 {
+		t.Fatalf("expected 5 routes but got %d", len(routes))
+	}
+
+	if _, ok := routes[router.ActionCreate]; ok {
+		t.Fatal("expected create route to be excluded")
+	}
+}
+
+func TestResourceWithParameterAndName(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	routes := rt.ResourceWith("/people", posts{}, router.ResourceOptions{
+		Only:      []router.Action{router.ActionShow},
+		Parameter: "person",
+		Name:      "users",
+	})
+
+	if expected := "/people/{person}"; routes[router.ActionShow].Pattern() != expected {
+		t.Fatalf("expected pattern %q but got %q", expected, routes[router.ActionShow].Pattern())
+	}
+
+	if _, err := rt.URL("users.show", "1"); err != nil {
+		t.Fatalf("expected route to be named users.show but got %v", err)
+	}
+}
+
+func TestResourceDerivesSingularParameter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	categories := rt.ResourceWith("/categories", posts{}, router.ResourceOptions{Only: []router.Action{router.ActionShow}})
+	boxes := rt.ResourceWith("/boxes", posts{}, router.ResourceOptions{Only: []router.Action{router.ActionShow}})
+	news := rt.ResourceWith("/news-items", posts{}, router.ResourceOptions{Only: []router.Action{router.ActionShow}})
+
+	if expected := "/categories/{category}"; categories[router.ActionShow].Pattern() != expected {
+		t.Fatalf("expected pattern %q but got %q", expected, categories[router.ActionShow].Pattern())
+	}
+
+	if expected := "/boxes/{box}"; boxes[router.ActionShow].Pattern() != expected {
+		t.Fatalf("expected pattern %q but got %q", expected, boxes[router.ActionShow].Pattern())
+	}
+
+	if expected := "/news-items/{news_item}"; news[router.ActionShow].Pattern() != expected {
+		t.Fatalf("expected pattern %q but got %q", expected, news[router.ActionShow].Pattern())
+	}
+}
+
+func TestResourceDerivesSingularParameterOfSesPlurals(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	only := router.ResourceOptions{Only: []router.Action{router.ActionShow}}
+
+	expectations := map[string]string{
+		"/statuses":  "/statuses/{status}",
+		"/buses":     "/buses/{bus}",
+		"/campuses":  "/campuses/{campus}",
+		"/addresses": "/addresses/{address}",
+		"/houses":    "/houses/{house}",
+		"/cases":     "/cases/{case}",
+		"/responses": "/responses/{response}",
+	}
+
+	for pattern, expected := range expectations {
+		routes := rt.ResourceWith(pattern, posts{}, only)
+
+		if routes[router.ActionShow].Pattern() != expected {
+			t.Fatalf("expected pattern %q but got %q", expected, routes[router.ActionShow].Pattern())
+		}
+	}
+}
+
+func TestResourceAppliesGroupAndNamePrefix(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Named("api.").Group("/api", func(api *router.Router[http.HandlerFunc]) {
+		api.ResourceWith("/posts", posts{}, router.ResourceOptions{Only: []router.Action{router.ActionIndex}})
+	})
+
+	if url, err := rt.URL("api.posts.index"); err != nil || url != "/api/posts" {
+		t.Fatalf("expected url %q but got %q (%v)", "/api/posts", url, err)
+	}
+}
+
+func TestResourcePanicsWithoutActions(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for controller without actions")
+		}
+	}()
+
+	router.New[http.HandlerFunc]().Resource("/posts", struct{}{})
+}
+
+func TestResourcePanicsOnUnimplementedOnlyAction(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for unimplemented action")
+		}
+	}()
+
+	router.New[http.HandlerFunc]().ResourceWith("/comments", comments{}, router.ResourceOptions{
+		Only: []router.Action{router.ActionStore},
+	})
+}
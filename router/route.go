@@ -0,0 +1,158 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Route represents a pattern registered on a [Router] for one or
+// more methods. It is returned by the route registration methods
+// such as [Router.Get] or [Router.Method] and can be used to name
+// the route so that its URL can be generated with [Router.URL].
+type Route struct {
+	// pattern stores the full pattern of the route, already
+	// joined with the prefixes of the router that registered it.
+	pattern string
+
+	// host stores the host pattern of the [Router.Host]
+	// group the route was registered in, if any.
+	host string
+
+	// prefix stores the name prefix of the router that
+	// registered the route.
+	prefix string
+
+	// names stores the route names of the root [Router]
+	// mapped to their full patterns.
+	names map[string]string
+
+	// constraints stores the wildcard constraints of the root
+	// [Router] keyed by host and registered pattern.
+	constraints map[string]map[string]Constraint
+
+	// records stores the registrations made for the route
+	// so that they can be updated when the route is named.
+	records []*RouteInfo
+}
+
+// Name assigns the given name to the route. The name is prefixed
+// with the prefix of the router that registered the route, see
+// [Router.Named]. The route URL can then be generated using
+// [Router.URL] or [Router.URLWith].
+//
+// It panics if the name is empty or if it's already assigned to a
+// route with a different pattern, as both are programming errors
+// that would otherwise make URL generation ambiguous.
+func (route *Route) Name(name string) *Route {
+	if name == "" {
+		panic("router: route name must not be empty")
+	}
+
+	name = route.prefix + name
+
+	if existing, ok := route.names[name]; ok && existing != route.pattern {
+		panic(fmt.Sprintf("router: route name %q is already assigned to %q", name, existing))
+	}
+
+	route.names[name] = route.pattern
+
+	for _, record := range route.records {
+		record.Name = name
+	}
+
+	return route
+}
+
+// Pattern returns the full pattern of the route, including the
+// prefixes of any [Router.Group] it was registered in.
+func (route *Route) Pattern() string {
+	return route.pattern
+}
+
+// Host returns the host pattern of the [Router.Host] group the
+// route was registered in, or an empty string if none.
+func (route *Route) Host() string {
+	return route.host
+}
+
+// Methods returns the HTTP methods the route was registered with,
+// in registration order.
+func (route *Route) Methods() []string {
+	methods := make([]string, len(route.records))
+
+	for i, record := range route.records {
+		methods[i] = record.Method
+	}
+
+	return methods
+}
+
+// RouteInfo describes a single route registration made on a
+// [Router]. It is returned by [Router.Routes] and is useful for
+// logging the registered routes at startup, listing them in admin
+// endpoints, asserting the API surface in tests or generating
+// documentation.
+type RouteInfo struct {
+	// Method is the HTTP method the route was registered with.
+	Method string
+
+	// Host is the host pattern of the [Router.Host] group the
+	// route was registered in, or an empty string if none.
+	Host string
+
+	// Pattern is the full pattern of the route, joined with the
+	// prefixes of any [Router.Group] it was registered in.
+	// The root route is reported as "/".
+	Pattern string
+
+	// Name is the full name of the route, including any prefix
+	// set with [Router.Named]. It is empty for unnamed routes.
+	Name string
+
+	// Middlewares is the number of middlewares that wrap the
+	// route's handler.
+	Middlewares int
+
+	// Constraints maps the constrained wildcards of the route to
+	// the description of their [Constraint], e.g. "int". It is nil
+	// when the route has no constraints, see [Route.Where].
+	Constraints map[string]string
+
+	// Trailing is the companion pattern that was registered
+	// along with Pattern so that the route matches with or without
+	// a trailing slash. It is empty when no companion pattern was
+	// registered, such as for the root route.
+	Trailing string
+}
+
+// String returns the method, host and pattern of the route in the
+// same format used by [http.ServeMux], e.g. "GET /users/{id}" or
+// "GET {tenant}.example.com/users/{id}".
+func (info RouteInfo) String() string {
+	return fmt.Sprintf("%s %s%s", info.Method, info.Host, info.Pattern)
+}
+
+// RoutePattern returns the route of the given [http.ServeMux]
+// pattern, such as the [http.Request.Pattern] of the requests served
+// by a [Router], in the form of [RouteInfo.Pattern]: without the
+// method and host, and with the trailing slash companion pattern
+// folded into its route. Both "GET /users/{id}" and
+// "GET /users/{id}/{$}" return "/users/{id}", and "GET /{$}" returns
+// "/". It returns an empty string for patterns without a path, such
+// as the empty pattern of unmatched requests.
+//
+// Unlike request paths, routes have a bounded cardinality, which
+// makes them suitable for metric labels, span names and logs.
+func RoutePattern(pattern string) string {
+	i := strings.IndexByte(pattern, '/')
+
+	if i < 0 {
+		return ""
+	}
+
+	if route := strings.TrimSuffix(pattern[i:], "/{$}"); route != "" {
+		return route
+	}
+
+	return "/"
+}
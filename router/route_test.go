@@ -0,0 +1,243 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+func TestRoutesIsEmptyOnNewRouter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	if routes := rt.Routes(); len(routes) != 0 {
+		t.Fatalf("expected no routes but got %d", len(routes))
+	}
+}
+
+func TestRoutesRecordsRegistrationsInOrder(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop)
+	rt.Post("/users", noop)
+
+	routes := rt.Routes()
+
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes but got %d", len(routes))
+	}
+
+	if expected := "GET /users"; routes[0].String() != expected {
+		t.Fatalf("expected route %q but got %q", expected, routes[0].String())
+	}
+
+	if expected := "POST /users"; routes[1].String() != expected {
+		t.Fatalf("expected route %q but got %q", expected, routes[1].String())
+	}
+}
+
+func TestRoutesRecordsTrailingCompanion(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{id}", noop)
+
+	route := rt.Routes()[0]
+
+	if expected := "/users/{id}/{$}"; route.Trailing != expected {
+		t.Fatalf("expected trailing pattern %q but got %q", expected, route.Trailing)
+	}
+}
+
+func TestRoutesRecordsRestCompanion(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/files/{path...}", noop)
+
+	route := rt.Routes()[0]
+
+	if expected := "/files"; route.Trailing != expected {
+		t.Fatalf("expected trailing pattern %q but got %q", expected, route.Trailing)
+	}
+}
+
+func TestRoutesRootHasNoCompanion(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/", noop)
+
+	route := rt.Routes()[0]
+
+	if route.Pattern != "/" {
+		t.Fatalf("expected pattern %q but got %q", "/", route.Pattern)
+	}
+
+	if route.Trailing != "" {
+		t.Fatalf("expected no trailing pattern but got %q", route.Trailing)
+	}
+}
+
+func TestRoutesRecordsGroupPrefixAndName(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Named("api.").Group("/api", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/users/{id}", noop).Name("users.show")
+	})
+
+	route := rt.Routes()[0]
+
+	if expected := "/api/users/{id}"; route.Pattern != expected {
+		t.Fatalf("expected pattern %q but got %q", expected, route.Pattern)
+	}
+
+	if expected := "api.users.show"; route.Name != expected {
+		t.Fatalf("expected name %q but got %q", expected, route.Name)
+	}
+}
+
+func TestRoutesRecordsMiddlewareCount(t *testing.T) {
+	t.Parallel()
+
+	middleware := func(next http.HandlerFunc) http.HandlerFunc {
+		return next
+	}
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Use(middleware)
+	rt.Get("/public", noop)
+	rt.With(middleware, middleware).Get("/private", noop)
+
+	routes := rt.Routes()
+
+	if routes[0].Middlewares != 1 {
+		t.Fatalf("expected 1 middleware but got %d", routes[0].Middlewares)
+	}
+
+	if routes[1].Middlewares != 3 {
+		t.Fatalf("expected 3 middlewares but got %d", routes[1].Middlewares)
+	}
+}
+
+func TestRoutesRecordsEveryMethodOfAny(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Any("/webhook", noop).Name("webhook")
+
+	routes := rt.Routes()
+
+	if len(routes) != 7 {
+		t.Fatalf("expected 7 routes but got %d", len(routes))
+	}
+
+	for _, route := range routes {
+		if route.Name != "webhook" {
+			t.Fatalf("expected every method to be named but %s was not", route)
+		}
+	}
+}
+
+func TestRoutesIncludesSubRouterRegistrations(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Clone().Get("/clone", noop)
+
+	rt.Grouped(func(grouped *router.Router[http.HandlerFunc]) {
+		grouped.Get("/grouped", noop)
+	})
+
+	rt.Group("/group", func(group *router.Router[http.HandlerFunc]) {
+		if routes := group.Routes(); len(routes) != 2 {
+			t.Fatalf("expected sub-router to see 2 routes but got %d", len(routes))
+		}
+	})
+
+	if routes := rt.Routes(); len(routes) != 2 {
+		t.Fatalf("expected 2 routes but got %d", len(routes))
+	}
+}
+
+func TestRoutesReturnsCopy(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop)
+
+	routes := rt.Routes()
+	routes[0].Pattern = "/changed"
+
+	if expected := "/users"; rt.Routes()[0].Pattern != expected {
+		t.Fatalf("expected pattern %q but got %q", expected, rt.Routes()[0].Pattern)
+	}
+}
+
+func TestRouteMethodsReportsRegisteredMethods(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	route := rt.Methods([]string{http.MethodGet, http.MethodPost}, "/users", noop)
+
+	methods := route.Methods()
+
+	if len(methods) != 2 || methods[0] != http.MethodGet || methods[1] != http.MethodPost {
+		t.Fatalf("expected GET and POST methods but got %v", methods)
+	}
+}
+
+func TestRoutePatternStripsMethodAndHost(t *testing.T) {
+	t.Parallel()
+
+	if route := router.RoutePattern("GET /users/{id}"); route != "/users/{id}" {
+		t.Fatalf("expected route %q but got %q", "/users/{id}", route)
+	}
+
+	if route := router.RoutePattern("GET {tenant}.example.com/users"); route != "/users" {
+		t.Fatalf("expected route %q but got %q", "/users", route)
+	}
+}
+
+func TestRoutePatternFoldsTrailingSlashCompanions(t *testing.T) {
+	t.Parallel()
+
+	if route := router.RoutePattern("GET /users/{id}/{$}"); route != "/users/{id}" {
+		t.Fatalf("expected route %q but got %q", "/users/{id}", route)
+	}
+
+	if route := router.RoutePattern("GET /{$}"); route != "/" {
+		t.Fatalf("expected route %q but got %q", "/", route)
+	}
+}
+
+func TestRoutePatternOfUnmatchedRequests(t *testing.T) {
+	t.Parallel()
+
+	if route := router.RoutePattern(""); route != "" {
+		t.Fatalf("expected no route but got %q", route)
+	}
+}
+
+func TestRoutePatternMatchesRegisteredRoutes(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	route := ""
+
+	rt.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		route = router.RoutePattern(r.Pattern)
+	})
+
+	rt.Record(httptest.NewRequest(http.MethodGet, "/users/1/", nil))
+
+	if route != rt.Routes()[0].Pattern {
+		t.Fatalf("expected route %q but got %q", rt.Routes()[0].Pattern, route)
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"path"
@@ -21,6 +22,9 @@ type Middleware[H http.Handler] = func(H) H
 type Router[H http.Handler] struct {
 	// native stores the actual [http.ServeMux]
 	// that's used internally to register the routes.
+	// It's nil for sub-routers, which use the one of
+	// their parent, except for the ones created with
+	// [Router.Host] that have their own.
 	native *http.ServeMux
 
 	// pattern stores the current pattern that will be
@@ -40,6 +44,57 @@ type Router[H http.Handler] struct {
 	// router. It already contains all the middlewares of
 	// the parent's [Router] if any.
 	middlewares []Middleware[H]
+
+	// name stores the prefix that will be prepended to the
+	// name of any route registered on this router. This
+	// prefix is already joined with the parent router's
+	// prefix if any.
+	name string
+
+	// names stores the route names mapped to their full
+	// patterns. Only the root [Router] holds this map, sub-routers
+	// resolve it through their parent, the same way [Router.mux] does.
+	names map[string]string
+
+	// routes stores every route registration in the order
+	// they were made. Only the root [Router] holds these
+	// records, sub-routers resolve them through their parent.
+	routes []*RouteInfo
+
+	// notFound stores the handler registered with [Router.NotFound].
+	// Only the root [Router] holds it, sub-routers resolve it through
+	// their parent.
+	notFound *fallback[H]
+
+	// methodNotAllowed stores the handler registered with
+	// [Router.MethodNotAllowed]. Only the root [Router] holds it,
+	// sub-routers resolve it through their parent.
+	methodNotAllowed *fallback[H]
+
+	// host stores the host pattern of the [Router.Host] group
+	// the router belongs to, if any.
+	host string
+
+	// hosts stores the host groups registered with [Router.Host].
+	// Only the root [Router] holds them, sub-routers resolve them
+	// through their parent.
+	hosts []*host
+
+	// constraints stores the wildcard constraints applied to every
+	// route registered on this router, see [Router.Where]. It
+	// already contains the constraints of the parent's [Router].
+	constraints map[string]Constraint
+
+	// routeConstraints stores the wildcard constraints of each
+	// pattern registered in the [http.ServeMux], keyed by host and
+	// pattern. Only the root [Router] holds them, sub-routers resolve
+	// them through their parent.
+	routeConstraints map[string]map[string]Constraint
+
+	// invalidParameter stores the handler registered with
+	// [Router.InvalidParameter]. Only the root [Router] holds it,
+	// sub-routers resolve it through their parent.
+	invalidParameter *fallback[H]
 }
 
 // allMethods stores the HTTP methods registered by [Router.Any].
@@ -61,6 +116,10 @@ func New[H http.Handler]() *Router[H] {
 	return &Router[H]{
 		native:      http.NewServeMux(),
 		middlewares: make([]Middleware[H], 0),
+		names:       make(map[string]string),
+		routes:      make([]*RouteInfo, 0),
+
+		routeConstraints: make(map[string]map[string]Constraint),
 	}
 }
 
@@ -87,6 +146,9 @@ func (router *Router[H]) Group(pattern string, subrouter func(*Router[H])) {
 		pattern:     path.Join(router.pattern, pattern),
 		parent:      router,
 		middlewares: slices.Clone(router.middlewares),
+		name:        router.name,
+		host:        router.host,
+		constraints: router.constraints,
 	})
 }
 
@@ -103,6 +165,9 @@ func (router *Router[H]) Clone() *Router[H] {
 		pattern:     router.pattern,
 		parent:      router,
 		middlewares: slices.Clone(router.middlewares),
+		name:        router.name,
+		host:        router.host,
+		constraints: router.constraints,
 	}
 }
 
@@ -119,19 +184,89 @@ func (router *Router[H]) With(middlewares ...Middleware[H]) *Router[H] {
 		pattern:     router.pattern,
 		parent:      router,
 		middlewares: append(slices.Clone(router.middlewares), middlewares...),
+		name:        router.name,
+		host:        router.host,
+		constraints: router.constraints,
 	}
 }
 
-// mux returns the native [http.ServeMux] that is used
-// internally by the router. This exists because sub-routers
-// must use the same [http.ServeMux] and therefore, there's
-// some recursion involved to get the same [http.ServeMux].
-func (router *Router[H]) mux() *http.ServeMux {
+// Named creates a new sub-router that prepends the given prefix to
+// the name of every route registered through it, in addition to any
+// inherited prefix. The prefix is inherited by [Router.Group],
+// [Router.Grouped], [Router.Clone] and [Router.With].
+//
+//	router.Named("users.").Group("/users", func(users *Router[H]) {
+//	    users.Get("/{id}", show).Name("show") // named "users.show"
+//	})
+//
+// Like [Router.With], it creates a new sub-router instead of
+// modifying the current router.
+func (router *Router[H]) Named(prefix string) *Router[H] {
+	return &Router[H]{
+		native:      nil, // parent's native will be used
+		pattern:     router.pattern,
+		parent:      router,
+		middlewares: slices.Clone(router.middlewares),
+		name:        router.name + prefix,
+		host:        router.host,
+		constraints: router.constraints,
+	}
+}
+
+// root returns the root [Router] of the router tree. This exists
+// because sub-routers must share the state held by the root, such
+// as the [http.ServeMux] and the route names, and therefore, there's
+// some recursion involved to get to it.
+func (router *Router[H]) root() *Router[H] {
 	if router.parent != nil {
-		return router.parent.mux()
+		return router.parent.root()
+	}
+
+	return router
+}
+
+// mux returns the native [http.ServeMux] where the router
+// registers its routes. Sub-routers must use the same
+// [http.ServeMux] as their parent, so it is resolved from the
+// root or from the [Router.Host] group they belong to.
+func (router *Router[H]) mux() *http.ServeMux {
+	if router.native != nil {
+		return router.native
 	}
 
-	return router.native
+	return router.parent.mux()
+}
+
+// route creates the [Route] that represents the given full
+// pattern registered through the current router, along with
+// the records of each of its registrations.
+func (router *Router[H]) route(pattern string, records ...*RouteInfo) *Route {
+	return &Route{
+		pattern:     pattern,
+		host:        router.host,
+		prefix:      router.name,
+		names:       router.root().names,
+		constraints: router.root().routeConstraints,
+		records:     records,
+	}
+}
+
+// record stores a new [RouteInfo] in the root router describing
+// a registration made through the current router.
+func (router *Router[H]) record(method string, pattern string, trailing string) *RouteInfo {
+	root := router.root()
+	record := &RouteInfo{
+		Method:      method,
+		Host:        router.host,
+		Pattern:     pattern,
+		Name:        "",
+		Middlewares: len(router.middlewares),
+		Trailing:    trailing,
+	}
+
+	root.routes = append(root.routes, record)
+
+	return record
 }
 
 // wrap makes a handler wrapped by the current router's middleware.
@@ -177,10 +312,13 @@ func (router *Router[H]) registerRoot(method string, handler H) {
 }
 
 // registerTrailing registers the given pattern when the route
-// is supposed to end up in a slash ("/").
-func (router *Router[H]) registerTrailing(method string, pattern string, handler H) {
+// is supposed to end up in a slash ("/"). It returns the
+// pattern that was registered.
+func (router *Router[H]) registerTrailing(method string, pattern string, handler H) string {
 	pattern = fmt.Sprintf("%s/{$}", pattern)
 	router.register(method, pattern, handler)
+
+	return pattern
 }
 
 // registerPair registers both a pattern and its
@@ -207,7 +345,10 @@ func (router *Router[H]) registerTrailing(method string, pattern string, handler
 // the wildcard segment are registered. Handlers should
 // account for the catch-all value being empty when the
 // base path is matched directly.
-func (router *Router[H]) registerPair(method string, pattern string, handler H) {
+//
+// It returns the companion pattern that was registered
+// along with the given one, or an empty string if none was.
+func (router *Router[H]) registerPair(method string, pattern string, handler H) string {
 	// In all cases we always register the pattern, so let's do this first.
 	router.register(method, pattern, handler)
 
@@ -219,15 +360,17 @@ func (router *Router[H]) registerPair(method string, pattern string, handler H)
 		if segments := strings.Split(pattern, "/"); len(segments) > 2 {
 			pattern = strings.Join(segments[:len(segments)-1], "/")
 			router.register(method, pattern, handler)
+
+			return pattern
 		}
 
-		return
+		return ""
 	}
 
 	// Given the path does not end in a rest wildcard,
 	// we can simply register the trailing slash pattern
 	// to adhere to both.
-	router.registerTrailing(method, pattern, handler)
+	return router.registerTrailing(method, pattern, handler)
 }
 
 // Method registers a new handler to the router with the
@@ -264,7 +407,10 @@ func (router *Router[H]) registerPair(method string, pattern string, handler H)
 // applications. TRACE enables cross-site tracing (XST) attacks and
 // CONNECT is reserved for HTTP proxies. If needed, use the
 // [Router.Trace] or [Router.Connect] methods explicitly.
-func (router *Router[H]) Method(method string, pattern string, handler H) {
+//
+// The returned [Route] can be used to name the route so that its
+// URL can later be generated with [Router.URL].
+func (router *Router[H]) Method(method string, pattern string, handler H) *Route {
 	if method == "" {
 		panic("router: method must not be empty")
 	}
@@ -280,75 +426,155 @@ func (router *Router[H]) Method(method string, pattern string, handler H) {
 	if pattern == "/" {
 		router.registerRoot(method, handler)
 
-		return
+		return router.constrain(router.route(pattern, router.record(method, pattern, "")))
 	}
 
-	router.registerPair(method, pattern, handler)
+	trailing := router.registerPair(method, pattern, handler)
+
+	return router.constrain(router.route(pattern, router.record(method, pattern, trailing)))
 }
 
 // Methods registers a handler for each method in the given slice by calling
-// [Router.Method] for each entry.
-func (router *Router[H]) Methods(methods []string, pattern string, handler H) {
+// [Router.Method] for each entry. The returned [Route] covers all
+// of the registered methods.
+func (router *Router[H]) Methods(methods []string, pattern string, handler H) *Route {
+	records := make([]*RouteInfo, 0, len(methods))
+
 	for _, method := range methods {
-		router.Method(method, pattern, handler)
+		records = append(records, router.Method(method, pattern, handler).records...)
 	}
+
+	return router.route(path.Join(router.pattern, pattern), records...)
 }
 
 // Any registers a handler for all standard HTTP methods (GET, HEAD, POST, PUT,
 // PATCH, DELETE, OPTIONS) using [Router.Methods]. TRACE and CONNECT are
 // intentionally excluded for security reasons.
-func (router *Router[H]) Any(pattern string, handler H) {
-	router.Methods(allMethods, pattern, handler)
+func (router *Router[H]) Any(pattern string, handler H) *Route {
+	return router.Methods(allMethods, pattern, handler)
 }
 
 // Get registers a handler for [http.MethodGet] using [Router.Method].
-func (router *Router[H]) Get(pattern string, handler H) {
-	router.Method(http.MethodGet, pattern, handler)
+func (router *Router[H]) Get(pattern string, handler H) *Route {
+	return router.Method(http.MethodGet, pattern, handler)
 }
 
 // Head registers a handler for [http.MethodHead] using [Router.Method].
-func (router *Router[H]) Head(pattern string, handler H) {
-	router.Method(http.MethodHead, pattern, handler)
+func (router *Router[H]) Head(pattern string, handler H) *Route {
+	return router.Method(http.MethodHead, pattern, handler)
 }
 
 // Post registers a handler for [http.MethodPost] using [Router.Method].
-func (router *Router[H]) Post(pattern string, handler H) {
-	router.Method(http.MethodPost, pattern, handler)
+func (router *Router[H]) Post(pattern string, handler H) *Route {
+	return router.Method(http.MethodPost, pattern, handler)
 }
 
 // Put registers a handler for [http.MethodPut] using [Router.Method].
-func (router *Router[H]) Put(pattern string, handler H) {
-	router.Method(http.MethodPut, pattern, handler)
+func (router *Router[H]) Put(pattern string, handler H) *Route {
+	return router.Method(http.MethodPut, pattern, handler)
 }
 
 // Patch registers a handler for [http.MethodPatch] using [Router.Method].
-func (router *Router[H]) Patch(pattern string, handler H) {
-	router.Method(http.MethodPatch, pattern, handler)
+func (router *Router[H]) Patch(pattern string, handler H) *Route {
+	return router.Method(http.MethodPatch, pattern, handler)
 }
 
 // Delete registers a handler for [http.MethodDelete] using [Router.Method].
-func (router *Router[H]) Delete(pattern string, handler H) {
-	router.Method(http.MethodDelete, pattern, handler)
+func (router *Router[H]) Delete(pattern string, handler H) *Route {
+	return router.Method(http.MethodDelete, pattern, handler)
 }
 
 // Connect registers a handler for [http.MethodConnect] using [Router.Method].
-func (router *Router[H]) Connect(pattern string, handler H) {
-	router.Method(http.MethodConnect, pattern, handler)
+func (router *Router[H]) Connect(pattern string, handler H) *Route {
+	return router.Method(http.MethodConnect, pattern, handler)
 }
 
 // Options registers a handler for [http.MethodOptions] using [Router.Method].
-func (router *Router[H]) Options(pattern string, handler H) {
-	router.Method(http.MethodOptions, pattern, handler)
+func (router *Router[H]) Options(pattern string, handler H) *Route {
+	return router.Method(http.MethodOptions, pattern, handler)
 }
 
 // Trace registers a handler for [http.MethodTrace] using [Router.Method].
-func (router *Router[H]) Trace(pattern string, handler H) {
-	router.Method(http.MethodTrace, pattern, handler)
+func (router *Router[H]) Trace(pattern string, handler H) *Route {
+	return router.Method(http.MethodTrace, pattern, handler)
 }
 
 // ServeHTTP implements [http.Handler] by delegating to the underlying [http.ServeMux].
+// Requests that match no route are dispatched to the handlers registered with
+// [Router.NotFound] and [Router.MethodNotAllowed], if any.
 func (router *Router[H]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	router.mux().ServeHTTP(w, r)
+	root := router.root()
+
+	// The [http.ServeMux] redirects requests to their cleaned path
+	// before matching any route, so neither the constraints nor the
+	// fallback handlers apply to them.
+	if redirectsToCleanPath(r) {
+		root.native.ServeHTTP(w, r)
+
+		return
+	}
+
+	// Each request is matched once: the handler is resolved here and
+	// called directly, setting the wildcard values the [http.ServeMux]
+	// would otherwise set when matching the request again.
+	var handler http.Handler
+
+	host, pattern := "", ""
+
+	if matched, values := router.matchHost(r); matched != nil {
+		if handler, pattern = matched.native.Handler(r); pattern != "" {
+			host = matched.pattern
+
+			for name, value := range values {
+				r.SetPathValue(name, value)
+			}
+		}
+	}
+
+	if pattern == "" {
+		handler, pattern = root.native.Handler(r)
+	}
+
+	if pattern == "" {
+		if !router.serveFallback(w, r) {
+			handler.ServeHTTP(w, r)
+		}
+
+		return
+	}
+
+	setPathValues(r, pattern)
+
+	if err := router.checkConstraints(host, pattern, r); err != nil {
+		router.serveInvalidParameter(w, r, err)
+
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// redirectsToCleanPath reports whether the [http.ServeMux] redirects
+// the given request to its cleaned path, such as "/users//1" to
+// "/users/1". CONNECT requests are never cleaned.
+func redirectsToCleanPath(request *http.Request) bool {
+	if request.Method == http.MethodConnect {
+		return false
+	}
+
+	escaped := request.URL.EscapedPath()
+
+	if escaped == "" || escaped[0] != '/' {
+		return true
+	}
+
+	cleaned := path.Clean(escaped)
+
+	if strings.HasSuffix(escaped, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+
+	return cleaned != escaped
 }
 
 // Has reports whether the given pattern is registered in the router
@@ -394,7 +620,7 @@ func (router *Router[H]) Handler(method string, pattern string) (h H, ok bool) {
 func (router *Router[H]) HandlerMatch(request *http.Request) (h H, ok bool) {
 	// We can look for that specific handler in the
 	// native [http.ServeMux] and return it if found.
-	if handler, pattern := router.mux().Handler(request); pattern != "" {
+	if handler, pattern := router.resolve(request).Handler(request); pattern != "" {
 		if typed, ok := handler.(H); ok {
 			return typed, true
 		}
@@ -403,6 +629,28 @@ func (router *Router[H]) HandlerMatch(request *http.Request) (h H, ok bool) {
 	return h, false
 }
 
+// Routes returns a description of every route registered on the
+// router tree, in registration order. A route registered for many
+// methods, such as with [Router.Any], is reported once per method.
+//
+// The returned slice is a copy, modifying it does not affect the
+// router.
+//
+//	for _, route := range router.Routes() {
+//	    slog.Info("route registered", "route", route.String(), "name", route.Name)
+//	}
+func (router *Router[H]) Routes() []RouteInfo {
+	records := router.root().routes
+	routes := make([]RouteInfo, len(records))
+
+	for i, record := range records {
+		routes[i] = *record
+		routes[i].Constraints = maps.Clone(record.Constraints)
+	}
+
+	return routes
+}
+
 // Record returns an [http.Response] produced by dispatching the given HTTP
 // request through the router's full middleware and handler pipeline.
 func (router *Router[H]) Record(request *http.Request) *http.Response {
@@ -3,6 +3,7 @@ package router_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/studiolambda/cosmos/router"
@@ -1697,3 +1698,80 @@ func TestMethodPanicsOnEmptyMethod(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 }
+
+func TestServeHTTPSetsPathValuesLikeServeMux(t *testing.T) {
+	t.Parallel()
+
+	var routed, native *http.Request
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/files/{dir}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		routed = r
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /files/{dir}/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		native = r
+	})
+
+	target := "/files/a%2Fb/c%20d/e"
+
+	rt.Record(httptest.NewRequest(http.MethodGet, target, nil))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+
+	if routed.Pattern != native.Pattern {
+		t.Fatalf("expected pattern %q but got %q", native.Pattern, routed.Pattern)
+	}
+
+	if routed.PathValue("dir") != native.PathValue("dir") {
+		t.Fatalf("expected dir %q but got %q", native.PathValue("dir"), routed.PathValue("dir"))
+	}
+
+	if routed.PathValue("path") != native.PathValue("path") {
+		t.Fatalf("expected path %q but got %q", native.PathValue("path"), routed.PathValue("path"))
+	}
+}
+
+// FuzzServeHTTPRedirectsToCleanPathsLikeServeMux ensures the router
+// redirects exactly the requests an [http.ServeMux] redirects to their
+// cleaned path, since it replicates the rules the mux keeps private.
+func FuzzServeHTTPRedirectsToCleanPathsLikeServeMux(f *testing.F) {
+	f.Add("/users")
+	f.Add("/users/")
+	f.Add("/users//")
+	f.Add("//users")
+	f.Add("/a/../users")
+	f.Add("/./users")
+	f.Add("/users/.")
+	f.Add("/users/..")
+	f.Add("/%2e%2e/users")
+	f.Add("/a%2F..%2Fusers")
+	f.Add("/")
+
+	f.Fuzz(func(t *testing.T, target string) {
+		parsed, err := url.ParseRequestURI(target)
+
+		if err != nil {
+			t.Skip()
+		}
+
+		rt := router.New[http.HandlerFunc]()
+		rt.Get("/users", status(http.StatusOK))
+		rt.NotFound(status(http.StatusTeapot))
+
+		mux := http.NewServeMux()
+		mux.Handle("/", status(http.StatusOK))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL = parsed
+
+		routed := rt.Record(req.Clone(t.Context()))
+		native := httptest.NewRecorder()
+
+		mux.ServeHTTP(native, req.Clone(t.Context()))
+
+		if routed.Header.Get("Location") != native.Header().Get("Location") {
+			t.Fatalf("expected location %q but got %q", native.Header().Get("Location"), routed.Header.Get("Location"))
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnknownRoute is returned by [Router.URL] and [Router.URLWith]
+	// when no route has been registered with the given name.
+	ErrUnknownRoute = errors.New("unknown route name")
+
+	// ErrMissingParameter is returned by [Router.URL] and [Router.URLWith]
+	// when a wildcard of the route pattern has no value.
+	ErrMissingParameter = errors.New("missing route parameter")
+
+	// ErrUnknownParameter is returned by [Router.URL] and [Router.URLWith]
+	// when a value is given for a wildcard the route pattern does not have.
+	ErrUnknownParameter = errors.New("unknown route parameter")
+
+	// ErrInvalidParameter is returned by [Router.URL] and [Router.URLWith]
+	// when a value would produce a "." or ".." path segment, which
+	// would be cleaned away by [http.ServeMux] before matching.
+	ErrInvalidParameter = errors.New("invalid route parameter")
+)
+
+// URL generates the path of the route with the given name by
+// replacing its wildcards with the given parameters, in the same
+// order they appear in the route pattern.
+//
+//	router.Get("/users/{user}/posts/{post}", show).Name("posts.show")
+//	router.URL("posts.show", "42", "hello world") // "/users/42/posts/hello%20world"
+//
+// Values are escaped using [url.PathEscape]. A value given to a
+// "{rest...}" wildcard may contain slashes, in which case each
+// segment is escaped individually.
+//
+// It returns [ErrUnknownRoute] if the name is not registered,
+// [ErrMissingParameter] if fewer parameters than wildcards are
+// given and [ErrUnknownParameter] if more parameters are given.
+func (router *Router[H]) URL(name string, params ...string) (string, error) {
+	pattern, ok := router.root().names[name]
+
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRoute, name)
+	}
+
+	wildcards := patternWildcards(pattern)
+
+	if len(params) > len(wildcards) {
+		return "", fmt.Errorf(
+			"%w: route %q expects %d parameters but got %d",
+			ErrUnknownParameter, name, len(wildcards), len(params),
+		)
+	}
+
+	values := make(map[string]string, len(params))
+
+	for i, param := range params {
+		values[wildcards[i]] = param
+	}
+
+	return buildURL(pattern, values)
+}
+
+// URLWith generates the path of the route with the given name by
+// replacing its wildcards with the values of the given map, keyed
+// by wildcard name.
+//
+//	router.URLWith("posts.show", map[string]string{
+//	    "user": "42",
+//	    "post": "hello",
+//	})
+//
+// It behaves like [Router.URL] but returns [ErrUnknownParameter]
+// if the map contains a key that is not a wildcard of the route.
+func (router *Router[H]) URLWith(name string, params map[string]string) (string, error) {
+	pattern, ok := router.root().names[name]
+
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRoute, name)
+	}
+
+	wildcards := patternWildcards(pattern)
+
+	for key := range params {
+		if !slices.Contains(wildcards, key) {
+			return "", fmt.Errorf("%w: route %q has no %q wildcard", ErrUnknownParameter, name, key)
+		}
+	}
+
+	return buildURL(pattern, params)
+}
+
+// wildcard parses a single pattern segment and reports whether it
+// is a wildcard. The name excludes the braces and the trailing
+// "..." which is instead reported as rest.
+func wildcard(segment string) (name string, rest bool, ok bool) {
+	inner, ok := strings.CutPrefix(segment, "{")
+
+	if !ok {
+		return "", false, false
+	}
+
+	inner, ok = strings.CutSuffix(inner, "}")
+
+	if !ok {
+		return "", false, false
+	}
+
+	name, rest = strings.CutSuffix(inner, "...")
+
+	return name, rest, true
+}
+
+// patternWildcards returns the names of the wildcards found in
+// the given pattern in the order they appear. The special "{$}"
+// wildcard is not included as it never takes a value.
+func patternWildcards(pattern string) []string {
+	wildcards := make([]string, 0)
+
+	for segment := range strings.SplitSeq(pattern, "/") {
+		if name, _, ok := wildcard(segment); ok && name != "$" {
+			wildcards = append(wildcards, name)
+		}
+	}
+
+	return wildcards
+}
+
+// setPathValues sets on the request the values of the wildcards of
+// the [http.ServeMux] pattern it matched, along with the pattern
+// itself, just like [http.ServeMux.ServeHTTP] does. Each value is
+// unescaped, and a "{rest...}" value spans the rest of the path.
+func setPathValues(request *http.Request, pattern string) {
+	request.Pattern = pattern
+
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i:]
+	}
+
+	segments := strings.Split(request.URL.EscapedPath(), "/")
+
+	for i, segment := range strings.Split(pattern, "/") {
+		name, rest, ok := wildcard(segment)
+
+		if !ok || name == "$" || i >= len(segments) {
+			continue
+		}
+
+		value := segments[i]
+
+		if rest {
+			value = strings.Join(segments[i:], "/")
+		}
+
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+
+		request.SetPathValue(name, value)
+	}
+}
+
+// buildURL replaces the wildcards of the pattern with the
+// escaped values found in params.
+//
+// An empty "{rest...}" value produces the base path of the
+// pattern, matching the companion route registered by
+// [Router.registerPair].
+func buildURL(pattern string, params map[string]string) (string, error) {
+	segments := strings.Split(pattern, "/")
+
+	for i, segment := range segments {
+		name, rest, ok := wildcard(segment)
+
+		if !ok {
+			continue
+		}
+
+		if name == "$" {
+			segments[i] = ""
+
+			continue
+		}
+
+		value, found := params[name]
+
+		if !found || (value == "" && !rest) {
+			return "", fmt.Errorf("%w: %q", ErrMissingParameter, name)
+		}
+
+		if rest && value == "" {
+			segments = segments[:i]
+
+			break
+		}
+
+		escaped, err := escapeSegments(name, strings.TrimPrefix(value, "/"), rest)
+
+		if err != nil {
+			return "", err
+		}
+
+		segments[i] = escaped
+	}
+
+	if built := strings.Join(segments, "/"); built != "" {
+		return built, nil
+	}
+
+	return "/", nil
+}
+
+// escapeSegments escapes the value of the named wildcard. Values
+// of rest wildcards are split on "/" so that each path segment is
+// escaped individually while keeping the slashes in place.
+func escapeSegments(name string, value string, rest bool) (string, error) {
+	segments := []string{value}
+
+	if rest {
+		segments = strings.Split(value, "/")
+	}
+
+	for i, segment := range segments {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: %q contains a %q segment", ErrInvalidParameter, name, segment)
+		}
+
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
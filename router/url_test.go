@@ -0,0 +1,366 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/studiolambda/cosmos/router"
+)
+
+func noop(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestURLGeneratesStaticRoute(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop).Name("users.index")
+
+	url, err := rt.URL("users.index")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/users"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestURLGeneratesRootRoute(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/", noop).Name("home")
+
+	url, err := rt.URL("home")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestURLReplacesParametersInOrder(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{user}/posts/{post}", noop).Name("posts.show")
+
+	url, err := rt.URL("posts.show", "42", "hello world")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/users/42/posts/hello%20world"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+
+	if !rt.Has(http.MethodGet, url) {
+		t.Fatalf("generated url %q should match the route", url)
+	}
+}
+
+func TestURLEscapesSlashInParameter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/files/{name}", noop).Name("files.show")
+
+	url, err := rt.URL("files.show", "a/b")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/files/a%2Fb"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestURLKeepsSlashesInRestParameter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/static/{path...}", noop).Name("static")
+
+	url, err := rt.URL("static", "css/main file.css")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/static/css/main%20file.css"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestURLEmptyRestParameterGeneratesBasePath(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/static/{path...}", noop).Name("static")
+
+	url, err := rt.URL("static", "")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/static"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestURLRejectsDotSegments(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/static/{path...}", noop).Name("static")
+
+	if _, err := rt.URL("static", "css/../secret"); !errors.Is(err, router.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter but got %v", err)
+	}
+}
+
+func TestURLUnknownRouteFails(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	if _, err := rt.URL("missing"); !errors.Is(err, router.ErrUnknownRoute) {
+		t.Fatalf("expected ErrUnknownRoute but got %v", err)
+	}
+}
+
+func TestURLMissingParameterFails(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{user}/posts/{post}", noop).Name("posts.show")
+
+	if _, err := rt.URL("posts.show", "42"); !errors.Is(err, router.ErrMissingParameter) {
+		t.Fatalf("expected ErrMissingParameter but got %v", err)
+	}
+}
+
+func TestURLEmptyParameterFails(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{user}", noop).Name("users.show")
+
+	if _, err := rt.URL("users.show", ""); !errors.Is(err, router.ErrMissingParameter) {
+		t.Fatalf("expected ErrMissingParameter but got %v", err)
+	}
+}
+
+func TestURLTooManyParametersFails(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{user}", noop).Name("users.show")
+
+	if _, err := rt.URL("users.show", "1", "2"); !errors.Is(err, router.ErrUnknownParameter) {
+		t.Fatalf("expected ErrUnknownParameter but got %v", err)
+	}
+}
+
+func TestURLWithReplacesNamedParameters(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{user}/posts/{post}", noop).Name("posts.show")
+
+	url, err := rt.URLWith("posts.show", map[string]string{
+		"post": "7",
+		"user": "42",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/users/42/posts/7"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestURLWithUnknownParameterFails(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users/{user}", noop).Name("users.show")
+
+	_, err := rt.URLWith("users.show", map[string]string{
+		"user":  "42",
+		"extra": "value",
+	})
+
+	if !errors.Is(err, router.ErrUnknownParameter) {
+		t.Fatalf("expected ErrUnknownParameter but got %v", err)
+	}
+}
+
+func TestURLIncludesGroupPrefix(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Group("/api", func(api *router.Router[http.HandlerFunc]) {
+		api.Get("/users/{user}", noop).Name("users.show")
+	})
+
+	url, err := rt.URL("users.show", "42")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/api/users/42"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestNamedPrefixIsInheritedByGroup(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.Named("admin.").Group("/admin", func(admin *router.Router[http.HandlerFunc]) {
+		admin.Named("users.").Group("/users", func(users *router.Router[http.HandlerFunc]) {
+			users.Get("/{user}", noop).Name("show")
+		})
+	})
+
+	url, err := rt.URL("admin.users.show", "42")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/admin/users/42"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestNamedDoesNotModifyParentRouter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Named("admin.")
+	rt.Get("/users", noop).Name("users")
+
+	if _, err := rt.URL("users"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestURLWorksForWithGroupedAndCloneRouters(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+
+	rt.With(func(next http.HandlerFunc) http.HandlerFunc {
+		return next
+	}).Get("/with", noop).Name("with")
+
+	rt.Grouped(func(grouped *router.Router[http.HandlerFunc]) {
+		grouped.Get("/grouped", noop).Name("grouped")
+	})
+
+	rt.Clone().Get("/clone", noop).Name("clone")
+
+	for _, name := range []string{"with", "grouped", "clone"} {
+		url, err := rt.URL(name)
+
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", name, err)
+		}
+
+		if expected := "/" + name; url != expected {
+			t.Fatalf("expected url %q but got %q", expected, url)
+		}
+	}
+}
+
+func TestURLFromSubRouter(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop).Name("users.index")
+
+	rt.Group("/api", func(api *router.Router[http.HandlerFunc]) {
+		url, err := api.URL("users.index")
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := "/users"; url != expected {
+			t.Fatalf("expected url %q but got %q", expected, url)
+		}
+	})
+}
+
+func TestAnyRouteCanBeNamed(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Any("/webhook", noop).Name("webhook")
+
+	url, err := rt.URL("webhook")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := "/webhook"; url != expected {
+		t.Fatalf("expected url %q but got %q", expected, url)
+	}
+}
+
+func TestNameSamePatternTwiceIsAllowed(t *testing.T) {
+	t.Parallel()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop).Name("users")
+	rt.Post("/users", noop).Name("users")
+
+	if _, err := rt.URL("users"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNamePanicsOnDuplicateName(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Name with a duplicate name should panic")
+		}
+	}()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop).Name("users")
+	rt.Get("/people", noop).Name("users")
+}
+
+func TestNamePanicsOnEmptyName(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Name with an empty name should panic")
+		}
+	}()
+
+	rt := router.New[http.HandlerFunc]()
+	rt.Get("/users", noop).Name("")
+}